@@ -2,7 +2,9 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -10,30 +12,228 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
-
-	"github.com/teal-bauer/specter/internal/config"
+	"sync"
+	"time"
 )
 
-// Client is a Ghost Admin API client
+// Client is a Ghost Admin API client. The typed services cover the common
+// resources; Get, Post, Put and Delete reach any other endpoint.
 type Client struct {
+	Posts       *Service[Post]
+	Pages       *Service[Page]
+	Tags        *Service[Tag]
+	Members     *Service[Member]
+	Labels      *Service[Label]
+	Newsletters *Service[Newsletter]
+	Tiers       *Service[Tier]
+	Users       *Service[User]
+	Offers      *Service[Offer]
+
 	baseURL string
 	key     string
-	http    *http.Client
+	// contentKey, if set, makes this a read-only Content API client
+	contentKey string
+	http       *http.Client
+	profile    string
+	frozen     bool
+	// userAgent and headers are added to every request
+	userAgent string
+	headers   map[string]string
+	// maxResponse is the largest response body accepted, in bytes; 0 means
+	// no limit
+	maxResponse int64
+	middleware  []Middleware
+
+	// The site's Ghost version and the Accept-Version picked for it, set
+	// once before the first request
+	versionOnce   sync.Once
+	serverVersion string
+	acceptVersion string
+	// warned holds the endpoints already warned about as unsupported
+	warnedMu sync.Mutex
+	warned   map[string]bool
+	// cacheDir holds the cache of detected Ghost versions
+	cacheDir string
+}
+
+// Options configures a Client. The zero value gives an Admin API client
+// with the default user agent and response size limit.
+type Options struct {
+	// ContentKey, if set, makes the client a read-only Content API client
+	// that authenticates with it instead of the admin key
+	ContentKey string
+	// Profile names the site in errors, e.g. about the freeze
+	Profile string
+	// Frozen refuses requests that change content
+	Frozen bool
+	// UserAgent replaces DefaultUserAgent
+	UserAgent string
+	// Headers are added to every request, e.g. to get through a proxy
+	Headers map[string]string
+	// MaxResponseMB limits the size of responses; 0 means
+	// DefaultMaxResponseMB and a negative value no limit
+	MaxResponseMB int
+	// CacheDir, if set, is where the site's detected Ghost version is
+	// cached between runs
+	CacheDir string
+}
+
+// APIVersion is the Admin API version requested with Accept-Version when
+// the site's Ghost version is unknown. Ghost answers in the requested
+// version's format; see SupportedVersion for the versions specter knows.
+const APIVersion = "v5.0"
+
+// DefaultUserAgent identifies specter to servers that block unknown clients
+const DefaultUserAgent = "specter (+https://github.com/teal-bauer/specter)"
+
+// DefaultMaxResponseMB is the response size limit unless a profile sets
+// max_response_mb. It is far above any page of results, but stops a runaway
+// limit=all request from exhausting memory.
+const DefaultMaxResponseMB = 100
+
+// ResponseTooLargeError is returned when a response exceeds the size limit
+type ResponseTooLargeError struct {
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response larger than %d MB (use a smaller --limit, or raise max_response_mb in the profile)", e.Limit>>20)
 }
 
-// NewClient creates a new Ghost Admin API client from config
-func NewClient(cfg *config.Config) *Client {
-	baseURL := strings.TrimSuffix(cfg.URL, "/")
-	return &Client{
-		baseURL: baseURL,
-		key:     cfg.Key,
-		http:    &http.Client{},
+// transport is shared by all clients so that connections are pooled across
+// them. Bulk commands run several workers against the same host, so it keeps
+// more idle connections per host than net/http's default of 2, which would
+// otherwise make most requests open a new TLS connection.
+var transport = func() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ForceAttemptHTTP2 = true
+	t.MaxIdleConns = 100
+	t.MaxIdleConnsPerHost = 32
+	t.IdleConnTimeout = 90 * time.Second
+	return t
+}()
+
+// New creates a Ghost API client for the site at siteURL that
+// authenticates with the Admin API key
+func New(siteURL, key string, opts Options) *Client {
+	headers := map[string]string{}
+	for name, value := range opts.Headers {
+		headers[name] = value
 	}
+	c := &Client{
+		baseURL:    strings.TrimSuffix(siteURL, "/"),
+		key:        key,
+		contentKey: opts.ContentKey,
+		http:       &http.Client{Transport: transport},
+		profile:    opts.Profile,
+		frozen:     opts.Frozen,
+		userAgent:  opts.UserAgent,
+		headers:    headers,
+		cacheDir:   opts.CacheDir,
+
+		maxResponse: maxResponseBytes(opts.MaxResponseMB),
+	}
+	if c.contentKey != "" {
+		c.key = ""
+	}
+	c.Posts = newService[Post](c, "posts", "post", "slug")
+	c.Pages = newService[Page](c, "pages", "page", "slug")
+	c.Tags = newService[Tag](c, "tags", "tag", "slug")
+	c.Members = newService[Member](c, "members", "member", "email")
+	c.Labels = newService[Label](c, "labels", "label", "slug")
+	c.Newsletters = newService[Newsletter](c, "newsletters", "newsletter", "slug")
+	c.Tiers = newService[Tier](c, "tiers", "tier", "slug")
+	c.Users = newService[User](c, "users", "user", "slug")
+	c.Offers = newService[Offer](c, "offers", "offer", "code")
+	return c
+}
+
+func maxResponseBytes(mb int) int64 {
+	switch {
+	case mb < 0:
+		return 0
+	case mb == 0:
+		mb = DefaultMaxResponseMB
+	}
+	return int64(mb) << 20
+}
+
+// limitBody returns the body of resp, failing with ResponseTooLargeError
+// once more than the client's limit has been read
+func (c *Client) limitBody(resp *http.Response) io.Reader {
+	if c.maxResponse == 0 {
+		return resp.Body
+	}
+	return &limitedReader{r: resp.Body, n: c.maxResponse, limit: c.maxResponse}
+}
+
+type limitedReader struct {
+	r     io.Reader
+	n     int64
+	limit int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// Only an error if there is more to read
+		var b [1]byte
+		if n, err := l.r.Read(b[:]); n == 0 {
+			return 0, err
+		}
+		return 0, &ResponseTooLargeError{Limit: l.limit}
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// setHeaders adds authentication and the configured headers to req. Content
+// API clients authenticate with their key in the query string instead of a
+// token.
+func (c *Client) setHeaders(req *http.Request, token string) {
+	if c.contentKey != "" {
+		q := req.URL.Query()
+		q.Set("key", c.contentKey)
+		req.URL.RawQuery = q.Encode()
+	} else if token != "" {
+		req.Header.Set("Authorization", "Ghost "+token)
+	}
+	if c.acceptVersion != "" {
+		req.Header.Set("Accept-Version", c.acceptVersion)
+	} else {
+		req.Header.Set("Accept-Version", APIVersion)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	} else {
+		req.Header.Set("User-Agent", DefaultUserAgent)
+	}
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// checkFreeze refuses requests that change content while the profile is
+// frozen
+func (c *Client) checkFreeze(method string) error {
+	if method == "GET" || !c.frozen {
+		return nil
+	}
+	return fmt.Errorf("profile '%s' is frozen; use --override-freeze to make changes anyway, or 'specter freeze off'", c.profile)
 }
 
 // APIError represents an error from the Ghost API
 type APIError struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int `json:"-"`
+	// body is the response, if it wasn't a JSON error
+	body string
+
 	Errors []struct {
 		Message string `json:"message"`
 		Context string `json:"context,omitempty"`
@@ -42,6 +242,9 @@ type APIError struct {
 }
 
 func (e *APIError) Error() string {
+	if e.body != "" {
+		return fmt.Sprintf("API error: %s (status %d)", e.body, e.StatusCode)
+	}
 	if len(e.Errors) == 0 {
 		return "unknown API error"
 	}
@@ -53,13 +256,73 @@ func (e *APIError) Error() string {
 }
 
 func (c *Client) apiURL(path string) string {
+	if c.contentKey != "" {
+		return c.baseURL + "/ghost/api/content" + path
+	}
 	return c.baseURL + "/ghost/api/admin" + path
 }
 
-func (c *Client) doRequest(method, path string, body interface{}) ([]byte, error) {
+// contentResources are the resources the Content API serves
+var contentResources = []string{"posts", "pages", "tags", "authors", "tiers", "offers", "settings"}
+
+// checkContentAPI fails requests that a Content API client can't make:
+// changes, and resources only the Admin API has
+func (c *Client) checkContentAPI(method, path string) error {
+	if c.contentKey == "" {
+		return nil
+	}
+	if method != http.MethodGet {
+		return fmt.Errorf("the Content API is read-only; drop --content-api to make changes")
+	}
+	resource, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	resource, _, _ = strings.Cut(resource, "?")
+	if !slices.Contains(contentResources, resource) {
+		return fmt.Errorf("%s isn't available through the Content API", resource)
+	}
+	return nil
+}
+
+// token returns a token for the Admin API, or "" for Content API clients
+func (c *Client) token() (string, error) {
+	if c.contentKey != "" {
+		return "", nil
+	}
 	token, err := GenerateToken(c.key)
 	if err != nil {
-		return nil, fmt.Errorf("generating token: %w", err)
+		return "", fmt.Errorf("generating token: %w", err)
+	}
+	return token, nil
+}
+
+func (c *Client) doRequest(method, path string, body interface{}) ([]byte, error) {
+	resp, err := c.send(context.Background(), method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(c.limitBody(resp))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	return respBody, nil
+}
+
+// send makes a JSON API request and returns the response if it succeeded.
+// The caller must close the response body.
+func (c *Client) send(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	if err := c.checkFreeze(method); err != nil {
+		return nil, err
+	}
+	if err := c.checkContentAPI(method, path); err != nil {
+		return nil, err
+	}
+	c.negotiate()
+	c.checkEndpoint(path)
+
+	token, err := c.token()
+	if err != nil {
+		return nil, err
 	}
 
 	var reqBody io.Reader
@@ -71,37 +334,40 @@ func (c *Client) doRequest(method, path string, body interface{}) ([]byte, error
 		reqBody = bytes.NewReader(data)
 	}
 
-	req, err := http.NewRequest(method, c.apiURL(path), reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL(path), reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Ghost "+token)
+	c.setHeaders(req, token)
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
-	req.Header.Set("Accept-Version", "v5.0")
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
+	if c.maxResponse > 0 && resp.ContentLength > c.maxResponse {
+		resp.Body.Close()
+		return nil, &ResponseTooLargeError{Limit: c.maxResponse}
 	}
 
 	if resp.StatusCode >= 400 {
-		var apiErr APIError
-		if err := json.Unmarshal(respBody, &apiErr); err == nil && len(apiErr.Errors) > 0 {
-			return nil, &apiErr
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(c.limitBody(resp))
+		if err != nil {
+			return nil, fmt.Errorf("reading response: %w", err)
 		}
-		return nil, fmt.Errorf("API error: %s (status %d)", string(respBody), resp.StatusCode)
+		apiErr := APIError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(respBody, &apiErr); err != nil || len(apiErr.Errors) == 0 {
+			apiErr.body = string(respBody)
+		}
+		return nil, &apiErr
 	}
 
-	return respBody, nil
+	return resp, nil
 }
 
 // Get performs a GET request
@@ -113,6 +379,37 @@ func (c *Client) Get(path string, params url.Values) ([]byte, error) {
 	return c.doRequest("GET", fullPath, nil)
 }
 
+// GetJSON performs a GET request and decodes the response into v as it is
+// read, without holding the raw response in memory
+func (c *Client) GetJSON(path string, params url.Values, v interface{}) error {
+	fullPath := path
+	if len(params) > 0 {
+		fullPath += "?" + params.Encode()
+	}
+	return c.sendJSON(context.Background(), "GET", fullPath, nil, v)
+}
+
+// sendJSON makes a JSON API request and decodes the response into v
+func (c *Client) sendJSON(ctx context.Context, method, path string, body, v interface{}) error {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(c.limitBody(resp)).Decode(v); err != nil {
+		var tooLarge *ResponseTooLargeError
+		if errors.As(err, &tooLarge) {
+			return err
+		}
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	return nil
+}
+
 // Post performs a POST request
 func (c *Client) Post(path string, body interface{}) ([]byte, error) {
 	return c.doRequest("POST", path, body)
@@ -130,80 +427,235 @@ func (c *Client) Delete(path string) ([]byte, error) {
 
 // UploadImage uploads an image file to Ghost
 func (c *Client) UploadImage(filePath, ref string) (string, error) {
-	token, err := GenerateToken(c.key)
+	file, err := os.Open(filePath)
 	if err != nil {
-		return "", fmt.Errorf("generating token: %w", err)
+		return "", fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	fields := map[string]string{}
+	if ref != "" {
+		fields["ref"] = ref
+	}
+
+	respBody, err := c.upload("/images/upload/", fields, formFile{"file", filepath.Base(filePath), file})
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Images []struct {
+			URL string `json:"url"`
+			Ref string `json:"ref,omitempty"`
+		} `json:"images"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("parsing response: %w", err)
+	}
+
+	if len(result.Images) == 0 {
+		return "", fmt.Errorf("no image URL in response")
 	}
 
+	return result.Images[0].URL, nil
+}
+
+// UploadMedia uploads a video or audio file to Ghost, with an optional
+// thumbnail image (used as a video's poster). It returns the media URL and
+// the thumbnail URL, if any.
+func (c *Client) UploadMedia(filePath, thumbnailPath, ref string) (string, string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return "", fmt.Errorf("opening file: %w", err)
+		return "", "", fmt.Errorf("opening file: %w", err)
 	}
 	defer file.Close()
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+	files := []formFile{{"file", filepath.Base(filePath), file}}
+	if thumbnailPath != "" {
+		thumb, err := os.Open(thumbnailPath)
+		if err != nil {
+			return "", "", fmt.Errorf("opening thumbnail: %w", err)
+		}
+		defer thumb.Close()
+		files = append(files, formFile{"thumbnail", filepath.Base(thumbnailPath), thumb})
+	}
 
-	part, err := writer.CreateFormFile("file", filepath.Base(filePath))
+	fields := map[string]string{}
+	if ref != "" {
+		fields["ref"] = ref
+	}
+
+	respBody, err := c.upload("/media/upload/", fields, files...)
 	if err != nil {
-		return "", fmt.Errorf("creating form file: %w", err)
+		return "", "", err
 	}
 
-	if _, err := io.Copy(part, file); err != nil {
-		return "", fmt.Errorf("copying file: %w", err)
+	var result struct {
+		Media []struct {
+			URL          string `json:"url"`
+			ThumbnailURL string `json:"thumbnail_url,omitempty"`
+		} `json:"media"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", "", fmt.Errorf("parsing response: %w", err)
+	}
+
+	if len(result.Media) == 0 {
+		return "", "", fmt.Errorf("no media URL in response")
 	}
 
+	return result.Media[0].URL, result.Media[0].ThumbnailURL, nil
+}
+
+// UploadFile uploads an arbitrary file (e.g. a PDF attachment) to Ghost
+func (c *Client) UploadFile(filePath, ref string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	fields := map[string]string{}
 	if ref != "" {
-		if err := writer.WriteField("ref", ref); err != nil {
-			return "", fmt.Errorf("writing ref field: %w", err)
+		fields["ref"] = ref
+	}
+
+	respBody, err := c.upload("/files/upload/", fields, formFile{"file", filepath.Base(filePath), file})
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Files []struct {
+			URL string `json:"url"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("parsing response: %w", err)
+	}
+
+	if len(result.Files) == 0 {
+		return "", fmt.Errorf("no file URL in response")
+	}
+
+	return result.Files[0].URL, nil
+}
+
+// UploadRoutes replaces the site's routes.yaml
+func (c *Client) UploadRoutes(r io.Reader) error {
+	_, err := c.upload("/settings/routes/yaml/", nil, formFile{"routes", "routes.yaml", r})
+	return err
+}
+
+// UploadTheme uploads a theme zip, replacing any theme of the same name,
+// and returns the theme's name. The theme is not activated.
+func (c *Client) UploadTheme(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	respBody, err := c.upload("/themes/upload/", nil, formFile{"file", filepath.Base(filePath), file})
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Themes []struct {
+			Name string `json:"name"`
+		} `json:"themes"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("parsing response: %w", err)
+	}
+
+	if len(result.Themes) == 0 {
+		return "", fmt.Errorf("no theme in response")
+	}
+
+	return result.Themes[0].Name, nil
+}
+
+// UploadRedirects replaces the site's redirects. filename's extension (.json
+// or .yaml) tells Ghost how to parse them.
+func (c *Client) UploadRedirects(r io.Reader, filename string) error {
+	_, err := c.upload("/redirects/upload/", nil, formFile{"redirects", filename, r})
+	return err
+}
+
+// formFile is a file part of a multipart upload
+type formFile struct {
+	field    string
+	filename string
+	r        io.Reader
+}
+
+// upload POSTs files and fields as multipart/form-data
+func (c *Client) upload(path string, fields map[string]string, files ...formFile) ([]byte, error) {
+	if err := c.checkFreeze("POST"); err != nil {
+		return nil, err
+	}
+	if err := c.checkContentAPI("POST", path); err != nil {
+		return nil, err
+	}
+	c.negotiate()
+	c.checkEndpoint(path)
+
+	token, err := c.token()
+	if err != nil {
+		return nil, err
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for _, f := range files {
+		part, err := writer.CreateFormFile(f.field, f.filename)
+		if err != nil {
+			return nil, fmt.Errorf("creating form file: %w", err)
+		}
+		if _, err := io.Copy(part, f.r); err != nil {
+			return nil, fmt.Errorf("copying file: %w", err)
+		}
+	}
+
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, fmt.Errorf("writing %s field: %w", name, err)
 		}
 	}
 
 	if err := writer.Close(); err != nil {
-		return "", fmt.Errorf("closing writer: %w", err)
+		return nil, fmt.Errorf("closing writer: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", c.apiURL("/images/upload/"), body)
+	req, err := http.NewRequest("POST", c.apiURL(path), body)
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Ghost "+token)
+	c.setHeaders(req, token)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("Accept-Version", "v5.0")
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return "", fmt.Errorf("upload failed: %w", err)
+		return nil, fmt.Errorf("upload failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(c.limitBody(resp))
 	if err != nil {
-		return "", fmt.Errorf("reading response: %w", err)
+		return nil, fmt.Errorf("reading response: %w", err)
 	}
 
 	if resp.StatusCode >= 400 {
-		var apiErr APIError
+		apiErr := APIError{StatusCode: resp.StatusCode}
 		if err := json.Unmarshal(respBody, &apiErr); err == nil && len(apiErr.Errors) > 0 {
-			return "", &apiErr
+			return nil, &apiErr
 		}
-		return "", fmt.Errorf("upload error: %s", string(respBody))
-	}
-
-	var result struct {
-		Images []struct {
-			URL string `json:"url"`
-			Ref string `json:"ref,omitempty"`
-		} `json:"images"`
-	}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return "", fmt.Errorf("parsing response: %w", err)
+		return nil, fmt.Errorf("upload error: %s", string(respBody))
 	}
 
-	if len(result.Images) == 0 {
-		return "", fmt.Errorf("no image URL in response")
-	}
-
-	return result.Images[0].URL, nil
+	return respBody, nil
 }
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Handler sends a request to Ghost and returns its response
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler to observe or change requests and responses,
+// e.g. for logging, metrics, extra authentication or caching. A middleware
+// may return a response without calling next.
+type Middleware func(next Handler) Handler
+
+// Use adds middleware to the client. Middleware added first is outermost: it
+// sees requests first and responses last. Requests reach the middleware
+// with all headers, including Authorization, already set.
+//
+// Use must not be called while requests are in flight.
+func (c *Client) Use(mw ...Middleware) {
+	c.middleware = append(c.middleware, mw...)
+}
+
+// do sends req through the middleware chain
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	h := Handler(c.http.Do)
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
+	return h(req)
+}
+
+// LogRequests returns middleware that writes the method, URL, status and
+// duration of each request to w
+func LogRequests(w io.Writer) Middleware {
+	// Bulk commands send requests from several workers
+	var mu sync.Mutex
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			elapsed := time.Since(start).Round(time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				fmt.Fprintf(w, "%s %s: %v (%s)\n", req.Method, req.URL, err, elapsed)
+			} else {
+				fmt.Fprintf(w, "%s %s: %s (%s)\n", req.Method, req.URL, resp.Status, elapsed)
+			}
+			return resp, err
+		}
+	}
+}
+
+// ErrDryRun is returned instead of sending a request that would change
+// something when the client runs in dry-run mode
+var ErrDryRun = errors.New("dry run: request not sent")
+
+// DryRun returns middleware that writes the method, URL and JSON payload of
+// each request that would change something to w and fails it with ErrDryRun
+// instead of sending it. GET requests go through, so lookups still work.
+func DryRun(w io.Writer) Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodGet {
+				return next(req)
+			}
+
+			fmt.Fprintf(w, "%s %s\n", req.Method, req.URL)
+			if req.Body == nil {
+				return nil, ErrDryRun
+			}
+			data, err := io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("reading request body: %w", err)
+			}
+			var pretty bytes.Buffer
+			if strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") && json.Indent(&pretty, data, "", "  ") == nil {
+				fmt.Fprintln(w, pretty.String())
+			} else {
+				fmt.Fprintf(w, "(%s, %d bytes)\n", req.Header.Get("Content-Type"), len(data))
+			}
+			return nil, ErrDryRun
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package api
+
+import "encoding/json"
+
+// The resource types below mirror the objects returned by the Admin API.
+// Only the commonly used fields are included.
+
+// Post is a Ghost post
+type Post struct {
+	ID             string `json:"id"`
+	UUID           string `json:"uuid"`
+	Title          string `json:"title"`
+	Slug           string `json:"slug"`
+	HTML           string `json:"html,omitempty"`
+	Plaintext      string `json:"plaintext,omitempty"`
+	Status         string `json:"status"`
+	Visibility     string `json:"visibility"`
+	Featured       bool   `json:"featured"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+	PublishedAt    string `json:"published_at,omitempty"`
+	Excerpt        string `json:"excerpt,omitempty"`
+	CustomExcerpt  string `json:"custom_excerpt,omitempty"`
+	Tags           []Tag  `json:"tags,omitempty"`
+	Authors        []User `json:"authors,omitempty"`
+	URL            string `json:"url,omitempty"`
+	FeatureImg     string `json:"feature_image,omitempty"`
+	MetaTitle      string `json:"meta_title,omitempty"`
+	MetaDesc       string `json:"meta_description,omitempty"`
+	CanonicalURL   string `json:"canonical_url,omitempty"`
+	OGTitle        string `json:"og_title,omitempty"`
+	OGDesc         string `json:"og_description,omitempty"`
+	OGImage        string `json:"og_image,omitempty"`
+	TwitterTitle   string `json:"twitter_title,omitempty"`
+	TwitterDesc    string `json:"twitter_description,omitempty"`
+	TwitterImage   string `json:"twitter_image,omitempty"`
+	CustomTemplate string `json:"custom_template,omitempty"`
+	CodeHead       string `json:"codeinjection_head,omitempty"`
+	CodeFoot       string `json:"codeinjection_foot,omitempty"`
+}
+
+// Page is a Ghost page
+type Page struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Slug        string `json:"slug"`
+	HTML        string `json:"html,omitempty"`
+	Status      string `json:"status"`
+	Featured    bool   `json:"featured"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+	PublishedAt string `json:"published_at,omitempty"`
+	URL         string `json:"url,omitempty"`
+	FeatureImg  string `json:"feature_image,omitempty"`
+	Tags        []Tag  `json:"tags,omitempty"`
+	Authors     []User `json:"authors,omitempty"`
+}
+
+// Tag is a post or page tag
+type Tag struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	Description  string `json:"description,omitempty"`
+	FeatureImage string `json:"feature_image,omitempty"`
+	Visibility   string `json:"visibility"`
+	MetaTitle    string `json:"meta_title,omitempty"`
+	MetaDesc     string `json:"meta_description,omitempty"`
+	URL          string `json:"url,omitempty"`
+	PostCount    int    `json:"count,omitempty"`
+}
+
+// Member is a site member
+type Member struct {
+	ID          string       `json:"id"`
+	UUID        string       `json:"uuid"`
+	Email       string       `json:"email"`
+	Name        string       `json:"name,omitempty"`
+	Note        string       `json:"note,omitempty"`
+	Status      string       `json:"status"`
+	Subscribed  bool         `json:"subscribed"`
+	CreatedAt   string       `json:"created_at"`
+	Labels      []Label      `json:"labels,omitempty"`
+	Newsletters []Newsletter `json:"newsletters,omitempty"`
+
+	EmailCount       int    `json:"email_count"`
+	EmailOpenedCount int    `json:"email_opened_count"`
+	EmailOpenRate    *int   `json:"email_open_rate"`
+	LastSeenAt       string `json:"last_seen_at,omitempty"`
+	// UnsubscribeURL is the signed link from the footer of newsletter
+	// emails, on Ghost versions that return it
+	UnsubscribeURL string `json:"unsubscribe_url,omitempty"`
+}
+
+// Label is a member label
+type Label struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// Newsletter is a newsletter members can subscribe to
+type Newsletter struct {
+	ID                string `json:"id"`
+	UUID              string `json:"uuid,omitempty"`
+	Name              string `json:"name"`
+	Slug              string `json:"slug"`
+	Description       string `json:"description,omitempty"`
+	SenderName        string `json:"sender_name,omitempty"`
+	SenderEmail       string `json:"sender_email,omitempty"`
+	SenderReplyTo     string `json:"sender_reply_to,omitempty"`
+	Status            string `json:"status"`
+	Visibility        string `json:"visibility"`
+	SubscribeOnSignup bool   `json:"subscribe_on_signup"`
+	SortOrder         int    `json:"sort_order"`
+	CreatedAt         string `json:"created_at"`
+	UpdatedAt         string `json:"updated_at"`
+	TitleFont         string `json:"title_font_category,omitempty"`
+	BodyFont          string `json:"body_font_category,omitempty"`
+	ShowHeaderIcon    bool   `json:"show_header_icon"`
+	ShowHeaderTitle   bool   `json:"show_header_title"`
+	ShowHeaderName    bool   `json:"show_header_name"`
+}
+
+// Tier is a membership tier
+type Tier struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Slug           string `json:"slug"`
+	Description    string `json:"description,omitempty"`
+	Active         bool   `json:"active"`
+	Type           string `json:"type"`
+	WelcomePageURL string `json:"welcome_page_url,omitempty"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+	Visibility     string `json:"visibility"`
+	MonthlyPrice   int    `json:"monthly_price,omitempty"`
+	YearlyPrice    int    `json:"yearly_price,omitempty"`
+	Currency       string `json:"currency,omitempty"`
+	TrialDays      int    `json:"trial_days"`
+}
+
+// Offer is a discount or trial on a tier, redeemed through a link
+type Offer struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Code     string `json:"code"`
+	Status   string `json:"status"`
+	Cadence  string `json:"cadence"`
+	Type     string `json:"type"`
+	Amount   int    `json:"amount"`
+	Currency string `json:"currency,omitempty"`
+	Duration string `json:"duration"`
+	Tier     *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"tier,omitempty"`
+}
+
+// User is a staff user
+type User struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Slug          string `json:"slug"`
+	Email         string `json:"email"`
+	ProfileImage  string `json:"profile_image,omitempty"`
+	CoverImage    string `json:"cover_image,omitempty"`
+	Bio           string `json:"bio,omitempty"`
+	Website       string `json:"website,omitempty"`
+	Location      string `json:"location,omitempty"`
+	Status        string `json:"status"`
+	Accessibility string `json:"accessibility,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	LastSeen      string `json:"last_seen,omitempty"`
+	URL           string `json:"url,omitempty"`
+	Roles         []Role `json:"roles,omitempty"`
+}
+
+// Role is a staff role
+type Role struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Pagination is the paging metadata Ghost returns with browse responses
+type Pagination struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+	Pages int `json:"pages"`
+	Total int `json:"total"`
+	Next  int `json:"next"`
+	Prev  int `json:"prev"`
+}
+
+// UnmarshalJSON accepts the limit "all", which Ghost returns for
+// limit=all; the limit is then the total
+func (p *Pagination) UnmarshalJSON(data []byte) error {
+	type pagination Pagination
+	var raw struct {
+		pagination
+		Limit json.RawMessage `json:"limit"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*p = Pagination(raw.pagination)
+	if string(raw.Limit) == `"all"` {
+		p.Limit = p.Total
+		return nil
+	}
+	if len(raw.Limit) > 0 && string(raw.Limit) != "null" {
+		return json.Unmarshal(raw.Limit, &p.Limit)
+	}
+	return nil
+}
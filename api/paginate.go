@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// MaxLimit is the most resources Ghost returns in one response
+const MaxLimit = 100
+
+// LimitAll as ListOptions.Limit requests every resource in one response,
+// as limit=all, on the endpoints where Ghost allows it
+const LimitAll = -1
+
+// Paginate walks a browse endpoint such as /posts/, following
+// meta.pagination.next until the last page. Each page is yielded as the raw
+// JSON response. params can set a filter, order and page size (MaxLimit
+// unless smaller, or "all") and is not modified. Iteration stops after the
+// first error.
+func (c *Client) Paginate(path string, params url.Values) iter.Seq2[json.RawMessage, error] {
+	return c.paginate(context.Background(), path, params)
+}
+
+func (c *Client) paginate(ctx context.Context, path string, params url.Values) iter.Seq2[json.RawMessage, error] {
+	return func(yield func(json.RawMessage, error) bool) {
+		q := url.Values{}
+		for k, v := range params {
+			q[k] = v
+		}
+		if limit := q.Get("limit"); limit != "all" {
+			if n, err := strconv.Atoi(limit); err != nil || n > MaxLimit {
+				q.Set("limit", strconv.Itoa(MaxLimit))
+			}
+		}
+		page := 1
+		if p, err := strconv.Atoi(q.Get("page")); err == nil && p > 1 {
+			page = p
+		}
+
+		for {
+			q.Set("page", strconv.Itoa(page))
+
+			var data json.RawMessage
+			if err := c.sendJSON(ctx, "GET", path+"?"+q.Encode(), nil, &data); err != nil {
+				yield(nil, err)
+				return
+			}
+			var meta struct {
+				Meta struct {
+					Pagination Pagination `json:"pagination"`
+				} `json:"meta"`
+			}
+			if err := json.Unmarshal(data, &meta); err != nil {
+				yield(nil, fmt.Errorf("parsing response: %w", err))
+				return
+			}
+
+			if !yield(data, nil) {
+				return
+			}
+			next := meta.Meta.Pagination.Next
+			// A server that doesn't advance would otherwise loop forever
+			if next == 0 || next <= page {
+				return
+			}
+			page = next
+		}
+	}
+}
+
+// Browse fetches one page of a browse endpoint such as /posts/ into v, like
+// GetJSON. A limit above MaxLimit is fetched in several requests and
+// combined, with meta.pagination describing pages of the requested size.
+func (c *Client) Browse(path string, params url.Values, v interface{}) error {
+	data, err := c.browse(context.Background(), path, params)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) browse(ctx context.Context, path string, params url.Values) (json.RawMessage, error) {
+	limit, err := strconv.Atoi(params.Get("limit"))
+	if err != nil || limit <= MaxLimit {
+		var data json.RawMessage
+		fullPath := path
+		if len(params) > 0 {
+			fullPath += "?" + params.Encode()
+		}
+		err := c.sendJSON(ctx, "GET", fullPath, nil, &data)
+		return data, err
+	}
+
+	page := 1
+	if p, err := strconv.Atoi(params.Get("page")); err == nil && p > 1 {
+		page = p
+	}
+	// Start at the Ghost page holding the first resource of the requested
+	// page and skip the ones before it
+	start := (page - 1) * limit
+	skip := start % MaxLimit
+	q := url.Values{}
+	for k, v := range params {
+		q[k] = v
+	}
+	q.Set("limit", strconv.Itoa(MaxLimit))
+	q.Set("page", strconv.Itoa(start/MaxLimit+1))
+
+	var key string
+	items := []json.RawMessage{}
+	total := 0
+	for data, err := range c.paginate(ctx, path, q) {
+		if err != nil {
+			return nil, err
+		}
+		var resp map[string]json.RawMessage
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, fmt.Errorf("parsing response: %w", err)
+		}
+		var meta struct {
+			Pagination Pagination `json:"pagination"`
+		}
+		var pageItems []json.RawMessage
+		for k, raw := range resp {
+			if k == "meta" {
+				err = json.Unmarshal(raw, &meta)
+			} else {
+				key = k
+				err = json.Unmarshal(raw, &pageItems)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("parsing response: %w", err)
+			}
+		}
+		total = meta.Pagination.Total
+
+		items = append(items, pageItems[min(skip, len(pageItems)):]...)
+		skip = 0
+		if len(items) >= limit {
+			items = items[:limit]
+			break
+		}
+	}
+
+	pagination := Pagination{Page: page, Limit: limit, Pages: (total + limit - 1) / limit, Total: total}
+	if page < pagination.Pages {
+		pagination.Next = page + 1
+	}
+	if page > 1 {
+		pagination.Prev = page - 1
+	}
+	return json.Marshal(map[string]interface{}{
+		key:    items,
+		"meta": map[string]interface{}{"pagination": pagination},
+	})
+}
@@ -0,0 +1,350 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// fakePosts serves a /posts/ browse endpoint with total posts named p1,
+// p2, ... as Ghost paginates them, and records the requested limits and
+// pages
+type fakePosts struct {
+	total int
+	// stuck makes every page claim the same next page
+	stuck bool
+
+	mu     sync.Mutex
+	limits []string
+	pages  []string
+}
+
+func (f *fakePosts) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/ghost/api/admin/posts/" {
+		http.NotFound(w, r)
+		return
+	}
+	q := r.URL.Query()
+	f.mu.Lock()
+	f.limits = append(f.limits, q.Get("limit"))
+	f.pages = append(f.pages, q.Get("page"))
+	f.mu.Unlock()
+
+	page, _ := strconv.Atoi(q.Get("page"))
+	page = max(page, 1)
+	var limit interface{} = "all"
+	size := max(f.total, 1)
+	if q.Get("limit") != "all" {
+		size, _ = strconv.Atoi(q.Get("limit"))
+		limit = size
+	}
+	pages := max((f.total+size-1)/size, 1)
+	var next interface{}
+	switch {
+	case f.stuck:
+		next = page
+	case page < pages:
+		next = page + 1
+	}
+
+	posts := []map[string]string{}
+	for i := (page-1)*size + 1; i <= min(page*size, f.total); i++ {
+		posts = append(posts, map[string]string{"id": fmt.Sprintf("p%d", i)})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"posts": posts,
+		"meta": map[string]interface{}{"pagination": map[string]interface{}{
+			"page": page, "limit": limit, "pages": pages, "total": f.total, "next": next,
+		}},
+	})
+}
+
+func newTestClient(t *testing.T, h http.Handler) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return New(srv.URL, "test:00ff", Options{})
+}
+
+// postIDs returns the IDs of the posts in a browse response
+func postIDs(t *testing.T, data []byte) []string {
+	t.Helper()
+	var resp struct {
+		Posts []struct {
+			ID string `json:"id"`
+		} `json:"posts"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatalf("parsing response: %v", err)
+	}
+	ids := []string{}
+	for _, p := range resp.Posts {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+// idRange returns the IDs pfrom to pto
+func idRange(from, to int) []string {
+	ids := []string{}
+	for i := from; i <= to; i++ {
+		ids = append(ids, fmt.Sprintf("p%d", i))
+	}
+	return ids
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      int
+		params     url.Values
+		wantLimits []string
+		wantPages  []string
+		wantIDs    []string
+	}{
+		{
+			name:       "default limit",
+			total:      250,
+			params:     url.Values{},
+			wantLimits: []string{"100", "100", "100"},
+			wantPages:  []string{"1", "2", "3"},
+			wantIDs:    idRange(1, 250),
+		},
+		{
+			name:       "limit above the maximum",
+			total:      150,
+			params:     url.Values{"limit": {"500"}},
+			wantLimits: []string{"100", "100"},
+			wantPages:  []string{"1", "2"},
+			wantIDs:    idRange(1, 150),
+		},
+		{
+			name:       "small limit",
+			total:      25,
+			params:     url.Values{"limit": {"10"}},
+			wantLimits: []string{"10", "10", "10"},
+			wantPages:  []string{"1", "2", "3"},
+			wantIDs:    idRange(1, 25),
+		},
+		{
+			name:       "limit all",
+			total:      250,
+			params:     url.Values{"limit": {"all"}},
+			wantLimits: []string{"all"},
+			wantPages:  []string{"1"},
+			wantIDs:    idRange(1, 250),
+		},
+		{
+			name:       "starting page",
+			total:      250,
+			params:     url.Values{"page": {"2"}},
+			wantLimits: []string{"100", "100"},
+			wantPages:  []string{"2", "3"},
+			wantIDs:    idRange(101, 250),
+		},
+		{
+			name:       "no results",
+			total:      0,
+			params:     url.Values{},
+			wantLimits: []string{"100"},
+			wantPages:  []string{"1"},
+			wantIDs:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakePosts{total: tt.total}
+			c := newTestClient(t, f)
+			before := tt.params.Encode()
+
+			ids := []string{}
+			for data, err := range c.Paginate("/posts/", tt.params) {
+				if err != nil {
+					t.Fatalf("Paginate: %v", err)
+				}
+				ids = append(ids, postIDs(t, data)...)
+			}
+
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("got posts %v, want %v", ids, tt.wantIDs)
+			}
+			if !slices.Equal(f.limits, tt.wantLimits) {
+				t.Errorf("limits = %v, want %v", f.limits, tt.wantLimits)
+			}
+			if !slices.Equal(f.pages, tt.wantPages) {
+				t.Errorf("pages = %v, want %v", f.pages, tt.wantPages)
+			}
+			if after := tt.params.Encode(); after != before {
+				t.Errorf("params changed from %q to %q", before, after)
+			}
+		})
+	}
+}
+
+func TestPaginateStopsWhenNextDoesNotAdvance(t *testing.T) {
+	f := &fakePosts{total: 250, stuck: true}
+	c := newTestClient(t, f)
+
+	n := 0
+	for _, err := range c.Paginate("/posts/", nil) {
+		if err != nil {
+			t.Fatalf("Paginate: %v", err)
+		}
+		n++
+	}
+	if n != 1 {
+		t.Errorf("got %d pages, want 1", n)
+	}
+}
+
+func TestPaginateError(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"errors":[{"message":"boom"}]}`))
+	}))
+
+	var errs []error
+	for data, err := range c.Paginate("/posts/", nil) {
+		if data != nil {
+			t.Errorf("got data %s with the error", data)
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || errs[0] == nil {
+		t.Errorf("got errors %v, want one", errs)
+	}
+}
+
+func TestBrowse(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      int
+		params     url.Values
+		wantLimits []string
+		wantPages  []string
+		wantIDs    []string
+		want       Pagination
+	}{
+		{
+			name:       "limit within one request",
+			total:      120,
+			params:     url.Values{"limit": {"30"}, "page": {"2"}},
+			wantLimits: []string{"30"},
+			wantPages:  []string{"2"},
+			wantIDs:    idRange(31, 60),
+			want:       Pagination{Page: 2, Limit: 30, Pages: 4, Total: 120, Next: 3},
+		},
+		{
+			name:       "first page split into requests",
+			total:      620,
+			params:     url.Values{"limit": {"250"}},
+			wantLimits: []string{"100", "100", "100"},
+			wantPages:  []string{"1", "2", "3"},
+			wantIDs:    idRange(1, 250),
+			want:       Pagination{Page: 1, Limit: 250, Pages: 3, Total: 620, Next: 2},
+		},
+		{
+			name:       "later page skips into a Ghost page",
+			total:      620,
+			params:     url.Values{"limit": {"250"}, "page": {"2"}},
+			wantLimits: []string{"100", "100", "100"},
+			wantPages:  []string{"3", "4", "5"},
+			wantIDs:    idRange(251, 500),
+			want:       Pagination{Page: 2, Limit: 250, Pages: 3, Total: 620, Next: 3, Prev: 1},
+		},
+		{
+			name:       "last page is short",
+			total:      620,
+			params:     url.Values{"limit": {"250"}, "page": {"3"}},
+			wantLimits: []string{"100", "100"},
+			wantPages:  []string{"6", "7"},
+			wantIDs:    idRange(501, 620),
+			want:       Pagination{Page: 3, Limit: 250, Pages: 3, Total: 620, Prev: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakePosts{total: tt.total}
+			c := newTestClient(t, f)
+
+			var resp struct {
+				Meta struct {
+					Pagination Pagination `json:"pagination"`
+				} `json:"meta"`
+			}
+			var raw json.RawMessage
+			if err := c.Browse("/posts/", tt.params, &raw); err != nil {
+				t.Fatalf("Browse: %v", err)
+			}
+			if err := json.Unmarshal(raw, &resp); err != nil {
+				t.Fatalf("parsing response: %v", err)
+			}
+
+			if ids := postIDs(t, raw); !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("got posts %v, want %v", ids, tt.wantIDs)
+			}
+			if resp.Meta.Pagination != tt.want {
+				t.Errorf("pagination = %+v, want %+v", resp.Meta.Pagination, tt.want)
+			}
+			if !slices.Equal(f.limits, tt.wantLimits) {
+				t.Errorf("limits = %v, want %v", f.limits, tt.wantLimits)
+			}
+			if !slices.Equal(f.pages, tt.wantPages) {
+				t.Errorf("pages = %v, want %v", f.pages, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestPaginationUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Pagination
+	}{
+		{
+			name: "numeric limit",
+			data: `{"page":2,"limit":15,"pages":4,"total":50,"next":3,"prev":1}`,
+			want: Pagination{Page: 2, Limit: 15, Pages: 4, Total: 50, Next: 3, Prev: 1},
+		},
+		{
+			name: "limit all",
+			data: `{"page":1,"limit":"all","pages":1,"total":42,"next":null,"prev":null}`,
+			want: Pagination{Page: 1, Limit: 42, Pages: 1, Total: 42},
+		},
+		{
+			name: "null limit",
+			data: `{"page":1,"limit":null,"pages":1,"total":3}`,
+			want: Pagination{Page: 1, Pages: 1, Total: 3},
+		},
+		{
+			name: "no limit",
+			data: `{"page":1,"pages":1,"total":3}`,
+			want: Pagination{Page: 1, Pages: 1, Total: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Pagination
+			if err := json.Unmarshal([]byte(tt.data), &p); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if p != tt.want {
+				t.Errorf("got %+v, want %+v", p, tt.want)
+			}
+		})
+	}
+
+	var p Pagination
+	if err := json.Unmarshal([]byte(`{"limit":"ten"}`), &p); err == nil {
+		t.Errorf("limit \"ten\": got no error")
+	}
+}
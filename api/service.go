@@ -0,0 +1,281 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ReadOptions selects what is returned for each resource
+type ReadOptions struct {
+	// Include adds related resources, e.g. "tags" or "authors"
+	Include []string
+	// Fields limits the response to these fields
+	Fields []string
+	// Formats selects content formats for posts and pages, e.g. "html"
+	Formats []string
+}
+
+// ListOptions are the browse parameters of a list request
+type ListOptions struct {
+	ReadOptions
+	// Filter is an NQL filter, e.g. "status:published+tag:news"
+	Filter string
+	// Order is a sort order, e.g. "published_at desc"
+	Order string
+	// Limit is the page size; 0 uses Ghost's default of 15. List fetches
+	// pages above MaxLimit in several requests, and LimitAll fetches
+	// everything where Ghost allows it.
+	Limit int
+	// Page is the page to fetch, starting at 1
+	Page int
+}
+
+func (o *ReadOptions) values(params url.Values) {
+	if o == nil {
+		return
+	}
+	if len(o.Include) > 0 {
+		params.Set("include", strings.Join(o.Include, ","))
+	}
+	if len(o.Fields) > 0 {
+		params.Set("fields", strings.Join(o.Fields, ","))
+	}
+	if len(o.Formats) > 0 {
+		params.Set("formats", strings.Join(o.Formats, ","))
+	}
+}
+
+func (o *ListOptions) values() url.Values {
+	params := url.Values{}
+	if o == nil {
+		return params
+	}
+	o.ReadOptions.values(params)
+	if o.Filter != "" {
+		params.Set("filter", o.Filter)
+	}
+	if o.Order != "" {
+		params.Set("order", o.Order)
+	}
+	if o.Limit > 0 {
+		params.Set("limit", strconv.Itoa(o.Limit))
+	} else if o.Limit == LimitAll {
+		params.Set("limit", "all")
+	}
+	if o.Page > 0 {
+		params.Set("page", strconv.Itoa(o.Page))
+	}
+	return params
+}
+
+// WriteOptions are the query parameters of a create or update request
+type WriteOptions struct {
+	// Newsletter is the slug of the newsletter that emails a post as it is
+	// published
+	Newsletter string
+	// EmailSegment limits that email to members matching an NQL filter,
+	// e.g. "status:-free"; empty sends it to all of the newsletter's
+	// subscribers
+	EmailSegment string
+}
+
+// query returns the query string of a write request, or "" if there is
+// none. Ghost 4 has a single newsletter, so there only the segment is sent.
+func (o *WriteOptions) query(c *Client) string {
+	if o == nil || o.Newsletter == "" {
+		return ""
+	}
+	params := url.Values{}
+	if c.ServerMajor() == 4 {
+		filter := o.EmailSegment
+		if filter == "" {
+			filter = "all"
+		}
+		params.Set("email_recipient_filter", filter)
+		return "?" + params.Encode()
+	}
+	params.Set("newsletter", o.Newsletter)
+	if o.EmailSegment != "" {
+		params.Set("email_segment", o.EmailSegment)
+	}
+	return "?" + params.Encode()
+}
+
+// List is one page of a browse response
+type List[T any] struct {
+	Items      []T
+	Pagination Pagination
+}
+
+// Service provides typed access to one kind of resource, e.g. posts
+type Service[T any] struct {
+	client *Client
+	// resource is the API path segment and response key, e.g. "posts"
+	resource string
+	// name is used in errors, e.g. "post"
+	name string
+	// lookupField is the unique field besides the ID that Lookup accepts
+	lookupField string
+}
+
+func newService[T any](c *Client, resource, name, lookupField string) *Service[T] {
+	return &Service[T]{client: c, resource: resource, name: name, lookupField: lookupField}
+}
+
+// List fetches one page of resources
+func (s *Service[T]) List(ctx context.Context, opts *ListOptions) (*List[T], error) {
+	data, err := s.client.browse(ctx, "/"+s.resource+"/", opts.values())
+	if err != nil {
+		return nil, err
+	}
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	list := &List[T]{}
+	if err := json.Unmarshal(resp[s.resource], &list.Items); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+	if meta, ok := resp["meta"]; ok {
+		var m struct {
+			Pagination Pagination `json:"pagination"`
+		}
+		if err := json.Unmarshal(meta, &m); err != nil {
+			return nil, fmt.Errorf("parsing response: %w", err)
+		}
+		list.Pagination = m.Pagination
+	}
+	return list, nil
+}
+
+// All iterates over every resource matching opts, fetching pages of
+// MaxLimit (or opts.Limit, if smaller) as needed. Iteration stops after the
+// first error.
+func (s *Service[T]) All(ctx context.Context, opts *ListOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		for data, err := range s.client.paginate(ctx, "/"+s.resource+"/", opts.values()) {
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			var page map[string]json.RawMessage
+			var items []T
+			if err := json.Unmarshal(data, &page); err == nil {
+				err = json.Unmarshal(page[s.resource], &items)
+			}
+			if err != nil {
+				yield(zero, fmt.Errorf("parsing response: %w", err))
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Get fetches a resource by ID
+func (s *Service[T]) Get(ctx context.Context, id string, opts *ReadOptions) (*T, error) {
+	params := url.Values{}
+	opts.values(params)
+	path := fmt.Sprintf("/%s/%s/", s.resource, url.PathEscape(id))
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	return s.one(ctx, "GET", path, nil, id)
+}
+
+// Lookup fetches a resource by ID, or else by slug (email for members).
+// ref can also be a URL of the resource, as accepted by ParseRef.
+func (s *Service[T]) Lookup(ctx context.Context, ref string, opts *ReadOptions) (*T, error) {
+	ref = ParseRef(ref)
+	if item, err := s.Get(ctx, ref, opts); err == nil {
+		return item, nil
+	}
+
+	o := &ListOptions{Filter: fmt.Sprintf("%s:'%s'", s.lookupField, strings.ReplaceAll(ref, "'", "\\'")), Limit: 1}
+	if opts != nil {
+		o.ReadOptions = *opts
+	}
+	list, err := s.List(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, fmt.Errorf("%s not found: %s", s.name, ref)
+	}
+	return &list.Items[0], nil
+}
+
+// ParseRef returns the ID or slug in a URL of a resource, so links can be
+// pasted from a browser: the ID or slug at the end of a Ghost Admin URL
+// such as https://example.com/ghost/#/editor/post/<id>, or the slug at the
+// end of a site URL such as https://example.com/tag/news/. Anything that
+// isn't an http(s) URL is returned as is.
+func ParseRef(ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ref
+	}
+	path := u.Path
+	if strings.HasPrefix(u.Fragment, "/") {
+		// Ghost Admin routes are in the fragment
+		path, _, _ = strings.Cut(u.Fragment, "?")
+	}
+	segments := strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+	if len(segments) == 0 {
+		return ref
+	}
+	last, err := url.PathUnescape(segments[len(segments)-1])
+	if err != nil {
+		return segments[len(segments)-1]
+	}
+	return last
+}
+
+// Create creates a resource from v, which is a T or a map of fields
+func (s *Service[T]) Create(ctx context.Context, v interface{}, opts *WriteOptions) (*T, error) {
+	body := map[string]interface{}{s.resource: []interface{}{v}}
+	return s.one(ctx, "POST", "/"+s.resource+"/"+opts.query(s.client), body, "")
+}
+
+// Update changes the resource with the given ID. v holds the fields to
+// change; posts and pages also need the updated_at of the version being
+// edited.
+func (s *Service[T]) Update(ctx context.Context, id string, v interface{}, opts *WriteOptions) (*T, error) {
+	body := map[string]interface{}{s.resource: []interface{}{v}}
+	path := fmt.Sprintf("/%s/%s/", s.resource, url.PathEscape(id)) + opts.query(s.client)
+	return s.one(ctx, "PUT", path, body, id)
+}
+
+// Delete deletes the resource with the given ID
+func (s *Service[T]) Delete(ctx context.Context, id string) error {
+	return s.client.sendJSON(ctx, "DELETE", fmt.Sprintf("/%s/%s/", s.resource, url.PathEscape(id)), nil, nil)
+}
+
+// one sends a request whose response holds a single resource
+func (s *Service[T]) one(ctx context.Context, method, path string, body interface{}, ref string) (*T, error) {
+	var resp map[string]json.RawMessage
+	if err := s.client.sendJSON(ctx, method, path, body, &resp); err != nil {
+		return nil, err
+	}
+	var items []T
+	if raw, ok := resp[s.resource]; ok {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, fmt.Errorf("parsing response: %w", err)
+		}
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%s not found: %s", s.name, ref)
+	}
+	return &items[0], nil
+}
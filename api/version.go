@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinGhostMajor is the oldest major Ghost version specter supports
+const MinGhostMajor = 4
+
+// apiVersions maps the Ghost major versions specter supports to the API
+// version it requests from them
+var apiVersions = map[int]string{4: "v4.0", 5: "v5.0", 6: "v6.0"}
+
+// endpointMinMajor lists endpoints, by path prefix, that older Ghost
+// versions don't have, with the first major version that does
+var endpointMinMajor = map[string]int{
+	"/newsletters/": 5,
+	"/tiers/":       5,
+}
+
+// versionCacheTTL is how long a detected Ghost version is trusted before
+// the site is asked again
+const versionCacheTTL = 24 * time.Hour
+
+// SupportedVersion returns the API version to request from a server running
+// the given Ghost version, and whether specter supports that version. Newer
+// majors get the newest API version specter knows; unknown versions get
+// APIVersion.
+func SupportedVersion(ghostVersion string) (string, bool) {
+	major := majorVersion(ghostVersion)
+	if v, ok := apiVersions[major]; ok {
+		return v, true
+	}
+	newest := 0
+	for m := range apiVersions {
+		newest = max(newest, m)
+	}
+	if major > newest {
+		return apiVersions[newest], false
+	}
+	return APIVersion, false
+}
+
+// majorVersion returns the major version of a Ghost version such as 5.96.2,
+// or 0 if it can't be parsed
+func majorVersion(v string) int {
+	major, _ := strconv.Atoi(strings.SplitN(v, ".", 2)[0])
+	return major
+}
+
+// ServerVersion returns the Ghost version of the site, e.g. 5.96, detected
+// from /site/ before the first request and cached per site. It is "" if
+// detection failed.
+func (c *Client) ServerVersion() string {
+	c.negotiate()
+	return c.serverVersion
+}
+
+// ServerMajor returns the major Ghost version of the site, or 0 if unknown
+func (c *Client) ServerMajor() int {
+	return majorVersion(c.ServerVersion())
+}
+
+// SetServerVersion records the site's Ghost version, e.g. after reading
+// /site/, and updates the cache. Like Use, it must not be called while
+// requests are in flight.
+func (c *Client) SetServerVersion(v string) {
+	c.negotiate()
+	c.setVersion(v)
+	c.saveVersion(v)
+}
+
+func (c *Client) setVersion(v string) {
+	c.serverVersion = v
+	if v == "" {
+		c.acceptVersion = APIVersion
+		return
+	}
+	c.acceptVersion, _ = SupportedVersion(v)
+}
+
+// negotiate detects the server version once, from the cache or /site/,
+// and picks the Accept-Version to send. Failures fall back to APIVersion,
+// and the request that needed it reports the actual problem.
+func (c *Client) negotiate() {
+	c.versionOnce.Do(func() {
+		v, ok := c.cachedVersion()
+		if !ok {
+			v = c.detectVersion()
+			if v != "" {
+				c.saveVersion(v)
+			}
+		}
+		c.setVersion(v)
+	})
+}
+
+// checkEndpoint warns, once per endpoint, if the site's Ghost version
+// doesn't have the endpoint at path
+func (c *Client) checkEndpoint(path string) {
+	major := c.ServerMajor()
+	if major == 0 {
+		return
+	}
+	for prefix, first := range endpointMinMajor {
+		if !strings.HasPrefix(path, prefix) || major >= first {
+			continue
+		}
+		c.warnedMu.Lock()
+		warned := c.warned[prefix]
+		if c.warned == nil {
+			c.warned = map[string]bool{}
+		}
+		c.warned[prefix] = true
+		c.warnedMu.Unlock()
+		if !warned {
+			fmt.Fprintf(os.Stderr, "Warning: %s needs Ghost %d or later, but the site runs Ghost %s\n", prefix, first, c.serverVersion)
+		}
+	}
+}
+
+// detectVersion reads the Ghost version from the Admin API's /site/, which
+// every version serves without authentication, so Content API clients can
+// use it too
+func (c *Client) detectVersion() string {
+	req, err := http.NewRequestWithContext(context.Background(), "GET", c.baseURL+"/ghost/api/admin/site/", nil)
+	if err != nil {
+		return ""
+	}
+	c.setHeaders(req, "")
+	// The Content API key is for the Content API only
+	req.URL.RawQuery = ""
+
+	resp, err := c.do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return ""
+	}
+	var site struct {
+		Site struct {
+			Version string `json:"version"`
+		} `json:"site"`
+	}
+	if err := json.NewDecoder(c.limitBody(resp)).Decode(&site); err != nil {
+		return ""
+	}
+	return site.Site.Version
+}
+
+// versionCacheEntry is a detected Ghost version in the cache file
+type versionCacheEntry struct {
+	Version   string    `json:"version"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+func (c *Client) versionCachePath() string {
+	if c.cacheDir == "" {
+		return ""
+	}
+	return filepath.Join(c.cacheDir, "versions.json")
+}
+
+func (c *Client) loadVersionCache() map[string]versionCacheEntry {
+	entries := map[string]versionCacheEntry{}
+	if path := c.versionCachePath(); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			_ = json.Unmarshal(data, &entries)
+		}
+	}
+	return entries
+}
+
+// cachedVersion returns the site's version from the cache, if it was
+// detected recently
+func (c *Client) cachedVersion() (string, bool) {
+	entry, ok := c.loadVersionCache()[c.baseURL]
+	if !ok || time.Since(entry.CheckedAt) > versionCacheTTL {
+		return "", false
+	}
+	return entry.Version, true
+}
+
+// saveVersion caches the site's version. The cache is an optimization, so
+// errors are ignored.
+func (c *Client) saveVersion(v string) {
+	path := c.versionCachePath()
+	if path == "" {
+		return
+	}
+	entries := c.loadVersionCache()
+	entries[c.baseURL] = versionCacheEntry{Version: v, CheckedAt: time.Now()}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0600)
+}
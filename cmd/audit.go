@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check the public site for problems",
+}
+
+var auditSitemapCmd = &cobra.Command{
+	Use:   "sitemap",
+	Short: "Compare the public sitemap with the site's content",
+	Long: `Read the site's public sitemap.xml, and the sitemaps it links to, and
+compare it with the posts and pages in Ghost, to catch routing and theme
+mistakes that hide content from search engines.
+
+Two problems are reported: published posts and pages that aren't in the
+sitemap, and sitemap URLs that don't load, e.g. a 404 after a collection
+in routes.yaml changed. URLs are compared by path, so a site reachable
+with and without www is one site. Each sitemap URL is requested, in
+parallel (--workers), unless --no-fetch is given.
+
+Exits with an error if any problem is found.`,
+	Example: `  specter audit sitemap
+  specter audit sitemap --workers 16 --report sitemap.json
+  specter audit sitemap --no-fetch -o json | jq '.[] | select(.type == "post")'`,
+	Args: cobra.NoArgs,
+	RunE: runAuditSitemap,
+}
+
+var (
+	auditWorkers int
+	auditNoFetch bool
+)
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditSitemapCmd)
+	auditSitemapCmd.Flags().IntVar(&auditWorkers, "workers", 4, "Concurrent requests for sitemap URLs")
+	auditSitemapCmd.Flags().BoolVar(&auditNoFetch, "no-fetch", false, "Only compare the sitemap with the content, without requesting its URLs")
+	addReportFlag(auditSitemapCmd)
+}
+
+// SitemapIssue is a problem found by comparing the sitemap with the site
+type SitemapIssue struct {
+	URL string `json:"url"`
+	// Type is post or page for content missing from the sitemap, or
+	// sitemap for a URL listed in it
+	Type    string `json:"type"`
+	Slug    string `json:"slug,omitempty"`
+	Problem string `json:"problem"`
+	// Status is the HTTP status of a sitemap URL that didn't load
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// sitemapContent is a published post or page, which should be in the
+// sitemap
+type sitemapContent struct {
+	Type string
+	Slug string
+	URL  string
+}
+
+func runAuditSitemap(cmd *cobra.Command, args []string) error {
+	// Problems found are listed, not a usage mistake
+	cmd.SilenceUsage = true
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	siteURL, err := portalSiteURL(client, cfg)
+	if err != nil {
+		return err
+	}
+	httpClient := &http.Client{Timeout: time.Minute}
+	userAgent := flagOr(cfg.UserAgent, api.DefaultUserAgent)
+	sitemap, err := readSitemap(httpClient, userAgent, siteURL+"/sitemap.xml")
+	if err != nil {
+		return err
+	}
+
+	var published []sitemapContent
+	opts := &api.ListOptions{Filter: "status:published", ReadOptions: api.ReadOptions{Fields: []string{"slug", "url"}}}
+	for p, err := range client.Posts.All(context.Background(), opts) {
+		if err != nil {
+			return err
+		}
+		published = append(published, sitemapContent{Type: "post", Slug: p.Slug, URL: p.URL})
+	}
+	for p, err := range client.Pages.All(context.Background(), opts) {
+		if err != nil {
+			return err
+		}
+		published = append(published, sitemapContent{Type: "page", Slug: p.Slug, URL: p.URL})
+	}
+
+	listed := map[string]bool{}
+	for _, u := range sitemap {
+		listed[sitemapPath(u)] = true
+	}
+	var issues []SitemapIssue
+	for _, c := range published {
+		if !listed[sitemapPath(c.URL)] {
+			issues = append(issues, SitemapIssue{URL: c.URL, Type: c.Type, Slug: c.Slug, Problem: "missing from sitemap"})
+		}
+	}
+
+	if !auditNoFetch {
+		broken := checkSitemapURLs(httpClient, userAgent, sitemap, max(auditWorkers, 1))
+		issues = append(issues, broken...)
+	}
+
+	if err := writeReport(cmd, cfg, issues, len(issues)); err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		fmt.Fprintf(os.Stderr, "No problems: the sitemap lists all %s, and %s.\n", countPostsAndPages(countContent(published, "post"), countContent(published, "page")), sitemapCheckedDesc(len(sitemap)))
+		if config.OutputFormat() == "json" {
+			return printJSON(issues)
+		}
+		return nil
+	}
+	err = render(issues, []output.Column[SitemapIssue]{
+		{Header: "URL", Value: func(r SitemapIssue) string { return r.URL }},
+		{Header: "TYPE", Value: func(r SitemapIssue) string { return r.Type }},
+		{Header: "SLUG", Value: func(r SitemapIssue) string { return orDash(r.Slug) }, Wide: true},
+		{Header: "PROBLEM", Value: func(r SitemapIssue) string { return r.Problem }},
+		{Header: "ERROR", Value: func(r SitemapIssue) string { return orDash(r.Error) }, Wide: true},
+	})
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("found %s", plural(len(issues), "problem"))
+}
+
+// countContent counts the items of one type
+func countContent(items []sitemapContent, typ string) int {
+	n := 0
+	for _, c := range items {
+		if c.Type == typ {
+			n++
+		}
+	}
+	return n
+}
+
+// sitemapCheckedDesc says what was done with the sitemap's URLs
+func sitemapCheckedDesc(n int) string {
+	if auditNoFetch {
+		return fmt.Sprintf("its %s weren't requested", plural(n, "URL"))
+	}
+	return fmt.Sprintf("its %s load", plural(n, "URL"))
+}
+
+// sitemapXML is a sitemap or a sitemap index, which lists other sitemaps,
+// as Ghost's sitemap.xml does
+type sitemapXML struct {
+	XMLName  xml.Name
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+}
+
+// readSitemap returns the URLs listed in a sitemap and in the sitemaps an
+// index at sitemapURL links to
+func readSitemap(client *http.Client, userAgent, sitemapURL string) ([]string, error) {
+	var urls []string
+	seen := map[string]bool{}
+	var read func(u string) error
+	read = func(u string) error {
+		if seen[u] {
+			return nil
+		}
+		seen[u] = true
+		data, err := fetchSitemap(client, userAgent, u)
+		if err != nil {
+			return err
+		}
+		var sm sitemapXML
+		if err := xml.Unmarshal(data, &sm); err != nil {
+			return fmt.Errorf("parsing %s: %w", u, err)
+		}
+		for _, loc := range sm.URLs {
+			urls = append(urls, strings.TrimSpace(loc.Loc))
+		}
+		for _, s := range sm.Sitemaps {
+			if err := read(strings.TrimSpace(s.Loc)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := read(sitemapURL); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// fetchSitemap downloads one sitemap
+func fetchSitemap(client *http.Client, userAgent, u string) ([]byte, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	// A private site sends every visitor to its password page
+	if strings.Contains(resp.Request.URL.Path, "/private/") {
+		return nil, fmt.Errorf("the site is private, so it has no public sitemap")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", u, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// sitemapPath returns the path of a URL for comparison, ending in "/"
+func sitemapPath(u string) string {
+	p := u
+	if parsed, err := url.Parse(u); err == nil {
+		p = parsed.Path
+	}
+	if !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+	return p
+}
+
+// checkSitemapURLs requests the URLs in a sitemap and returns those that
+// don't load, sorted
+func checkSitemapURLs(client *http.Client, userAgent string, urls []string, workers int) []SitemapIssue {
+	var (
+		mu     sync.Mutex
+		issues []SitemapIssue
+		wg     sync.WaitGroup
+	)
+	jobs := make(chan string)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range jobs {
+				status, err := sitemapURLStatus(client, userAgent, u)
+				issue := SitemapIssue{URL: u, Type: "sitemap", Status: status}
+				switch {
+				case err != nil:
+					issue.Problem, issue.Error = "unreachable", err.Error()
+				case status == http.StatusNotFound || status == http.StatusGone:
+					issue.Problem = "not found"
+				case status >= 400:
+					issue.Problem, issue.Error = "error", fmt.Sprintf("%d %s", status, http.StatusText(status))
+				default:
+					continue
+				}
+				mu.Lock()
+				issues = append(issues, issue)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, u := range urls {
+		jobs <- u
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(issues, func(i, j int) bool { return issues[i].URL < issues[j].URL })
+	return issues
+}
+
+// sitemapURLStatus returns the status a URL ends with after redirects,
+// trying GET when a server doesn't allow HEAD
+func sitemapURLStatus(client *http.Client, userAgent, u string) (int, error) {
+	var status int
+	for _, method := range []string{"HEAD", "GET"} {
+		req, err := http.NewRequest(method, u, nil)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("User-Agent", userAgent)
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		status = resp.StatusCode
+		if status != http.StatusMethodNotAllowed {
+			break
+		}
+	}
+	return status, nil
+}
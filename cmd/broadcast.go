@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+// singleProfileCmds manage profiles themselves or don't talk to a site, so
+// --profiles doesn't apply to them or their subcommands
+var singleProfileCmds = []*cobra.Command{loginCmd, logoutCmd, profilesCmd, envCmd, introspectCmd, schemaCmd, helpCmd}
+
+// ProfileResult is the outcome of running a command for one profile
+type ProfileResult struct {
+	Profile string `json:"profile"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// enableBroadcast makes the commands under cmd run once per profile when
+// --profiles or --all-profiles is given
+func enableBroadcast(cmd *cobra.Command) {
+	var wrap func(cmd *cobra.Command, single bool)
+	wrap = func(cmd *cobra.Command, single bool) {
+		single = single || slices.Contains(singleProfileCmds, cmd)
+		if run := cmd.RunE; run != nil {
+			cmd.RunE = func(cmd *cobra.Command, args []string) error {
+				switch {
+				case !broadcasting():
+					return run(cmd, args)
+				case single:
+					return fmt.Errorf("--profiles and --all-profiles don't apply to '%s'", cmd.CommandPath())
+				}
+				return broadcast(cmd, args, run)
+			}
+		}
+		for _, sub := range cmd.Commands() {
+			wrap(sub, single)
+		}
+	}
+	wrap(cmd, false)
+}
+
+// broadcasting reports whether the command runs for several profiles
+func broadcasting() bool {
+	return len(config.FlagProfiles) > 0 || config.FlagAllProfiles
+}
+
+// broadcastProfiles returns the profiles to run the command for, in the
+// order given, or sorted for --all-profiles
+func broadcastProfiles() ([]string, error) {
+	names, _, err := config.ListInstances()
+	if err != nil {
+		return nil, err
+	}
+	if config.FlagAllProfiles {
+		if len(config.FlagProfiles) > 0 {
+			return nil, fmt.Errorf("use --profiles or --all-profiles, not both")
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no profiles configured. Run 'specter login' to set up")
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+
+	known := map[string]bool{}
+	for _, name := range names {
+		known[name] = true
+	}
+	var profiles []string
+	seen := map[string]bool{}
+	for _, name := range config.FlagProfiles {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("profile not found: %s", name)
+		}
+		seen[name] = true
+		profiles = append(profiles, name)
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("--profiles must name at least one profile")
+	}
+	return profiles, nil
+}
+
+// broadcast runs a command once for each selected profile, continuing
+// after failures, and then prints how it went for each
+func broadcast(cmd *cobra.Command, args []string, run func(*cobra.Command, []string) error) error {
+	// The results say what failed; a usage message would hide them
+	cmd.SilenceUsage = true
+	switch {
+	case config.FlagProfile != "":
+		return fmt.Errorf("use --profile or --profiles, not both")
+	case config.FlagURL != "" || config.FlagKey != "":
+		return fmt.Errorf("--url and --key can't be used with --profiles: they would apply to every profile")
+	case os.Getenv("GHOST_URL") != "" || os.Getenv("GHOST_ADMIN_KEY") != "":
+		return fmt.Errorf("GHOST_URL and GHOST_ADMIN_KEY override every profile; unset them to use --profiles")
+	}
+	profiles, err := broadcastProfiles()
+	if err != nil {
+		return err
+	}
+
+	results := make([]ProfileResult, len(profiles))
+	failed := 0
+	for i, name := range profiles {
+		fmt.Fprintf(os.Stderr, "==> %s\n", name)
+		config.FlagProfile = name
+		err := run(cmd, args)
+		results[i] = ProfileResult{Profile: name, Status: "ok"}
+		switch {
+		case errors.Is(err, api.ErrDryRun):
+			results[i].Status = "dry run"
+		case err != nil:
+			output.Error(os.Stderr, err, outputOptions())
+			results[i].Status, results[i].Error = "failed", err.Error()
+			failed++
+		}
+	}
+	config.FlagProfile = ""
+
+	fmt.Fprintln(os.Stderr)
+	err = output.Render(os.Stderr, results, []output.Column[ProfileResult]{
+		{Header: "PROFILE", Value: func(r ProfileResult) string { return r.Profile }},
+		{Header: "STATUS", Value: func(r ProfileResult) string { return r.Status }},
+		{Header: "ERROR", Value: func(r ProfileResult) string { return orDash(r.Error) }},
+	}, output.Options{Format: "text"})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d profiles failed", failed, len(profiles))
+	}
+	return nil
+}
+
+// broadcastReportPath returns where --report writes the report for a
+// profile: with several profiles, each gets its own file, e.g.
+// report.eu.json
+func broadcastReportPath(dest, profile string) string {
+	if !broadcasting() || profile == "" {
+		return dest
+	}
+	dir, file := path.Split(dest)
+	ext := path.Ext(file)
+	return dir + strings.TrimSuffix(file, ext) + "." + profile + ext
+}
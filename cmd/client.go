@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+)
+
+// newClient creates an API client for the profile, set up by the global
+// flags: --content-api, --override-freeze, --debug and --dry-run
+func newClient(cfg *config.Config) *api.Client {
+	opts := api.Options{
+		Profile: cfg.Name,
+		// A dry run sends no changes, so it can show them on a frozen
+		// profile too
+		Frozen:        cfg.Frozen && !config.FlagOverrideFreeze && !config.FlagDryRun,
+		UserAgent:     cfg.UserAgent,
+		Headers:       proxyHeaders(cfg),
+		MaxResponseMB: cfg.MaxResponseMB,
+		CacheDir:      config.CacheDir(),
+	}
+	if config.FlagContentAPI {
+		opts.ContentKey = cfg.ContentKey
+	}
+	c := api.New(cfg.URL, cfg.Key, opts)
+	if config.FlagDebug {
+		c.Use(api.LogRequests(os.Stderr))
+	}
+	if config.FlagDryRun {
+		c.Use(api.DryRun(os.Stdout))
+	}
+	return c
+}
+
+// proxyHeaders returns the configured extra headers plus those that
+// authenticate with Cloudflare Access or a token-checking proxy
+func proxyHeaders(cfg *config.Config) map[string]string {
+	headers := map[string]string{}
+	for name, value := range cfg.Headers {
+		headers[name] = value
+	}
+	if cfg.CFAccess != nil {
+		headers["CF-Access-Client-Id"] = cfg.CFAccess.ClientID
+		headers["CF-Access-Client-Secret"] = cfg.CFAccess.ClientSecret
+	}
+	if cfg.ProxyToken != "" {
+		name := cfg.ProxyTokenHeader
+		if name == "" {
+			name = "Proxy-Authorization"
+		}
+		headers[name] = "Bearer " + cfg.ProxyToken
+	}
+	return headers
+}
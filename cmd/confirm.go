@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/teal-bauer/specter/internal/config"
+)
+
+// confirm asks a yes/no question on stderr and reads the answer from stdin.
+// Anything other than "y" or "yes" counts as no.
+func confirm(prompt string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, fmt.Errorf("reading confirmation: %w (use --yes to skip the prompt)", err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// confirmTyped asks the user to type an exact value, for actions where a
+// reflexive "y" isn't enough
+func confirmTyped(prompt, expected string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s (%s): ", prompt, expected)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, fmt.Errorf("reading confirmation: %w (use --yes to skip the prompt)", err)
+	}
+	return strings.TrimSpace(answer) == expected, nil
+}
+
+// confirming reports whether confirmOrAbort will ask, so commands can skip
+// gathering details for the prompt
+func confirming(force bool) bool {
+	return !force && !config.FlagYes && !config.FlagDryRun
+}
+
+// confirmOrAbort asks before a destructive action unless --yes or the
+// command's own --force is given, and fails if the answer isn't yes
+func confirmOrAbort(prompt string, force bool) error {
+	if !confirming(force) {
+		return nil
+	}
+	ok, err := confirm(prompt)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("aborted")
+	}
+	return nil
+}
+
+// interactive reports whether stdin is a terminal, so the user can be
+// asked to choose. /dev/null is a character device too, but nobody types
+// into it.
+func interactive() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	null, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(info, null)
+}
+
+// readChoice reads an answer from stdin and returns the choice it stands
+// for, asking again until the answer is one of choices' keys
+func readChoice(choices map[string]string) (string, error) {
+	r := bufio.NewReader(os.Stdin)
+	for {
+		answer, err := r.ReadString('\n')
+		if choice, ok := choices[strings.ToLower(strings.TrimSpace(answer))]; ok {
+			return choice, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("reading choice: %w", err)
+		}
+		var keys []string
+		for k := range choices {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(os.Stderr, "Please answer %s: ", strings.Join(keys, ", "))
+	}
+}
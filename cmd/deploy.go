@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+var deployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Deploy a theme, routes and redirects together",
+	Long: `Deploy a theme, routes.yaml and redirects in one step, e.g. from CI.
+
+All files are checked before anything is uploaded. If a step fails, the
+routes and redirects that were already replaced are restored and the
+previously active theme stays active. An uploaded theme zip replaces the
+stored theme of the same name even if a later step fails, but is only
+activated once everything else succeeded.`,
+	Example: `  specter deploy --theme dist/theme.zip --routes routes.yaml --redirects redirects.yaml --activate`,
+	RunE:    runDeploy,
+}
+
+var (
+	deployTheme     string
+	deployRoutes    string
+	deployRedirects string
+	deployActivate  bool
+)
+
+func init() {
+	rootCmd.AddCommand(deployCmd)
+
+	deployCmd.Flags().StringVar(&deployTheme, "theme", "", "Theme zip to upload")
+	deployCmd.Flags().StringVar(&deployRoutes, "routes", "", "routes.yaml to upload")
+	deployCmd.Flags().StringVar(&deployRedirects, "redirects", "", "Redirects file to upload (.json or .yaml)")
+	deployCmd.Flags().BoolVar(&deployActivate, "activate", false, "Activate the uploaded theme")
+	addReportFlag(deployCmd)
+}
+
+// DeployStep is the outcome of one part of a deployment
+type DeployStep struct {
+	Step   string `json:"step"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func runDeploy(cmd *cobra.Command, args []string) error {
+	if deployTheme == "" && deployRoutes == "" && deployRedirects == "" {
+		return fmt.Errorf("nothing to deploy (use --theme, --routes and/or --redirects)")
+	}
+	if deployActivate && deployTheme == "" {
+		return fmt.Errorf("--activate requires --theme")
+	}
+
+	// Check everything locally before changing anything
+	var routes, redirects []byte
+	if deployTheme != "" {
+		if _, err := os.Stat(deployTheme); err != nil {
+			return err
+		}
+		if !strings.EqualFold(filepath.Ext(deployTheme), ".zip") {
+			return fmt.Errorf("theme must be a .zip file: %s", deployTheme)
+		}
+	}
+	if deployRoutes != "" {
+		var err error
+		if routes, err = readDeployFile(deployRoutes); err != nil {
+			return err
+		}
+	}
+	if deployRedirects != "" {
+		ext := strings.ToLower(filepath.Ext(deployRedirects))
+		if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+			return fmt.Errorf("redirects must be a .json or .yaml file: %s", deployRedirects)
+		}
+		var err error
+		if redirects, err = readDeployFile(deployRedirects); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	var steps []DeployStep
+	// undo restores the previous state of the step at the same index
+	undo := map[int]func() error{}
+	fail := func(step string, err error) error {
+		for i := len(steps) - 1; i >= 0; i-- {
+			if undo[i] == nil {
+				continue
+			}
+			if rerr := undo[i](); rerr != nil {
+				steps[i].Status = "rollback failed"
+				fmt.Fprintf(os.Stderr, "error: restoring %s: %v\n", steps[i].Step, rerr)
+			} else {
+				steps[i].Status = "rolled back"
+			}
+		}
+		steps = append(steps, DeployStep{Step: step, Status: "failed", Detail: err.Error()})
+		if rerr := writeReport(cmd, cfg, steps, 1); rerr != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", rerr)
+		}
+		printDeploySteps(steps)
+		return fmt.Errorf("deploy failed at %s: %w", step, err)
+	}
+
+	var themeName string
+	if deployTheme != "" {
+		themeName, err = client.UploadTheme(deployTheme)
+		if err != nil {
+			return fail("theme", err)
+		}
+		steps = append(steps, DeployStep{Step: "theme", Status: "uploaded", Detail: themeName})
+	}
+
+	if routes != nil {
+		previous, err := client.Get("/settings/routes/yaml/", nil)
+		if err != nil {
+			return fail("routes", fmt.Errorf("backing up current routes: %w", err))
+		}
+		if err := client.UploadRoutes(bytes.NewReader(routes)); err != nil {
+			return fail("routes", err)
+		}
+		undo[len(steps)] = func() error {
+			return client.UploadRoutes(bytes.NewReader(previous))
+		}
+		steps = append(steps, DeployStep{Step: "routes", Status: "updated", Detail: deployRoutes})
+	}
+
+	if redirects != nil {
+		previous, err := client.Get("/redirects/download/", nil)
+		if err != nil {
+			return fail("redirects", fmt.Errorf("backing up current redirects: %w", err))
+		}
+		if err := client.UploadRedirects(bytes.NewReader(redirects), filepath.Base(deployRedirects)); err != nil {
+			return fail("redirects", err)
+		}
+		undo[len(steps)] = func() error {
+			name := "redirects.yaml"
+			if t := bytes.TrimSpace(previous); len(t) > 0 && (t[0] == '[' || t[0] == '{') {
+				name = "redirects.json"
+			}
+			return client.UploadRedirects(bytes.NewReader(previous), name)
+		}
+		steps = append(steps, DeployStep{Step: "redirects", Status: "updated", Detail: deployRedirects})
+	}
+
+	if deployActivate {
+		if _, err := client.Put(fmt.Sprintf("/themes/%s/activate/", themeName), nil); err != nil {
+			return fail("activate", err)
+		}
+		steps = append(steps, DeployStep{Step: "activate", Status: "activated", Detail: themeName})
+	}
+
+	if err := writeReport(cmd, cfg, steps, 0); err != nil {
+		return err
+	}
+	return printDeploySteps(steps)
+}
+
+// readDeployFile reads a routes or redirects file and checks that it parses
+func readDeployFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var parsed interface{}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return data, nil
+}
+
+func printDeploySteps(steps []DeployStep) error {
+	return render(steps, []output.Column[DeployStep]{
+		{Header: "STEP", Value: func(s DeployStep) string { return s.Step }},
+		{Header: "STATUS", Value: func(s DeployStep) string { return s.Status }},
+		{Header: "DETAIL", Value: func(s DeployStep) string { return s.Detail }},
+	})
+}
@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configuration and the connection to Ghost",
+	Long: `Check everything specter needs to talk to Ghost, in order, and print a fix
+for each problem found: the config file's syntax, the profile, the site URL
+and the admin key's format, signing a token, reaching /site/, whether the
+key is accepted, the Ghost version against the API version specter requests,
+and clock skew, which makes Ghost reject otherwise valid tokens.
+
+Exits with an error if any check fails.`,
+	Example: `  specter doctor
+  specter doctor -p staging`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// DoctorCheck is the result of one diagnostic check
+type DoctorCheck struct {
+	Check string `json:"check"`
+	// Status is ok, warning, failed or skipped
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// maxClockSkew is how far the local clock may be off before Ghost rejects
+// tokens: they are valid for 5 minutes from their issue time
+const maxClockSkew = 5 * time.Minute
+
+// doctorChecks are the names of the checks in order
+var doctorChecks = []string{"Config file", "Profile", "Site URL", "Admin key", "Token", "Connection", "Authentication", "Ghost version", "Clock"}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	// Failed checks come with their own fixes
+	cmd.SilenceUsage = true
+	checks := diagnose()
+
+	// Later checks depend on earlier ones, so they are skipped once one
+	// fails
+	byName := map[string]DoctorCheck{}
+	for _, c := range checks {
+		byName[c.Check] = c
+	}
+	checks = checks[:0]
+	failed := 0
+	for _, name := range doctorChecks {
+		c, ok := byName[name]
+		if !ok {
+			c = DoctorCheck{Check: name, Status: "skipped"}
+		}
+		if c.Status == "failed" {
+			failed++
+		}
+		checks = append(checks, c)
+	}
+
+	if config.OutputFormat() == "json" {
+		if err := printJSON(checks); err != nil {
+			return err
+		}
+	} else {
+		for _, c := range checks {
+			fmt.Println(strings.TrimRight(fmt.Sprintf("%-8s %-15s %s", c.Status, c.Check, c.Detail), " "))
+			if c.Fix != "" {
+				fmt.Printf("%-8s %-15s Fix: %s\n", "", "", c.Fix)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// diagnose runs the checks in order until one fails that later ones depend
+// on
+func diagnose() []DoctorCheck {
+	var checks []DoctorCheck
+	add := func(check, status, detail, fix string) {
+		checks = append(checks, DoctorCheck{Check: check, Status: status, Detail: detail, Fix: fix})
+	}
+
+	path, err := config.CheckFile()
+	switch {
+	case err != nil:
+		// yaml lists each problem on an indented line of its own
+		add("Config file", "failed", fmt.Sprintf("%s: %s", path, strings.ReplaceAll(err.Error(), "\n  ", " ")),
+			"Fix the YAML at the line shown, or move the file away and run 'specter login'")
+	case path == "":
+		add("Config file", "warning", "no config file", "Run 'specter login' to save a profile, unless GHOST_URL and GHOST_ADMIN_KEY are set on purpose")
+	default:
+		add("Config file", "ok", path, "")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		add("Profile", "failed", err.Error(), "Run 'specter login', or pick a profile with -p (see 'specter profiles')")
+		return checks
+	}
+	profile := cfg.Name
+	if profile == "" {
+		profile = "none, from environment or flags"
+	}
+	add("Profile", "ok", profile, "")
+
+	if detail, fix := checkSiteURL(cfg.URL); fix != "" {
+		add("Site URL", "failed", detail, fix)
+		return checks
+	} else if detail != "" {
+		add("Site URL", "warning", detail, "Use https, or Ghost may redirect requests and drop the Authorization header")
+	} else {
+		add("Site URL", "ok", cfg.URL, "")
+	}
+
+	if err := checkAdminKey(cfg.Key); err != nil {
+		add("Admin key", "failed", err.Error(), "Copy the Admin API key of a custom integration from Ghost Admin, Settings > Integrations, and run 'specter login'")
+		return checks
+	}
+	add("Admin key", "ok", "key id "+strings.SplitN(cfg.Key, ":", 2)[0], "")
+
+	if _, err := api.GenerateToken(cfg.Key); err != nil {
+		add("Token", "failed", err.Error(), "Check the admin key")
+		return checks
+	}
+	add("Token", "ok", "signed, valid for 5 minutes", "")
+
+	client := newClient(cfg)
+	// The status and headers of the last response, for fixes and the clock
+	var lastStatus int
+	var lastHeader http.Header
+	client.Use(func(next api.Handler) api.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if err == nil {
+				lastStatus, lastHeader = resp.StatusCode, resp.Header
+			}
+			return resp, err
+		}
+	})
+
+	start := time.Now()
+	var site siteResponse
+	if err := client.GetJSON("/site/", nil, &site); err != nil {
+		fix := "Check the URL and your network connection; --debug shows each request"
+		if lastStatus == http.StatusNotFound {
+			fix = "Use the site's root URL, without /ghost or /ghost/api"
+		}
+		add("Connection", "failed", err.Error(), fix)
+		if lastHeader != nil {
+			checkClock(add, lastHeader, start)
+		}
+		return checks
+	}
+	title := site.Site.Title
+	if title == "" {
+		title = cfg.URL
+	}
+	add("Connection", "ok", fmt.Sprintf("%s in %s", title, time.Since(start).Round(time.Millisecond)), "")
+	// Refresh the cached version in case the site was upgraded
+	client.SetServerVersion(site.Site.Version)
+	clockStart := start
+
+	// /site/ is public, so the key is only checked by a request that needs it
+	start = time.Now()
+	params := url.Values{"limit": {"1"}, "fields": {"id"}}
+	var tags map[string]interface{}
+	if err := client.GetJSON("/tags/", params, &tags); err != nil {
+		fix := "Check that the key belongs to an integration on this site and that the integration wasn't deleted or its key regenerated, and see Clock below"
+		if lastStatus == http.StatusForbidden {
+			fix = "A proxy or WAF may be blocking the request; see the headers and user_agent profile settings"
+		}
+		add("Authentication", "failed", err.Error(), fix)
+	} else {
+		add("Authentication", "ok", "the admin key is accepted", "")
+		clockStart = start
+	}
+
+	add(checkGhostVersion(site.Site.Version))
+	checkClock(add, lastHeader, clockStart)
+	return checks
+}
+
+// checkSiteURL reports a problem with a site URL as a detail and a fix, or
+// only a detail for a warning
+func checkSiteURL(raw string) (detail, fix string) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Sprintf("%q is not an http(s) URL", raw), "Set the URL to the site's address, e.g. https://example.com"
+	}
+	if strings.Contains(u.Path, "/ghost") {
+		return raw + " includes the admin path", "Use the site's root URL, without /ghost"
+	}
+	if u.Scheme == "http" && u.Hostname() != "localhost" && u.Hostname() != "127.0.0.1" {
+		return raw + " is not https", ""
+	}
+	return "", ""
+}
+
+// checkAdminKey checks that key has the format of a Ghost Admin API key: a
+// 24 character hex id and a 64 character hex secret, separated by a colon
+func checkAdminKey(key string) error {
+	id, secret, ok := strings.Cut(key, ":")
+	if !ok {
+		if len(key) == 26 {
+			return errors.New("this looks like a Content API key, not an Admin API key")
+		}
+		return errors.New("expected id:secret")
+	}
+	if _, err := hex.DecodeString(id); err != nil || len(id) != 24 {
+		return fmt.Errorf("the id %q should be 24 hex characters", id)
+	}
+	if len(secret) != 64 {
+		return fmt.Errorf("the secret should be 64 hex characters, not %d", len(secret))
+	}
+	if _, err := hex.DecodeString(secret); err != nil {
+		return errors.New("the secret should be hex")
+	}
+	return nil
+}
+
+// checkGhostVersion checks that specter supports the site's Ghost version,
+// and reports the API version it requests
+func checkGhostVersion(version string) (check, status, detail, fix string) {
+	accept, supported := api.SupportedVersion(version)
+	major, err := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+	detail = fmt.Sprintf("Ghost %s, API %s", orDash(version), accept)
+	switch {
+	case err != nil:
+		return "Ghost version", "warning", detail, "The site didn't report a version; a proxy may be rewriting responses"
+	case major < api.MinGhostMajor:
+		return "Ghost version", "failed", detail, fmt.Sprintf("Upgrade Ghost to %d.x or later", api.MinGhostMajor)
+	case !supported:
+		return "Ghost version", "warning", detail, "Ghost is newer than the API versions specter knows; update specter if responses look wrong"
+	}
+	return "Ghost version", "ok", detail, ""
+}
+
+// checkClock compares the local clock with the Date header of a response
+// to a request sent at start
+func checkClock(add func(check, status, detail, fix string), header http.Header, start time.Time) {
+	server, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		add("Clock", "skipped", "the server sent no Date header", "")
+		return
+	}
+	// The Date header has second precision and was set while the request
+	// was in flight
+	skew := start.Add(time.Since(start) / 2).Sub(server).Round(time.Second)
+	abs := skew
+	if abs < 0 {
+		abs = -abs
+	}
+	direction := "ahead of"
+	if skew < 0 {
+		direction = "behind"
+	}
+	detail := fmt.Sprintf("%s %s the server", abs, direction)
+	fix := "Sync the system clock with NTP, e.g. 'timedatectl set-ntp true' on Linux"
+
+	switch {
+	case abs <= 2*time.Second:
+		add("Clock", "ok", "in sync with the server", "")
+	case skew < 0 && abs >= maxClockSkew:
+		add("Clock", "failed", detail+"; Ghost rejects tokens that look older than 5 minutes", fix)
+	case abs >= time.Minute:
+		add("Clock", "warning", detail, fix)
+	default:
+		add("Clock", "ok", detail, "")
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setEcho turns the terminal's echo of typed characters on or off
+func setEcho(on bool) error {
+	arg := "-echo"
+	if on {
+		arg = "echo"
+	}
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Print the profile's settings as environment exports",
+	Long: `Print shell exports for the selected profile, so that other tools in a
+pipeline (or specter in another environment) can use the same site:
+
+  GHOST_URL, GHOST_ADMIN_KEY, and if configured GHOST_CONTENT_KEY,
+  CF_ACCESS_CLIENT_ID, CF_ACCESS_CLIENT_SECRET and GHOST_PROXY_TOKEN
+
+Use --no-secrets to mask keys and tokens, e.g. when the output is logged.`,
+	Example: `  eval "$(specter -p work env)"
+  specter env --no-secrets`,
+	Args: cobra.NoArgs,
+	RunE: runEnv,
+}
+
+var envNoSecrets bool
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+
+	envCmd.Flags().BoolVar(&envNoSecrets, "no-secrets", false, "Mask keys and tokens")
+}
+
+// envVar is an environment variable and whether its value is secret
+type envVar struct {
+	name   string
+	value  string
+	secret bool
+}
+
+func runEnv(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	vars := []envVar{
+		{"GHOST_URL", cfg.URL, false},
+		{"GHOST_ADMIN_KEY", cfg.Key, true},
+	}
+	if cfg.ContentKey != "" {
+		vars = append(vars, envVar{"GHOST_CONTENT_KEY", cfg.ContentKey, true})
+	}
+	if cfg.CFAccess != nil {
+		vars = append(vars,
+			envVar{"CF_ACCESS_CLIENT_ID", cfg.CFAccess.ClientID, false},
+			envVar{"CF_ACCESS_CLIENT_SECRET", cfg.CFAccess.ClientSecret, true},
+		)
+	}
+	if cfg.ProxyToken != "" {
+		vars = append(vars, envVar{"GHOST_PROXY_TOKEN", cfg.ProxyToken, true})
+	}
+
+	if envNoSecrets {
+		for i, v := range vars {
+			if v.secret {
+				vars[i].value = maskSecret(v.name, v.value)
+			}
+		}
+	}
+
+	if config.OutputFormat() == "json" {
+		result := map[string]string{}
+		for _, v := range vars {
+			result[v.name] = v.value
+		}
+		return printJSON(result)
+	}
+
+	for _, v := range vars {
+		fmt.Printf("export %s=%s\n", v.name, shellQuote(v.value))
+	}
+	return nil
+}
+
+// maskSecret hides a secret value. The ID part of an admin key is kept so
+// that the key can still be told apart from others.
+func maskSecret(name, value string) string {
+	if name == "GHOST_ADMIN_KEY" {
+		if id, _, ok := strings.Cut(value, ":"); ok {
+			return id + ":********"
+		}
+	}
+	return "********"
+}
+
+// shellQuote quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
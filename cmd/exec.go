@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// execCommand is the --exec command line of list commands
+var execCommand string
+
+// addExecFlag registers --exec on a list command
+func addExecFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&execCommand, "exec", "", "Run this command for each result instead of printing, with {field} replaced by the result's field, e.g. './welcome.sh {email}'")
+}
+
+// execFieldRe matches a {field} placeholder, where field is a JSON field
+// name or a dotted path into nested objects
+var execFieldRe = regexp.MustCompile(`\{([A-Za-z0-9_.]+)\}`)
+
+// execEach runs --exec once for each of items, in order. Placeholders are
+// replaced within each argument after splitting the command line, and the
+// command is run without a shell, so field values can't inject commands.
+// The item's JSON is passed on stdin. Failures are reported and counted,
+// and don't stop the remaining commands.
+func execEach(items interface{}) error {
+	args, err := splitCommandLine(execCommand)
+	if err != nil {
+		return fmt.Errorf("invalid --exec: %w", err)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("invalid --exec: no command")
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	var rows []json.RawMessage
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, row := range rows {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(row, &fields); err != nil {
+			return err
+		}
+
+		argv := make([]string, len(args))
+		for i, arg := range args {
+			argv[i] = execFieldRe.ReplaceAllStringFunc(arg, func(m string) string {
+				return execField(fields, m[1:len(m)-1])
+			})
+		}
+
+		c := exec.Command(argv[0], argv[1:]...)
+		c.Stdin = bytes.NewReader(row)
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", strings.Join(argv, " "), err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d commands failed", failed, len(rows))
+	}
+	return nil
+}
+
+// execField returns the value at a dotted path in fields as a string.
+// Missing and null fields, which Ghost omits when empty, are "".
+func execField(fields map[string]interface{}, path string) string {
+	var v interface{} = fields
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		v = m[key]
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// splitCommandLine splits s into arguments at unquoted whitespace, with
+// single quotes, double quotes and backslash escapes as in a POSIX shell
+func splitCommandLine(s string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\\' && quote == 0:
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			i++
+			cur.WriteRune(runes[i])
+			inArg = true
+		case r == '\\' && i+1 < len(runes) && strings.ContainsRune(`"\`, runes[i+1]):
+			// Only \" and \\ are escapes within double quotes
+			i++
+			cur.WriteRune(runes[i])
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
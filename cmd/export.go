@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the site's content",
+}
+
+var exportStaticCmd = &cobra.Command{
+	Use:   "static <dir>",
+	Short: "Write published posts and pages as files, with their images",
+	Long: `Write every published post and page to a directory as a markdown file
+with frontmatter, as "posts edit" shows them, or with --format html as HTML
+after the frontmatter. This suits moving to a static site generator, or an
+offline archive.
+
+Posts are written to posts/<slug>.md and pages to pages/<slug>.md. The
+images they use are downloaded to assets/, as "images download" does, and
+the content points to them by relative path; links between posts and pages
+point to their files. With --no-images, images keep their URLs on the site.
+
+Running the command again rewrites the files and only downloads new images.`,
+	Example: `  specter export static archive
+  specter export static site/content --format html --workers 8`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExportStatic,
+}
+
+var (
+	exportFormat   string
+	exportNoImages bool
+	exportWorkers  int
+)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportStaticCmd)
+	exportStaticCmd.Flags().StringVar(&exportFormat, "format", "markdown", "Format of the content: markdown or html")
+	exportStaticCmd.Flags().BoolVar(&exportNoImages, "no-images", false, "Keep images on the site instead of downloading them")
+	exportStaticCmd.Flags().IntVar(&exportWorkers, "workers", 4, "Concurrent image downloads")
+	addReportFlag(exportStaticCmd)
+}
+
+// ExportedItem is a post or page written by export static
+type ExportedItem struct {
+	Type   string `json:"type"`
+	Slug   string `json:"slug"`
+	File   string `json:"file"`
+	Images int    `json:"images"`
+}
+
+// exportAssetsDir is the directory under the export that images are
+// downloaded to
+const exportAssetsDir = "assets"
+
+func runExportStatic(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+	var ext string
+	switch exportFormat {
+	case "markdown":
+		ext = ".md"
+	case "html":
+		ext = ".html"
+	default:
+		return fmt.Errorf("unknown format %q: use markdown or html", exportFormat)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	siteURL, err := portalSiteURL(client, cfg)
+	if err != nil {
+		return err
+	}
+	images, err := newSiteImages(cfg.URL, siteURL)
+	if err != nil {
+		return err
+	}
+
+	e := &staticExport{images: images, files: map[string]string{}, downloads: map[string]bool{}}
+	var items []ExportedItem
+	var posts []Post
+	params := url.Values{"filter": {"status:published"}, "include": {"tags"}, "formats": {"html"}}
+	for _, resource := range []string{"posts", "pages"} {
+		for page, err := range client.Paginate("/"+resource+"/", params) {
+			if err != nil {
+				return err
+			}
+			var list map[string]json.RawMessage
+			var found []Post
+			if err := json.Unmarshal(page, &list); err != nil {
+				return err
+			}
+			if err := json.Unmarshal(list[resource], &found); err != nil {
+				return err
+			}
+			for _, p := range found {
+				file := resource + "/" + p.Slug + ext
+				e.files[sitemapPath(p.URL)] = file
+				items = append(items, ExportedItem{Type: resource[:len(resource)-1], Slug: p.Slug, File: file})
+				posts = append(posts, p)
+			}
+		}
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("the site has nothing published")
+	}
+
+	for i := range items {
+		p := posts[i]
+		items[i].Images = e.rewrite(&p)
+		var data []byte
+		if ext == ".html" {
+			data, err = postDocument(&p, p.HTML+"\n", false)
+		} else {
+			data, err = postMarkdown(&p)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", items[i].File, err)
+		}
+		path := filepath.Join(dir, filepath.FromSlash(items[i].File))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	if len(e.downloads) > 0 {
+		urls := make([]string, 0, len(e.downloads))
+		for u := range e.downloads {
+			urls = append(urls, u)
+		}
+		sort.Strings(urls)
+		results, err := downloadImages(filepath.Join(dir, exportAssetsDir), urls, exportWorkers)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			if r.Status == "failed" {
+				failed++
+				fmt.Fprintf(os.Stderr, "Warning: downloading %s: %s\n", r.URL, r.Error)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Downloaded %s to %s\n", plural(len(urls)-failed, "image"), filepath.Join(dir, exportAssetsDir))
+	}
+
+	if err := writeReport(cmd, cfg, items, 0); err != nil {
+		return err
+	}
+	err = render(items, []output.Column[ExportedItem]{
+		{Header: "TYPE", Value: func(r ExportedItem) string { return r.Type }},
+		{Header: "SLUG", Value: func(r ExportedItem) string { return r.Slug }},
+		{Header: "FILE", Value: func(r ExportedItem) string { return r.File }},
+		{Header: "IMAGES", Value: func(r ExportedItem) string { return fmt.Sprint(r.Images) }},
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d images could not be downloaded; run the command again to retry them", failed, len(e.downloads))
+	}
+	return nil
+}
+
+// staticExport rewrites the URLs in exported content to point into the
+// export
+type staticExport struct {
+	images *siteImages
+	// files maps the paths of exported posts and pages to their files
+	files map[string]string
+	// downloads are the images to download
+	downloads map[string]bool
+}
+
+// hrefRe matches a link's target in HTML
+var hrefRe = regexp.MustCompile(`href="([^"]*)"`)
+
+// rewrite points a post's images and links to other exported content at
+// their files, relative to the post's own, and returns how many images it
+// uses
+func (e *staticExport) rewrite(p *Post) int {
+	used := map[string]bool{}
+	image := func(s string) string {
+		if exportNoImages {
+			return s
+		}
+		return contentImageRe.ReplaceAllStringFunc(s, func(m string) string {
+			u, ok := e.images.url(m)
+			if !ok {
+				return m
+			}
+			e.downloads[u] = true
+			used[u] = true
+			return "../" + exportAssetsDir + "/" + imageLocalPath(u)
+		})
+	}
+
+	p.HTML = hrefRe.ReplaceAllStringFunc(p.HTML, func(m string) string {
+		href := hrefRe.FindStringSubmatch(m)[1]
+		u, err := e.images.base.Parse(html.UnescapeString(href))
+		if err != nil || !e.images.hosts[u.Host] {
+			return m
+		}
+		file, ok := e.files[sitemapPath(u.String())]
+		if !ok {
+			return m
+		}
+		if u.Fragment != "" {
+			file += "#" + u.Fragment
+		}
+		return `href="../` + file + `"`
+	})
+	p.HTML = image(p.HTML)
+	p.FeatureImg = image(p.FeatureImg)
+	p.OGImage = image(p.OGImage)
+	p.TwitterImage = image(p.TwitterImage)
+	return len(used)
+}
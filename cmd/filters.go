@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+)
+
+// joinFilters combines NQL filters so that all must match, skipping empty
+// ones
+func joinFilters(filters ...string) string {
+	var parts []string
+	for _, f := range filters {
+		if f != "" {
+			parts = append(parts, f)
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	for i, p := range parts {
+		parts[i] = "(" + p + ")"
+	}
+	return strings.Join(parts, "+")
+}
+
+// authorFilter returns the NQL filter for content by the given author slug
+func authorFilter(slug string) string {
+	if slug == "" {
+		return ""
+	}
+	return "authors:" + slug
+}
+
+// nqlTimeFormat is the timestamp format Ghost's NQL filters accept
+const nqlTimeFormat = "2006-01-02 15:04:05"
+
+// dateRangeFilter returns the NQL filter restricting field to the range
+// given by since and until, either of which may be empty. See parseTimeArg
+// for the accepted formats; a date-only until includes that whole day.
+func dateRangeFilter(field, since, until string) (string, error) {
+	now := time.Now()
+	var filters []string
+	if since != "" {
+		t, _, err := parseTimeArg(since, now)
+		if err != nil {
+			return "", fmt.Errorf("invalid --since: %w", err)
+		}
+		filters = append(filters, fmt.Sprintf("%s:>='%s'", field, t.UTC().Format(nqlTimeFormat)))
+	}
+	if until != "" {
+		t, dateOnly, err := parseTimeArg(until, now)
+		if err != nil {
+			return "", fmt.Errorf("invalid --until: %w", err)
+		}
+		op := "<="
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+			op = "<"
+		}
+		filters = append(filters, fmt.Sprintf("%s:%s'%s'", field, op, t.UTC().Format(nqlTimeFormat)))
+	}
+	return strings.Join(filters, "+"), nil
+}
+
+// parseTimeArg parses a date (2006-01-02), an RFC 3339 timestamp, or a
+// duration before now such as 30d, 2w or 12h. It reports whether s was a
+// date without a time.
+func parseTimeArg(s string, now time.Time) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+
+	if n, err := strconv.Atoi(s[:max(len(s)-1, 0)]); err == nil {
+		switch s[len(s)-1] {
+		case 'd':
+			return now.AddDate(0, 0, -n), false, nil
+		case 'w':
+			return now.AddDate(0, 0, -7*n), false, nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), false, nil
+	}
+
+	return time.Time{}, false, fmt.Errorf("%q is not a date (2006-01-02), timestamp (RFC 3339) or duration (e.g. 30d, 2w, 12h)", s)
+}
+
+// listQuery holds the query flags shared by list commands, which are passed
+// through to Ghost's browse endpoints
+type listQuery struct {
+	filter  string
+	order   string
+	include string
+	fields  string
+}
+
+// addFlags registers --filter, --order, --include and --fields on cmd
+func (q *listQuery) addFlags(cmd *cobra.Command, example string) {
+	cmd.Flags().StringVar(&q.filter, "filter", "", fmt.Sprintf("NQL filter (e.g., '%s')", example))
+	cmd.Flags().StringVar(&q.order, "order", "", "Sort order (e.g., 'created_at desc')")
+	cmd.Flags().StringVar(&q.include, "include", "", "Related data to include (comma-separated)")
+	cmd.Flags().StringVar(&q.fields, "fields", "", "Only return these fields (comma-separated)")
+}
+
+// options returns the list options for the flags. filter is combined with
+// --filter, and include with --include.
+func (q *listQuery) options(filter string, include ...string) *api.ListOptions {
+	opts := &api.ListOptions{Filter: joinFilters(q.filter, filter), Order: q.order}
+	if q.include != "" {
+		include = append(strings.Split(q.include, ","), include...)
+	}
+	opts.Include = include
+	if q.fields != "" {
+		opts.Fields = strings.Split(q.fields, ",")
+	}
+	return opts
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+)
+
+var freezeCmd = &cobra.Command{
+	Use:   "freeze [on|off]",
+	Short: "Freeze or unfreeze content changes for a profile",
+	Long: `Freeze or unfreeze content changes for a profile, e.g. during a migration
+or a big launch. While a profile is frozen, every command that changes the
+site fails unless --override-freeze is given.
+
+Without an argument, shows whether the profile is frozen.`,
+	Example: `  specter -p work freeze on
+  specter -p work posts update launch launch.md --override-freeze
+  specter -p work freeze off`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"on", "off"},
+	RunE:      runFreeze,
+}
+
+func init() {
+	rootCmd.AddCommand(freezeCmd)
+}
+
+func runFreeze(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if cfg.Name == "" {
+		return fmt.Errorf("freezing requires a profile (use 'specter login' to create one)")
+	}
+
+	frozen := cfg.Frozen
+	if len(args) == 1 {
+		switch args[0] {
+		case "on":
+			frozen = true
+		case "off":
+			frozen = false
+		default:
+			return fmt.Errorf("invalid argument %q (expected on or off)", args[0])
+		}
+		if err := config.SetFrozen(cfg.Name, frozen); err != nil {
+			return err
+		}
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(map[string]interface{}{
+			"profile": cfg.Name,
+			"frozen":  frozen,
+		})
+	}
+
+	if frozen {
+		fmt.Printf("Profile '%s' is frozen\n", cfg.Name)
+	} else {
+		fmt.Printf("Profile '%s' is not frozen\n", cfg.Name)
+	}
+	return nil
+}
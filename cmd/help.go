@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/content"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+// helpCmd replaces cobra's help command to add reference topics next to
+// the help for commands
+var helpCmd = &cobra.Command{
+	Use:   "help [command | topic]",
+	Short: "Help about any command, filters, frontmatter or destinations",
+	Long: `Help provides help for any command, e.g. specter help posts list.
+
+Reference topics:
+  filters      NQL syntax for --filter, with the fields and values to filter on
+  frontmatter  The frontmatter keys of markdown files and their values
+  destinations Where exports, manifests and reports can be written`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		target, _, err := cmd.Root().Find(args)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var completions []cobra.Completion
+		for _, sub := range target.Commands() {
+			if sub.IsAvailableCommand() && strings.HasPrefix(sub.Name(), toComplete) {
+				completions = append(completions, cobra.CompletionWithDesc(sub.Name(), sub.Short))
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: runHelp,
+}
+
+var helpFiltersCmd = &cobra.Command{
+	Use:   "filters",
+	Short: "NQL syntax for --filter",
+	Long: `Describe the NQL syntax that --filter accepts on list commands, and the
+fields and values of each resource to filter on.`,
+	Args: cobra.NoArgs,
+	RunE: runHelpFilters,
+}
+
+var helpFrontmatterCmd = &cobra.Command{
+	Use:   "frontmatter",
+	Short: "Frontmatter keys of markdown files",
+	Long: `List the frontmatter keys that posts and pages accept, with their types and
+allowed values. -o json prints them as data; "specter schema frontmatter"
+prints a JSON Schema for editors.`,
+	Args: cobra.NoArgs,
+	RunE: runHelpFrontmatter,
+}
+
+var helpDestinationsCmd = &cobra.Command{
+	Use:   "destinations",
+	Short: "Where exports, manifests and reports can be written",
+	Long: `Files that commands export, such as "nav export", "posts calendar --ical",
+"images upload --manifest" and --report, can be written to:
+
+  path/to/file           a local file, replaced only once it is complete
+  file:///path/to/file   the same, as a URL
+  -                      standard output
+  s3://bucket/key        an object in S3 or an S3-compatible store
+  gs://bucket/key        an object in Google Cloud Storage
+
+Objects are streamed: large ones are uploaded in parts as they are written,
+and nothing is left in the bucket if the export fails.
+
+s3:// uses AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN,
+in AWS_REGION (default us-east-1). Set AWS_ENDPOINT_URL for other stores,
+e.g. https://<account>.r2.cloudflarestorage.com or a MinIO server.
+
+gs:// uses Cloud Storage's interoperability API with an HMAC key, in
+GS_ACCESS_KEY_ID and GS_SECRET_ACCESS_KEY.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprintln(cmd.OutOrStdout(), cmd.Long)
+	},
+}
+
+func init() {
+	rootCmd.SetHelpCommand(helpCmd)
+	helpCmd.AddCommand(helpFiltersCmd)
+	helpCmd.AddCommand(helpFrontmatterCmd)
+	helpCmd.AddCommand(helpDestinationsCmd)
+}
+
+func runHelp(cmd *cobra.Command, args []string) error {
+	target, _, err := cmd.Root().Find(args)
+	if err != nil || target == nil {
+		return fmt.Errorf("unknown help topic %q, see specter help", strings.Join(args, " "))
+	}
+	target.InitDefaultHelpFlag()
+	return target.Help()
+}
+
+// nqlSyntax is the part of the filter reference that doesn't come from the
+// models: operators, with an example of each
+var nqlSyntax = [][3]string{
+	{"field:value", "equals", "status:published"},
+	{"field:-value", "doesn't equal", "status:-draft"},
+	{"field:>value", "greater than (also >=, <, <=)", "published_at:>'2026-01-01'"},
+	{"field:[a,b]", "any of", "tag:[news,events]"},
+	{"field:-[a,b]", "none of", "tag:-[draft-notes]"},
+	{"field:~'text'", "contains (~^ starts, ~$ ends with)", "title:~'weekly'"},
+	{"field:null", "is empty", "feature_image:null"},
+	{"a+b", "and", "status:published+featured:true"},
+	{"a,b", "or", "tag:news,tag:events"},
+	{"(a,b)+c", "grouping", "(tag:news,tag:events)+featured:true"},
+}
+
+// filterResources are the resources whose fields the filter reference
+// lists, with their models
+var filterResources = []struct {
+	name  string
+	model interface{}
+}{
+	{"posts", Post{}},
+	{"pages", Page{}},
+	{"tags", Tag{}},
+	{"members", Member{}},
+	{"newsletters", Newsletter{}},
+	{"tiers", Tier{}},
+	{"users", User{}},
+}
+
+// memberStatuses are the values of a member's status
+var memberStatuses = []string{"free", "paid", "comped"}
+
+func runHelpFilters(cmd *cobra.Command, args []string) error {
+	w := cmd.OutOrStdout()
+
+	fmt.Fprintf(w, "Filters (--filter) use Ghost's NQL syntax, for Admin API %s.\n\n", api.APIVersion)
+	fmt.Fprintln(w, "Syntax")
+	for _, row := range nqlSyntax {
+		fmt.Fprintf(w, "  %-15s %-36s %s\n", row[0], row[1], row[2])
+	}
+	fmt.Fprint(w, `
+  Quote values that contain spaces or punctuation in single quotes. Dates
+  are 'YYYY-MM-DD HH:MM:SS' in UTC. Flags such as --since, --author and
+  --drafts are combined with --filter, so all of them must match.
+`)
+
+	fmt.Fprintln(w, "\nFields")
+	for _, r := range filterResources {
+		fields, relations := modelFields(reflect.TypeOf(r.model))
+		writeWrapped(w, fmt.Sprintf("  %-12s", r.name), fields)
+		if len(relations) > 0 {
+			writeWrapped(w, fmt.Sprintf("  %-12s", ""), []string{"by slug: " + strings.Join(relations, ", ")})
+		}
+	}
+	fmt.Fprintln(w, "\n  Relations match by slug: tag:news (or tags:news), authors:jane, label:vip.")
+
+	fmt.Fprintln(w, "\nValues")
+	for _, key := range content.FrontmatterKeys() {
+		if key.Name == "status" || key.Name == "visibility" {
+			fmt.Fprintf(w, "  %-12s %s: %s\n", "posts, pages", key.Name, strings.Join(key.Values, ", "))
+		}
+	}
+	fmt.Fprintf(w, "  %-12s status: %s\n", "members", strings.Join(memberStatuses, ", "))
+	return nil
+}
+
+// modelFields returns the JSON field names of a model, with relations (lists
+// of other resources) separately
+func modelFields(t reflect.Type) (fields, relations []string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.Struct {
+			relations = append(relations, name)
+			continue
+		}
+		fields = append(fields, name)
+	}
+	return fields, relations
+}
+
+// writeWrapped writes words after prefix, separated by commas and wrapped
+// at 80 columns with continuation lines indented to match
+func writeWrapped(w io.Writer, prefix string, words []string) {
+	line := prefix
+	for i, word := range words {
+		if i < len(words)-1 {
+			word += ","
+		}
+		if len(line)+1+len(word) > 80 && len(line) > len(prefix) {
+			fmt.Fprintln(w, line)
+			line = strings.Repeat(" ", len(prefix))
+		}
+		line += " " + word
+	}
+	fmt.Fprintln(w, line)
+}
+
+func runHelpFrontmatter(cmd *cobra.Command, args []string) error {
+	return render(content.FrontmatterKeys(), []output.Column[content.FrontmatterKey]{
+		{Header: "KEY", Value: func(k content.FrontmatterKey) string { return k.Name }},
+		{Header: "TYPE", Value: func(k content.FrontmatterKey) string { return k.Type }},
+		{Header: "VALUES", Value: func(k content.FrontmatterKey) string { return orDash(strings.Join(k.Values, ", ")) }},
+		{Header: "DESCRIPTION", Value: func(k content.FrontmatterKey) string { return k.Description }},
+	})
+}
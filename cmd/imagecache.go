@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/content"
+)
+
+// imageCache remembers the URLs of uploaded images by site and file
+// content, so re-running a command doesn't upload unchanged files again
+type imageCache struct {
+	path    string
+	entries map[string]string
+	dirty   bool
+}
+
+func loadImageCache() *imageCache {
+	c := &imageCache{entries: map[string]string{}}
+	if dir := config.CacheDir(); dir != "" {
+		c.path = filepath.Join(dir, "images.json")
+		if data, err := os.ReadFile(c.path); err == nil {
+			_ = json.Unmarshal(data, &c.entries)
+		}
+	}
+	return c
+}
+
+func (c *imageCache) save() error {
+	if !c.dirty || c.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0600)
+}
+
+// localImageUploader returns a resolver that uploads images referenced by
+// local path, relative to baseDir, and leaves URLs alone. With a nil client,
+// nothing is uploaded and only images uploaded before are resolved.
+func localImageUploader(cfg *config.Config, client *api.Client, baseDir string, cache *imageCache) content.ImageResolver {
+	return func(dest string) (string, error) {
+		u, err := url.Parse(dest)
+		if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+			return "", nil
+		}
+
+		path := u.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			// Site-relative URLs like /content/images/... aren't files
+			if filepath.IsAbs(u.Path) {
+				return "", nil
+			}
+			return "", err
+		}
+
+		sum, err := fileHash(path)
+		if err != nil {
+			return "", err
+		}
+		key := cfg.URL + " " + sum
+		if cached, ok := cache.entries[key]; ok {
+			return cached, nil
+		}
+		if client == nil {
+			return "", nil
+		}
+
+		uploaded, err := client.UploadImage(path, "")
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(os.Stderr, "Uploaded %s\n", dest)
+		cache.entries[key] = uploaded
+		cache.dirty = true
+		return uploaded, nil
+	}
+}
+
+// fileHash returns the hex SHA-256 of a file's content
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// remoteImageUploader returns a resolver that downloads images from
+// another site, e.g. one being migrated, and uploads them. Images are
+// remembered by URL. With a nil client, nothing is downloaded and only
+// images uploaded before are resolved. An image that can't be downloaded
+// keeps its URL, with a warning.
+func remoteImageUploader(cfg *config.Config, client *api.Client, cache *imageCache) content.ImageResolver {
+	return func(src string) (string, error) {
+		key := cfg.URL + " " + src
+		if cached, ok := cache.entries[key]; ok {
+			return cached, nil
+		}
+		if client == nil {
+			return "", nil
+		}
+
+		dir, err := os.MkdirTemp("", "specter-image-")
+		if err != nil {
+			return "", err
+		}
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "image")
+		if u, err := url.Parse(src); err == nil {
+			if name := filepath.Base(u.Path); name != "." && name != "/" {
+				path = filepath.Join(dir, name)
+			}
+		}
+		if err := downloadFile(src, path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: can't download %s, keeping its URL: %v\n", src, err)
+			return "", nil
+		}
+
+		uploaded, err := client.UploadImage(path, "")
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(os.Stderr, "Uploaded %s\n", src)
+		cache.entries[key] = uploaded
+		cache.dirty = true
+		return uploaded, nil
+	}
+}
+
+// downloadFile saves the content at a URL to path
+func downloadFile(src, path string) error {
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Get(src)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s", resp.Status)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -1,13 +1,24 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/spf13/cobra"
 	"github.com/teal-bauer/specter/api"
 	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/dest"
+	"github.com/teal-bauer/specter/internal/output"
 )
 
 var imagesCmd = &cobra.Command{
@@ -16,40 +27,228 @@ var imagesCmd = &cobra.Command{
 }
 
 var imagesUploadCmd = &cobra.Command{
-	Use:   "upload <file>",
-	Short: "Upload an image",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runImagesUpload,
+	Use:   "upload <file-or-dir>...",
+	Short: "Upload images",
+	Long: `Upload one or more images. Directories are searched for image files
+(jpg, jpeg, png, gif, webp, svg, ico); use --recursive to include
+subdirectories.
+
+With --manifest, a mapping from local paths to Ghost URLs is written as
+JSON, or as CSV if the file name ends in .csv.`,
+	Example: `  specter images upload cover.jpg
+  specter images upload ./assets/ --recursive --manifest images.json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runImagesUpload,
 }
 
-var imageRef string
+var (
+	imageRef       string
+	imageRecursive bool
+	imageWorkers   int
+	imageManifest  string
+)
 
 func init() {
 	rootCmd.AddCommand(imagesCmd)
 	imagesCmd.AddCommand(imagesUploadCmd)
 
-	imagesUploadCmd.Flags().StringVar(&imageRef, "ref", "", "Reference name for the image")
+	imagesUploadCmd.Flags().StringVar(&imageRef, "ref", "", "Reference name for the image (single file only)")
+	imagesUploadCmd.Flags().BoolVarP(&imageRecursive, "recursive", "r", false, "Include images in subdirectories")
+	imagesUploadCmd.Flags().IntVar(&imageWorkers, "workers", 4, "Concurrent uploads")
+	imagesUploadCmd.Flags().StringVar(&imageManifest, "manifest", "", "Write a path-to-URL manifest to this file (.json or .csv) or s3:// or gs:// URL")
+	addReportFlag(imagesUploadCmd)
+}
+
+// imageExtensions are the file types Ghost accepts as images
+var imageExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true,
+	".webp": true, ".svg": true, ".ico": true,
+}
+
+// ImageUpload maps a local file to its uploaded URL
+type ImageUpload struct {
+	Path  string `json:"path"`
+	URL   string `json:"url,omitempty"`
+	Error string `json:"error,omitempty"`
 }
 
 func runImagesUpload(cmd *cobra.Command, args []string) error {
+	paths, err := collectImages(args, imageRecursive)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no images found")
+	}
+	if imageRef != "" && len(paths) > 1 {
+		return fmt.Errorf("--ref can only be used when uploading a single image")
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
+
+	// A single image keeps the simple output
+	if len(paths) == 1 && imageManifest == "" && reportPath == "" {
+		url, err := client.UploadImage(paths[0], imageRef)
+		if err != nil {
+			return err
+		}
+
+		if config.OutputFormat() == "json" {
+			return printJSON(map[string]string{
+				"url": url,
+				"ref": imageRef,
+			})
+		}
+
+		fmt.Println(url)
+		return nil
+	}
 
-	url, err := client.UploadImage(args[0], imageRef)
+	uploads, err := uploadImages(client, paths, imageWorkers)
 	if err != nil {
 		return err
 	}
 
-	if config.OutputFormat() == "json" {
-		return json.NewEncoder(os.Stdout).Encode(map[string]string{
-			"url": url,
-			"ref": imageRef,
+	failed := 0
+	for _, u := range uploads {
+		if u.Error != "" {
+			failed++
+		}
+	}
+
+	if imageManifest != "" {
+		if err := writeImageManifest(imageManifest, uploads); err != nil {
+			return err
+		}
+	}
+	if err := writeReport(cmd, cfg, uploads, failed); err != nil {
+		return err
+	}
+
+	err = render(uploads, []output.Column[ImageUpload]{
+		{Header: "PATH", Value: func(u ImageUpload) string { return u.Path }},
+		{Header: "URL", Value: func(u ImageUpload) string {
+			if u.Error != "" {
+				return "error: " + u.Error
+			}
+			return u.URL
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d images failed to upload", failed, len(uploads))
+	}
+	return nil
+}
+
+// collectImages expands directories in args into the image files they
+// contain, sorted by path
+func collectImages(args []string, recursive bool) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != arg && !recursive {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if imageExtensions[strings.ToLower(filepath.Ext(path))] {
+				paths = append(paths, path)
+			}
+			return nil
 		})
+		if err != nil {
+			return nil, err
+		}
 	}
+	sort.Strings(paths)
+	return paths, nil
+}
 
-	fmt.Println(url)
+// uploadImages uploads paths using a pool of workers. Results are in the
+// same order as paths. A dry run stops at the first upload, with
+// api.ErrDryRun.
+func uploadImages(client *api.Client, paths []string, workers int) ([]ImageUpload, error) {
+	uploads := make([]ImageUpload, len(paths))
+	jobs := make(chan int)
+	var (
+		wg     sync.WaitGroup
+		dryRun atomic.Bool
+	)
+	for i := 0; i < max(workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if dryRun.Load() {
+					continue
+				}
+				uploads[j].Path = paths[j]
+				url, err := client.UploadImage(paths[j], "")
+				if errors.Is(err, api.ErrDryRun) {
+					dryRun.Store(true)
+					continue
+				}
+				if err != nil {
+					uploads[j].Error = err.Error()
+					continue
+				}
+				uploads[j].URL = url
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if dryRun.Load() {
+		return nil, api.ErrDryRun
+	}
+	return uploads, nil
+}
+
+// writeImageManifest writes uploads as CSV if path ends in .csv, and as
+// JSON otherwise
+func writeImageManifest(path string, uploads []ImageUpload) error {
+	err := dest.WriteTo(context.Background(), path, func(f io.Writer) error {
+		if !strings.EqualFold(filepath.Ext(path), ".csv") {
+			return output.JSON(f, uploads)
+		}
+		w := csv.NewWriter(f)
+		if err := w.Write([]string{"path", "url", "error"}); err != nil {
+			return err
+		}
+		for _, u := range uploads {
+			if err := w.Write([]string{u.Path, u.URL, u.Error}); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	})
+	if err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
 	return nil
 }
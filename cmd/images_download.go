@@ -0,0 +1,402 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var imagesDownloadCmd = &cobra.Command{
+	Use:   "download <dir>",
+	Short: "Download the site's images, e.g. for a backup",
+	Long: `Download the images that posts, pages, tags, staff users and the site's
+settings use into a directory, laid out as in Ghost's content/images, e.g.
+2024/05/cover.jpg. Resized copies are fetched as the original.
+
+Downloads run in parallel (--workers). Running the command again only
+downloads what is new: files already in the directory are skipped if they
+match the checksum recorded when they were downloaded, in
+.specter-images.json in the directory, and downloads that were interrupted
+continue where they stopped.`,
+	Example: `  specter images download backup/images
+  specter images download backup/images --workers 16 --report images.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImagesDownload,
+}
+
+var imagesDownloadWorkers int
+
+func init() {
+	imagesCmd.AddCommand(imagesDownloadCmd)
+	imagesDownloadCmd.Flags().IntVar(&imagesDownloadWorkers, "workers", 4, "Concurrent downloads")
+	addReportFlag(imagesDownloadCmd)
+}
+
+// imageDownloadManifest is the file in the download directory that records
+// the checksums of downloaded images
+const imageDownloadManifest = ".specter-images.json"
+
+// ImageDownload is the outcome of downloading one image
+type ImageDownload struct {
+	URL    string `json:"url"`
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Size   int64  `json:"size,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// downloadedImage is what the manifest records about a downloaded image
+type downloadedImage struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+func runImagesDownload(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	siteURL, err := portalSiteURL(client, cfg)
+	if err != nil {
+		return err
+	}
+	urls, err := siteImageURLs(client, cfg.URL, siteURL)
+	if err != nil {
+		return err
+	}
+	if len(urls) == 0 {
+		return fmt.Errorf("the site has no images")
+	}
+
+	results, err := downloadImages(dir, urls, imagesDownloadWorkers)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Status == "failed" {
+			failed++
+		}
+	}
+	if err := writeReport(cmd, cfg, results, failed); err != nil {
+		return err
+	}
+	err = render(results, []output.Column[ImageDownload]{
+		{Header: "PATH", Value: func(r ImageDownload) string { return r.Path }},
+		{Header: "URL", Value: func(r ImageDownload) string { return r.URL }, Wide: true},
+		{Header: "STATUS", Value: func(r ImageDownload) string { return r.Status }},
+		{Header: "SIZE", Value: func(r ImageDownload) string { return formatBytes(r.Size) }},
+		{Header: "ERROR", Value: func(r ImageDownload) string { return orDash(r.Error) }},
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d images could not be downloaded", failed, len(results))
+	}
+	return nil
+}
+
+// downloadImages downloads images into dir in parallel, keeping the
+// manifest of their checksums in dir up to date so that the next run only
+// downloads what is new
+func downloadImages(dir string, urls []string, workers int) ([]ImageDownload, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	manifest := map[string]downloadedImage{}
+	manifestPath := filepath.Join(dir, imageDownloadManifest)
+	if data, err := os.ReadFile(manifestPath); err == nil {
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", manifestPath, err)
+		}
+	}
+
+	var mu sync.Mutex
+	saveManifest := func() error {
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(manifestPath, append(data, '\n'), 0644)
+	}
+
+	// URLs saved to the same path, e.g. one image linked through both the
+	// admin and the site URL, would be downloaded to it at the same time
+	seen := map[string]bool{}
+	var unique []string
+	for _, u := range urls {
+		if p := imageLocalPath(u); !seen[p] {
+			seen[p] = true
+			unique = append(unique, u)
+		}
+	}
+	urls = unique
+
+	results := make([]ImageDownload, len(urls))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	httpClient := &http.Client{Timeout: 10 * time.Minute}
+	done := 0
+	for i := 0; i < max(workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				r := &results[j]
+				r.URL = urls[j]
+				r.Path = imageLocalPath(urls[j])
+				target := filepath.Join(dir, filepath.FromSlash(r.Path))
+
+				mu.Lock()
+				known, ok := manifest[r.Path]
+				mu.Unlock()
+				entry, status, err := downloadImage(httpClient, r.URL, target, known, ok)
+				r.Status, r.Size = status, entry.Size
+				if err != nil {
+					r.Status, r.Error = "failed", err.Error()
+					continue
+				}
+
+				// Saved as it goes, so that an interrupted run isn't lost
+				mu.Lock()
+				manifest[r.Path] = entry
+				if done++; done%50 == 0 {
+					if err := saveManifest(); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: saving %s: %v\n", manifestPath, err)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := saveManifest(); err != nil {
+		return nil, fmt.Errorf("saving %s: %w", manifestPath, err)
+	}
+	return results, nil
+}
+
+// contentImageRe matches the URL of an uploaded image in HTML or JSON
+var contentImageRe = regexp.MustCompile(`(?:https?://[^\s"'()<>\\,]+)?/content/images/[^\s"'()<>\\,?#]+`)
+
+// imageSizeRe matches the part of a resized image's path that names its
+// size and format
+var imageSizeRe = regexp.MustCompile(`/content/images/size/[^/]+/(?:format/[^/]+/)?`)
+
+// siteImages recognizes the images uploaded to a site in its content
+type siteImages struct {
+	base  *url.URL
+	hosts map[string]bool
+}
+
+// newSiteImages returns a siteImages for the site at the given URLs, the
+// first of which relative paths are resolved against
+func newSiteImages(urls ...string) (*siteImages, error) {
+	base, err := url.Parse(strings.TrimSuffix(urls[0], "/") + "/")
+	if err != nil {
+		return nil, err
+	}
+	s := &siteImages{base: base, hosts: map[string]bool{}}
+	for _, u := range urls {
+		if parsed, err := url.Parse(u); err == nil {
+			s.hosts[parsed.Host] = true
+		}
+	}
+	return s, nil
+}
+
+// url returns the URL of the original image for a match of contentImageRe,
+// if it is on the site
+func (s *siteImages) url(match string) (string, bool) {
+	u, err := s.base.Parse(imageSizeRe.ReplaceAllString(match, "/content/images/"))
+	if err != nil || !s.hosts[u.Host] {
+		return "", false
+	}
+	return u.String(), true
+}
+
+// siteImageURLs returns the URLs of the images uploaded to the site that
+// its content and settings use, sorted. Resized copies are given as the
+// original.
+func siteImageURLs(client *api.Client, urls ...string) ([]string, error) {
+	images, err := newSiteImages(urls...)
+	if err != nil {
+		return nil, err
+	}
+	found := map[string]bool{}
+	scan := func(data []byte) {
+		for _, m := range contentImageRe.FindAllString(string(data), -1) {
+			if u, ok := images.url(m); ok {
+				found[u] = true
+			}
+		}
+	}
+
+	for _, resource := range []string{"posts", "pages", "tags", "users"} {
+		params := url.Values{}
+		if resource == "posts" || resource == "pages" {
+			params.Set("formats", "html")
+		}
+		for page, err := range client.Paginate("/"+resource+"/", params) {
+			if err != nil {
+				return nil, err
+			}
+			scan(page)
+		}
+	}
+	settings, err := client.Get("/settings/", nil)
+	if err != nil {
+		return nil, err
+	}
+	scan(settings)
+
+	list := make([]string, 0, len(found))
+	for u := range found {
+		list = append(list, u)
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+// imageLocalPath returns where an image is saved, relative to the download
+// directory: its path under content/images
+func imageLocalPath(imageURL string) string {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return path.Base(imageURL)
+	}
+	_, rel, _ := strings.Cut(u.Path, "/content/images/")
+	// Clean as an absolute path, so that ".." can't leave the directory
+	return strings.TrimPrefix(path.Clean("/"+rel), "/")
+}
+
+// downloadImage downloads an image to target, unless a file is there that
+// matches its manifest entry, known. Partial downloads are kept next to
+// target and continued by the next attempt. It returns the image's new
+// entry and what was done: downloaded, resumed or unchanged.
+func downloadImage(client *http.Client, imageURL, target string, known downloadedImage, isKnown bool) (downloadedImage, string, error) {
+	entry := downloadedImage{URL: imageURL}
+	if _, err := os.Stat(target); err == nil {
+		sum, size, err := fileSHA256(target)
+		if err != nil {
+			return entry, "", err
+		}
+		// Files only get their final name once complete, so a file the
+		// manifest doesn't know yet is one it wasn't saved for
+		if !isKnown || known.SHA256 == sum {
+			entry.SHA256, entry.Size = sum, size
+			return entry, "unchanged", nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return entry, "", err
+	}
+	partial := target + ".part"
+	var offset int64
+	if info, err := os.Stat(partial); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequest("GET", imageURL, nil)
+	if err != nil {
+		return entry, "", err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return entry, "", err
+	}
+	defer resp.Body.Close()
+
+	status := "downloaded"
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		status, flags = "resumed", os.O_WRONLY|os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is longer than the image is now: start over
+		if err := os.Remove(partial); err != nil {
+			return entry, "", err
+		}
+		return downloadImage(client, imageURL, target, known, isKnown)
+	case resp.StatusCode != http.StatusOK:
+		return entry, "", fmt.Errorf("%s", resp.Status)
+	}
+
+	f, err := os.OpenFile(partial, flags, 0644)
+	if err != nil {
+		return entry, "", err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return entry, "", err
+	}
+	if err := f.Close(); err != nil {
+		return entry, "", err
+	}
+
+	if entry.SHA256, entry.Size, err = fileSHA256(partial); err != nil {
+		return entry, "", err
+	}
+	if err := os.Rename(partial, target); err != nil {
+		return entry, "", err
+	}
+	return entry, status, nil
+}
+
+// fileSHA256 returns the hex SHA-256 and size of a file
+func fileSHA256(path string) (string, int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, err
+	}
+	sum, err := fileHash(path)
+	return sum, info.Size(), err
+}
+
+// formatBytes formats a size for tables, e.g. 1.2 MB
+func formatBytes(n int64) string {
+	if n == 0 {
+		return "-"
+	}
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var introspectCmd = &cobra.Command{
+	Use:   "introspect",
+	Short: "Print all commands, arguments and flags as JSON",
+	Long: `Print a catalog of every command with its arguments and flags as JSON, for
+generating wrappers, GUIs or completions for other shells.
+
+Arguments are taken from each command's usage line: <name> is required,
+[name] is optional, and a trailing ... means it can be repeated.`,
+	Example: `  specter introspect | jq '.commands[] | select(.path == "specter posts list") | .flags'`,
+	Args:    cobra.NoArgs,
+	RunE:    runIntrospect,
+}
+
+func init() {
+	rootCmd.AddCommand(introspectCmd)
+}
+
+// CommandInfo describes a command for automation
+type CommandInfo struct {
+	Path     string    `json:"path"`
+	Use      string    `json:"use"`
+	Short    string    `json:"short,omitempty"`
+	Long     string    `json:"long,omitempty"`
+	Example  string    `json:"example,omitempty"`
+	Aliases  []string  `json:"aliases,omitempty"`
+	Runnable bool      `json:"runnable"`
+	Args     []ArgInfo `json:"args"`
+	// ValidArgs lists the accepted values of the first argument, if fixed
+	ValidArgs []string   `json:"valid_args,omitempty"`
+	Flags     []FlagInfo `json:"flags"`
+}
+
+// ArgInfo describes a positional argument
+type ArgInfo struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	Variadic bool   `json:"variadic,omitempty"`
+}
+
+// FlagInfo describes a command-line flag
+type FlagInfo struct {
+	Name       string `json:"name"`
+	Shorthand  string `json:"shorthand,omitempty"`
+	Type       string `json:"type"`
+	Default    string `json:"default,omitempty"`
+	Usage      string `json:"usage"`
+	Persistent bool   `json:"persistent,omitempty"`
+}
+
+func runIntrospect(cmd *cobra.Command, args []string) error {
+	var commands []CommandInfo
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		if c.Hidden || c.Name() == "help" {
+			return
+		}
+		commands = append(commands, describeCommand(c))
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(rootCmd)
+
+	return printJSON(map[string]interface{}{
+		"global_flags": describeFlags(rootCmd.PersistentFlags(), true),
+		"commands":     commands,
+	})
+}
+
+func describeCommand(c *cobra.Command) CommandInfo {
+	info := CommandInfo{
+		Path:      c.CommandPath(),
+		Use:       c.Use,
+		Short:     c.Short,
+		Long:      c.Long,
+		Example:   c.Example,
+		Aliases:   c.Aliases,
+		Runnable:  c.Runnable(),
+		Args:      parseArgs(c.Use),
+		ValidArgs: c.ValidArgs,
+		Flags:     describeFlags(c.LocalNonPersistentFlags(), false),
+	}
+	// Persistent flags declared below the root, e.g. stats --days
+	if c != rootCmd {
+		info.Flags = append(info.Flags, describeFlags(c.PersistentFlags(), true)...)
+	}
+	return info
+}
+
+func describeFlags(fs *pflag.FlagSet, persistent bool) []FlagInfo {
+	flags := []FlagInfo{}
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Name == "help" {
+			return
+		}
+		flags = append(flags, FlagInfo{
+			Name:       f.Name,
+			Shorthand:  f.Shorthand,
+			Type:       f.Value.Type(),
+			Default:    f.DefValue,
+			Usage:      f.Usage,
+			Persistent: persistent,
+		})
+	})
+	return flags
+}
+
+// parseArgs reads the positional arguments from a usage line such as
+// "update <id-or-slug> [file]" or "upload <file-or-dir>..."
+func parseArgs(use string) []ArgInfo {
+	args := []ArgInfo{}
+	fields := strings.Fields(use)
+	if len(fields) < 2 {
+		return args
+	}
+	for _, f := range fields[1:] {
+		variadic := strings.HasSuffix(f, "...")
+		f = strings.TrimSuffix(f, "...")
+		switch {
+		case strings.HasPrefix(f, "<") && strings.HasSuffix(f, ">"):
+			args = append(args, ArgInfo{Name: strings.Trim(f, "<>"), Required: true, Variadic: variadic})
+		case strings.HasPrefix(f, "[") && strings.HasSuffix(f, "]"):
+			name := strings.TrimSuffix(strings.Trim(f, "[]"), "...")
+			args = append(args, ArgInfo{Name: name, Variadic: variadic || strings.HasSuffix(f, "...]")})
+		}
+	}
+	return args
+}
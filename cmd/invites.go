@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var invitesCmd = &cobra.Command{
+	Use:   "invites",
+	Short: "Manage staff invites",
+}
+
+var invitesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List pending invites",
+	RunE:  runInvitesList,
+}
+
+var invitesRevokeCmd = &cobra.Command{
+	Use:   "revoke <id-or-email>",
+	Short: "Revoke an invite",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runInvitesRevoke,
+}
+
+var invitesForce bool
+
+func init() {
+	rootCmd.AddCommand(invitesCmd)
+	invitesCmd.AddCommand(invitesListCmd)
+	invitesCmd.AddCommand(invitesRevokeCmd)
+
+	addExecFlag(invitesListCmd)
+	invitesRevokeCmd.Flags().BoolVar(&invitesForce, "force", false, "Revoke without asking for confirmation")
+}
+
+type Invite struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	RoleID    string `json:"role_id"`
+	Status    string `json:"status"`
+	Expires   int64  `json:"expires"`
+	CreatedAt string `json:"created_at"`
+}
+
+type invitesResponse struct {
+	Invites []Invite `json:"invites"`
+}
+
+func runInvitesList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	invites, err := listInvites(client)
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(invites)
+	}
+
+	roleNames := map[string]string{}
+	if roles, err := listRoles(client, false); err == nil {
+		for _, r := range roles {
+			roleNames[r.ID] = r.Name
+		}
+	}
+
+	return render(invites, []output.Column[Invite]{
+		{Header: "ID", Value: func(inv Invite) string { return inv.ID }},
+		{Header: "EMAIL", Value: func(inv Invite) string { return inv.Email }},
+		{Header: "ROLE", Value: func(inv Invite) string {
+			if role := roleNames[inv.RoleID]; role != "" {
+				return role
+			}
+			return inv.RoleID
+		}},
+		{Header: "STATUS", Value: func(inv Invite) string { return inv.Status }},
+		{Header: "EXPIRES", Value: func(inv Invite) string {
+			if inv.Expires > 0 {
+				return time.UnixMilli(inv.Expires).UTC().Format("2006-01-02")
+			}
+			return "-"
+		}},
+	})
+}
+
+func runInvitesRevoke(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	invites, err := listInvites(client)
+	if err != nil {
+		return err
+	}
+
+	var existing *Invite
+	for i, inv := range invites {
+		if inv.ID == args[0] || inv.Email == args[0] {
+			existing = &invites[i]
+			break
+		}
+	}
+	if existing == nil {
+		return fmt.Errorf("invite not found: %s", args[0])
+	}
+
+	if err := confirmOrAbort(fmt.Sprintf("Revoke the invite for %s (%s)?", existing.Email, existing.ID), invitesForce); err != nil {
+		return err
+	}
+
+	_, err = client.Delete(fmt.Sprintf("/invites/%s/", existing.ID))
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(map[string]string{
+			"deleted": existing.ID,
+			"email":   existing.Email,
+		})
+	}
+
+	fmt.Printf("Revoked invite: %s (%s)\n", existing.Email, existing.ID)
+	return nil
+}
+
+func listInvites(client *api.Client) ([]Invite, error) {
+	var invites []Invite
+	for data, err := range client.Paginate("/invites/", nil) {
+		if err != nil {
+			return nil, err
+		}
+		var resp invitesResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, fmt.Errorf("parsing response: %w", err)
+		}
+		invites = append(invites, resp.Invites...)
+	}
+	return invites, nil
+}
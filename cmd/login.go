@@ -2,15 +2,15 @@ package cmd
 
 import (
 	"bufio"
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"runtime"
 	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/teal-bauer/specter/api"
 	"github.com/teal-bauer/specter/internal/config"
 )
 
@@ -22,12 +22,28 @@ var loginCmd = &cobra.Command{
 This will:
 1. Ask for your Ghost site URL
 2. Open your browser to create an API integration
-3. Save your credentials to ~/.config/specter/config.yaml
+3. Ask for the Admin API key, without showing it as it is pasted
+4. Check that the key can read and change the site
+5. Save your credentials to ~/.config/specter/config.yaml
+
+A URL or key that is mistyped or doesn't work is asked for again. With
+--url and --key nothing is asked, e.g. to set up a profile in a script, and
+login fails instead. To check that the key can change the site, a draft
+titled "specter login check" is created and deleted again; Ghost sends the
+post.added and post.deleted webhooks for it. Frozen profiles and dry runs
+skip this check.
+
+With --keyring, the admin key is stored in the OS keychain (macOS Keychain,
+Secret Service via secret-tool, or Windows Credential Manager) instead of
+the config file. Profiles set up this way keep using the keychain when you
+log in again.
 
 Examples:
   specter login              # Set up default profile
   specter login myblog       # Set up profile named "myblog"
   specter login work --default  # Set up "work" as the default profile
+  specter login --keyring    # Keep the admin key in the OS keychain
+  specter login ci --url https://myblog.com --key "$GHOST_ADMIN_KEY"
 
 Then use with:
   specter posts list                # Uses default profile
@@ -39,16 +55,24 @@ Then use with:
 var (
 	loginNoBrowser bool
 	loginDefault   bool
+	loginKeyring   bool
+	loginURL       string
+	loginKey       string
 )
 
 func init() {
 	rootCmd.AddCommand(loginCmd)
 	loginCmd.Flags().BoolVar(&loginNoBrowser, "no-browser", false, "Don't open browser automatically")
 	loginCmd.Flags().BoolVar(&loginDefault, "default", false, "Set this profile as default")
+	loginCmd.Flags().BoolVar(&loginKeyring, "keyring", false, "Store the admin key in the OS keychain instead of the config file")
+	loginCmd.Flags().StringVar(&loginURL, "url", "", "Ghost site URL, instead of asking for it")
+	loginCmd.Flags().StringVar(&loginKey, "key", "", "Admin API key, instead of asking for it")
 }
 
 func runLogin(cmd *cobra.Command, args []string) error {
 	reader := bufio.NewReader(os.Stdin)
+	// With both flags there is nobody to ask again, so problems are errors
+	prompting := loginURL == "" || loginKey == ""
 
 	// Determine profile name
 	profileName := "default"
@@ -56,24 +80,126 @@ func runLogin(cmd *cobra.Command, args []string) error {
 		profileName = args[0]
 	}
 
+	// Failures from here on come with their own explanation
+	cmd.SilenceUsage = true
+
 	fmt.Printf("Setting up profile: %s\n", profileName)
 	fmt.Println()
 
-	// Get Ghost URL
-	fmt.Print("Enter your Ghost site URL (e.g., https://myblog.com): ")
-	ghostURL, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
+	// Keep the profile's other settings, e.g. proxy credentials that are
+	// needed to reach the site at all
+	cfg, _ := config.GetInstance(profileName)
+	if loginKeyring {
+		cfg.Keyring = true
 	}
-	ghostURL = strings.TrimSpace(ghostURL)
 
-	// Normalize URL
+	ghostURL := normalizeLoginURL(loginURL)
+	adminKey := strings.TrimSpace(loginKey)
+	instructedFor := ""
+	var title string
+	for {
+		if ghostURL == "" {
+			fmt.Print("Enter your Ghost site URL (e.g., https://myblog.com): ")
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("reading input: %w", err)
+			}
+			ghostURL = normalizeLoginURL(line)
+		}
+		if detail, fix := checkSiteURL(ghostURL); fix != "" {
+			if !prompting {
+				return fmt.Errorf("%s: %s", detail, fix)
+			}
+			fmt.Printf("%s. %s.\n\n", detail, fix)
+			ghostURL = ""
+			continue
+		} else if detail != "" {
+			fmt.Printf("Warning: %s\n", detail)
+		}
+
+		if adminKey == "" {
+			if instructedFor != ghostURL {
+				showIntegrationSteps(ghostURL)
+				instructedFor = ghostURL
+			}
+			key, err := readSecret(reader, "Paste your Admin API Key here: ")
+			if err != nil {
+				return fmt.Errorf("reading input: %w", err)
+			}
+			adminKey = key
+		}
+		if err := checkAdminKey(adminKey); err != nil {
+			if !prompting {
+				return fmt.Errorf("invalid admin key: %w", err)
+			}
+			fmt.Printf("Invalid key: %s.\n\n", err)
+			adminKey = ""
+			continue
+		}
+
+		// Test the connection
+		fmt.Println()
+		fmt.Println("Testing connection...")
+		cfg.URL = ghostURL
+		cfg.Key = adminKey
+		var keyFailed bool
+		var err error
+		title, keyFailed, err = verifyLogin(cfg)
+		if err == nil {
+			break
+		}
+		if !prompting {
+			return err
+		}
+		fmt.Printf("%s\n\n", err)
+		// /site/ needs no key, so a failure there is the URL's
+		if keyFailed {
+			adminKey = ""
+		} else {
+			ghostURL = ""
+		}
+	}
+
+	fmt.Printf("Connected to: %s\n", title)
+	fmt.Println()
+
+	if err := config.SaveInstance(profileName, cfg, loginDefault); err != nil {
+		return err
+	}
+
+	fmt.Printf("Saved profile '%s' to: %s\n", profileName, config.ConfigPath())
+	if cfg.Keyring {
+		fmt.Println("The admin key is stored in the OS keychain.")
+	}
+	fmt.Println()
+	fmt.Println("You're all set! Try running:")
+	if profileName == "default" {
+		fmt.Println("  specter posts list")
+		fmt.Println("  specter site info")
+	} else {
+		fmt.Printf("  specter -p %s posts list\n", profileName)
+		fmt.Printf("  specter -p %s site info\n", profileName)
+	}
+
+	return nil
+}
+
+// normalizeLoginURL adds https:// to a site URL typed without a scheme and
+// removes the trailing slash
+func normalizeLoginURL(ghostURL string) string {
+	ghostURL = strings.TrimSpace(ghostURL)
+	if ghostURL == "" {
+		return ""
+	}
 	if !strings.HasPrefix(ghostURL, "http://") && !strings.HasPrefix(ghostURL, "https://") {
 		ghostURL = "https://" + ghostURL
 	}
-	ghostURL = strings.TrimSuffix(ghostURL, "/")
+	return strings.TrimSuffix(ghostURL, "/")
+}
 
-	// Open browser to integrations page
+// showIntegrationSteps opens the page in Ghost Admin that creates an
+// integration, and explains how to get its key
+func showIntegrationSteps(ghostURL string) {
 	integrationsURL := ghostURL + "/ghost/#/settings/integrations/new"
 	fmt.Println()
 	fmt.Println("To get an Admin API key, you need to create a custom integration in Ghost.")
@@ -95,68 +221,78 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	fmt.Println("  2. Name it 'specter' (or anything you like)")
 	fmt.Println("  3. Copy the 'Admin API Key'")
 	fmt.Println()
+}
 
-	fmt.Print("Paste your Admin API Key here: ")
-	adminKey, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
-	}
-	adminKey = strings.TrimSpace(adminKey)
-
-	if adminKey == "" {
-		return fmt.Errorf("admin key cannot be empty")
-	}
-
-	// Validate the key format
-	if !strings.Contains(adminKey, ":") {
-		return fmt.Errorf("invalid key format: expected 'id:secret' format")
+// readSecret reads a line without echoing it when stdin is a terminal,
+// and then shows it masked. Echo is turned back on if the read is
+// interrupted.
+func readSecret(reader *bufio.Reader, prompt string) (string, error) {
+	fmt.Print(prompt)
+	if !interactive() || setEcho(false) != nil {
+		line, err := reader.ReadString('\n')
+		return strings.TrimSpace(line), err
 	}
 
-	// Test the connection
-	fmt.Println()
-	fmt.Println("Testing connection...")
-
-	client := api.NewClient(&config.Config{URL: ghostURL, Key: adminKey})
-
-	data, err := client.Get("/site/", nil)
-	if err != nil {
-		return fmt.Errorf("connection failed: %w", err)
-	}
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-interrupted:
+			_ = setEcho(true)
+			fmt.Println()
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+	line, err := reader.ReadString('\n')
+	signal.Stop(interrupted)
+	close(done)
+	_ = setEcho(true)
 
-	var siteResp struct {
-		Site struct {
-			Title string `json:"title"`
-		} `json:"site"`
-	}
-	if err := json.Unmarshal(data, &siteResp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
+	line = strings.TrimSpace(line)
+	if line == "" {
+		fmt.Println()
+	} else {
+		fmt.Println(maskSecret("GHOST_ADMIN_KEY", line))
 	}
+	return line, err
+}
 
-	fmt.Printf("Connected to: %s\n", siteResp.Site.Title)
-	fmt.Println()
+// verifyLogin checks that the profile's site can be reached, that Ghost
+// accepts its key, and that the key may change the site, by creating a
+// draft and deleting it again. The write check is skipped in a dry run and
+// for frozen profiles. It returns the site's title, or whether the key
+// rather than the URL is the problem.
+func verifyLogin(cfg config.Config) (title string, keyFailed bool, err error) {
+	client := newClient(&cfg)
 
-	// Save config
-	cfg := config.Config{
-		URL: ghostURL,
-		Key: adminKey,
+	var site siteResponse
+	if err := client.GetJSON("/site/", nil, &site); err != nil {
+		return "", false, fmt.Errorf("connection failed: %w", err)
 	}
 
-	if err := config.SaveInstance(profileName, cfg, loginDefault); err != nil {
-		return err
+	// /site/ is public, so the key is only checked by a request that needs it
+	if _, err := getSettings(client); err != nil {
+		return "", true, fmt.Errorf("the key wasn't accepted: %w", err)
 	}
-
-	fmt.Printf("Saved profile '%s' to: %s\n", profileName, config.ConfigPath())
-	fmt.Println()
-	fmt.Println("You're all set! Try running:")
-	if profileName == "default" {
-		fmt.Println("  specter posts list")
-		fmt.Println("  specter site info")
-	} else {
-		fmt.Printf("  specter -p %s posts list\n", profileName)
-		fmt.Printf("  specter -p %s site info\n", profileName)
+	switch {
+	case config.FlagDryRun:
+		fmt.Println("Not checking write access in a dry run.")
+	case cfg.Frozen:
+		fmt.Println("Not checking write access, as the profile is frozen.")
+	default:
+		// Changing nothing visible: a draft is created and deleted again
+		ctx := context.Background()
+		draft, err := client.Posts.Create(ctx, map[string]interface{}{"title": "specter login check", "status": "draft"}, nil)
+		if err != nil {
+			return "", true, fmt.Errorf("the key can read the site but not change it: %w", err)
+		}
+		if err := client.Posts.Delete(ctx, draft.ID); err != nil {
+			return "", false, fmt.Errorf("deleting the draft 'specter login check' (%s): %w", draft.ID, err)
+		}
 	}
-
-	return nil
+	return flagOr(site.Site.Title, cfg.URL), false, nil
 }
 
 func openBrowser(url string) error {
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+)
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout [profile]",
+	Short: "Delete the stored admin key of a profile",
+	Long: `Delete the admin key of a profile (the current one by default) from the
+config file and the OS keychain. The site URL and other settings are kept,
+so 'specter login' can set the profile up again. To remove the profile
+entirely, use 'specter profiles remove'.
+
+The Ghost integration itself stays active; delete it in Ghost admin under
+Settings > Integrations to revoke the key.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runLogout,
+}
+
+func init() {
+	rootCmd.AddCommand(logoutCmd)
+}
+
+func runLogout(cmd *cobra.Command, args []string) error {
+	name := config.CurrentProfile()
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if name == "" {
+		return fmt.Errorf("no profile selected; name one or use --profile")
+	}
+
+	if err := config.ClearKey(name); err != nil {
+		return err
+	}
+	fmt.Printf("Logged out of profile '%s'\n", name)
+	return nil
+}
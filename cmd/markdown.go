@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/content"
+)
+
+// markdownFlags holds the flags that override the profile's markdown
+// settings for one run. Frontmatter still overrides them per file.
+type markdownFlags struct {
+	typographer bool
+	headingIDs  bool
+	highlight   bool
+	// sets are the flag sets the flags are registered on, to tell which
+	// were given
+	sets []*pflag.FlagSet
+}
+
+// markdownOptionFlags is shared by the commands that render markdown files
+var markdownOptionFlags markdownFlags
+
+// addFlags registers --typographer, --heading-ids and --highlight on cmd
+func (m *markdownFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&m.typographer, "typographer", false, "Use smart quotes, dashes and ellipses (overrides the profile)")
+	cmd.Flags().BoolVar(&m.headingIDs, "heading-ids", false, "Add id attributes to headings (overrides the profile)")
+	cmd.Flags().BoolVar(&m.highlight, "highlight", false, "Highlight code blocks that name a language (overrides the profile)")
+	m.sets = append(m.sets, cmd.Flags())
+}
+
+// apply sets the options that were given as flags, so that e.g.
+// --typographer=false turns off the profile's setting
+func (m *markdownFlags) apply(opts *content.Options) {
+	for _, fs := range m.sets {
+		if fs.Changed("typographer") {
+			opts.Typographer = m.typographer
+		}
+		if fs.Changed("heading-ids") {
+			opts.HeadingIDs = m.headingIDs
+		}
+		if fs.Changed("highlight") {
+			opts.Highlight = m.highlight
+		}
+	}
+}
+
+// fetchBookmark gets the metadata of a page for a :::bookmark card from
+// Ghost, which reads it from the page as its editor does
+func fetchBookmark(client *api.Client, pageURL string) (*content.Bookmark, error) {
+	var resp struct {
+		Metadata struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+			Icon        string `json:"icon"`
+			Thumbnail   string `json:"thumbnail"`
+			Author      string `json:"author"`
+			Publisher   string `json:"publisher"`
+		} `json:"metadata"`
+	}
+	if err := client.GetJSON("/oembed/", url.Values{"url": {pageURL}, "type": {"bookmark"}}, &resp); err != nil {
+		return nil, err
+	}
+	m := resp.Metadata
+	return &content.Bookmark{Title: m.Title, Description: m.Description, Icon: m.Icon, Thumbnail: m.Thumbnail, Author: m.Author, Publisher: m.Publisher}, nil
+}
+
+// fetchEmbed gets the player of a page for an embed card through Ghost,
+// which knows the oEmbed endpoints of YouTube, Vimeo, Spotify, X/Twitter
+// and other providers
+func fetchEmbed(client *api.Client, pageURL string) (*content.Embed, error) {
+	var resp struct {
+		Type string `json:"type"`
+		HTML string `json:"html"`
+	}
+	if err := client.GetJSON("/oembed/", url.Values{"url": {pageURL}, "type": {"embed"}}, &resp); err != nil {
+		return nil, err
+	}
+	if resp.HTML == "" {
+		return nil, fmt.Errorf("no embed for %s", pageURL)
+	}
+	return &content.Embed{Type: resp.Type, HTML: resp.HTML}, nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+)
+
+var mediaCmd = &cobra.Command{
+	Use:   "media",
+	Short: "Manage video and audio files",
+}
+
+var mediaUploadCmd = &cobra.Command{
+	Use:   "upload <file>",
+	Short: "Upload a video or audio file",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runMediaUpload,
+}
+
+var filesCmd = &cobra.Command{
+	Use:   "files",
+	Short: "Manage file attachments",
+}
+
+var filesUploadCmd = &cobra.Command{
+	Use:   "upload <file>",
+	Short: "Upload a file",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFilesUpload,
+}
+
+var (
+	mediaRef       string
+	mediaThumbnail string
+	fileRef        string
+)
+
+func init() {
+	rootCmd.AddCommand(mediaCmd)
+	mediaCmd.AddCommand(mediaUploadCmd)
+	rootCmd.AddCommand(filesCmd)
+	filesCmd.AddCommand(filesUploadCmd)
+
+	mediaUploadCmd.Flags().StringVar(&mediaRef, "ref", "", "Reference name for the file")
+	mediaUploadCmd.Flags().StringVar(&mediaThumbnail, "thumbnail", "", "Thumbnail image shown before a video plays")
+
+	filesUploadCmd.Flags().StringVar(&fileRef, "ref", "", "Reference name for the file")
+}
+
+func runMediaUpload(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	url, thumbnailURL, err := client.UploadMedia(args[0], mediaThumbnail, mediaRef)
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(map[string]string{
+			"url":           url,
+			"thumbnail_url": thumbnailURL,
+			"ref":           mediaRef,
+		})
+	}
+
+	fmt.Println(url)
+	if thumbnailURL != "" {
+		fmt.Println(thumbnailURL)
+	}
+	return nil
+}
+
+func runFilesUpload(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	url, err := client.UploadFile(args[0], fileRef)
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(map[string]string{
+			"url": url,
+			"ref": fileRef,
+		})
+	}
+
+	fmt.Println(url)
+	return nil
+}
@@ -1,15 +1,24 @@
 package cmd
 
 import (
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"os"
-	"text/tabwriter"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 	"github.com/teal-bauer/specter/api"
 	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
 )
 
 var membersCmd = &cobra.Command{
@@ -51,14 +60,67 @@ var membersDeleteCmd = &cobra.Command{
 	RunE:  runMembersDelete,
 }
 
+var membersLabelCmd = &cobra.Command{
+	Use:   "label",
+	Short: "Add or remove labels on all members matching a filter",
+	Long: `Add or remove labels on all members matching a filter.
+
+Uses Ghost's bulk edit endpoint, falling back to updating members one at a
+time if the site doesn't support it. Labels that don't exist yet are created.
+
+Examples:
+  specter members label --filter 'status:free' --add vip --remove trial
+  specter members label --all --add newsletter-2024`,
+	Args: cobra.NoArgs,
+	RunE: runMembersLabel,
+}
+
+var membersDeleteBulkCmd = &cobra.Command{
+	Use:   "delete-bulk",
+	Short: "Delete all members matching a filter",
+	Long: `Delete all members matching a filter, e.g. to clean up spam signups.
+
+Shows how many members match and asks for confirmation unless --force is given.
+With --dry-run, only the number of matching members is shown.
+
+Examples:
+  specter members delete-bulk --filter "email:~$'@spam.example'" --dry-run
+  specter members delete-bulk --filter 'label:spam' --force`,
+	Args: cobra.NoArgs,
+	RunE: runMembersDeleteBulk,
+}
+
+var membersAnnotateCmd = &cobra.Command{
+	Use:   "annotate <notes.csv>",
+	Short: "Add notes to members from a CSV file",
+	Long: `Add notes to existing members from a CSV file with 'email' and 'note'
+columns. Use '-' to read from stdin.
+
+By default each note is appended to the member's existing note (unless it is
+already there); use --replace to overwrite existing notes instead. Emails that
+don't belong to a member are reported and skipped.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMembersAnnotate,
+}
+
 var (
-	membersLimit    int
-	membersAll      bool
-	membersFilter   string
-	memberName      string
-	memberNote      string
-	memberLabels    []string
+	membersLimit     int
+	membersAll       bool
+	membersFilter    string
+	membersSince     string
+	membersUntil     string
+	membersColumns   []string
+	memberName       string
+	memberNote       string
+	memberLabels     []string
 	memberNewsletter bool
+	labelAdd         []string
+	labelRemove      []string
+	labelAll         bool
+	labelWorkers     int
+	deleteForce      bool
+	annotateReplace  bool
+	annotateWorkers  int
 )
 
 func init() {
@@ -68,10 +130,18 @@ func init() {
 	membersCmd.AddCommand(membersCreateCmd)
 	membersCmd.AddCommand(membersUpdateCmd)
 	membersCmd.AddCommand(membersDeleteCmd)
+	membersCmd.AddCommand(membersLabelCmd)
+	membersCmd.AddCommand(membersDeleteBulkCmd)
+	membersCmd.AddCommand(membersAnnotateCmd)
 
 	membersListCmd.Flags().IntVar(&membersLimit, "limit", 15, "Number of members to return")
 	membersListCmd.Flags().BoolVar(&membersAll, "all", false, "Fetch all members")
 	membersListCmd.Flags().StringVar(&membersFilter, "filter", "", "Filter members (e.g., 'status:free')")
+	membersListCmd.Flags().StringVar(&membersSince, "since", "", "Only list members who signed up since this date or duration ago (e.g. 2025-01-01, 30d)")
+	membersListCmd.Flags().StringVar(&membersUntil, "until", "", "Only list members who signed up until this date or duration ago")
+	membersListCmd.Flags().StringSliceVar(&membersColumns, "columns", []string{"id", "email", "name", "status"},
+		"Table columns: id, email, name, status, created, emails, opened, last_seen, labels")
+	addExecFlag(membersListCmd)
 
 	membersCreateCmd.Flags().StringVar(&memberName, "name", "", "Member name")
 	membersCreateCmd.Flags().StringVar(&memberNote, "note", "", "Member note")
@@ -81,38 +151,84 @@ func init() {
 	membersUpdateCmd.Flags().StringVar(&memberName, "name", "", "Update member name")
 	membersUpdateCmd.Flags().StringVar(&memberNote, "note", "", "Update member note")
 	membersUpdateCmd.Flags().StringSliceVar(&memberLabels, "labels", nil, "Update member labels")
+
+	membersLabelCmd.Flags().StringVar(&membersFilter, "filter", "", "Filter members (e.g., 'status:free')")
+	membersLabelCmd.Flags().BoolVar(&labelAll, "all", false, "Apply to all members")
+	membersLabelCmd.Flags().StringSliceVar(&labelAdd, "add", nil, "Labels to add")
+	membersLabelCmd.Flags().StringSliceVar(&labelRemove, "remove", nil, "Labels to remove")
+	membersLabelCmd.Flags().IntVar(&labelWorkers, "workers", 4, "Concurrent requests when falling back to per-member updates")
+
+	membersDeleteBulkCmd.Flags().StringVar(&membersFilter, "filter", "", "Filter members to delete (required)")
+	membersDeleteCmd.Flags().BoolVar(&deleteForce, "force", false, "Delete without asking for confirmation")
+	membersDeleteBulkCmd.Flags().BoolVar(&deleteForce, "force", false, "Delete without asking for confirmation")
+	membersDeleteBulkCmd.MarkFlagRequired("filter")
+	addReportFlag(membersDeleteBulkCmd)
+
+	membersAnnotateCmd.Flags().BoolVar(&annotateReplace, "replace", false, "Replace existing notes instead of appending")
+	membersAnnotateCmd.Flags().IntVar(&annotateWorkers, "workers", 4, "Concurrent update requests")
+	addReportFlag(membersAnnotateCmd)
+	addReportFlag(membersLabelCmd)
+}
+
+// MemberResult is what a bulk members command did with one member
+type MemberResult struct {
+	ID     string `json:"id,omitempty"`
+	Email  string `json:"email"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// memberResults returns a result with the given status for each member
+func memberResults(members []Member, status string) []MemberResult {
+	results := make([]MemberResult, len(members))
+	for i, m := range members {
+		results[i] = MemberResult{ID: m.ID, Email: m.Email, Status: status}
+	}
+	return results
 }
 
-type Member struct {
-	ID            string   `json:"id"`
-	UUID          string   `json:"uuid"`
-	Email         string   `json:"email"`
-	Name          string   `json:"name,omitempty"`
-	Note          string   `json:"note,omitempty"`
-	Status        string   `json:"status"`
-	Subscribed    bool     `json:"subscribed"`
-	CreatedAt     string   `json:"created_at"`
-	Labels        []Label  `json:"labels,omitempty"`
-	Newsletters   []Newsletter `json:"newsletters,omitempty"`
+// memberColumns are the columns available in the members table
+var memberColumns = map[string]output.Column[Member]{
+	"id":     {Header: "ID", Value: func(m Member) string { return m.ID }},
+	"email":  {Header: "EMAIL", Value: func(m Member) string { return m.Email }},
+	"name":   {Header: "NAME", Value: func(m Member) string { return orDash(m.Name) }},
+	"status": {Header: "STATUS", Value: func(m Member) string { return m.Status }},
+	"created": {Header: "CREATED", Value: func(m Member) string {
+		return orDash(truncateDate(m.CreatedAt))
+	}},
+	"emails": {Header: "EMAILS", Value: func(m Member) string { return strconv.Itoa(m.EmailCount) }},
+	"opened": {Header: "OPENED%", Value: func(m Member) string {
+		// Ghost only computes the rate once enough emails were sent
+		if m.EmailOpenRate == nil {
+			return "-"
+		}
+		return strconv.Itoa(*m.EmailOpenRate) + "%"
+	}},
+	"last_seen": {Header: "LAST_SEEN", Value: func(m Member) string {
+		return orDash(truncateDate(m.LastSeenAt))
+	}},
+	"labels": {Header: "LABELS", Value: func(m Member) string {
+		var names []string
+		for _, l := range m.Labels {
+			names = append(names, l.Name)
+		}
+		return orDash(strings.Join(names, ","))
+	}},
 }
 
-type Label struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
-	Slug string `json:"slug"`
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
 }
 
-type membersResponse struct {
-	Members []Member `json:"members"`
-	Meta    struct {
-		Pagination struct {
-			Page  int `json:"page"`
-			Limit int `json:"limit"`
-			Pages int `json:"pages"`
-			Total int `json:"total"`
-			Next  int `json:"next"`
-		} `json:"pagination"`
-	} `json:"meta"`
+// truncateDate returns the date part of a timestamp
+func truncateDate(ts string) string {
+	if len(ts) > 10 {
+		return ts[:10]
+	}
+	return ts
 }
 
 func runMembersList(cmd *cobra.Command, args []string) error {
@@ -120,72 +236,60 @@ func runMembersList(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
-	var allMembers []Member
-
-	if membersAll {
-		page := 1
-		for {
-			params := url.Values{}
-			params.Set("limit", "100")
-			params.Set("page", fmt.Sprintf("%d", page))
-			if membersFilter != "" {
-				params.Set("filter", membersFilter)
+	for _, c := range membersColumns {
+		if _, ok := memberColumns[c]; !ok {
+			var available []string
+			for name := range memberColumns {
+				available = append(available, name)
 			}
+			sort.Strings(available)
+			return fmt.Errorf("unknown column %q (available: %s)", c, strings.Join(available, ", "))
+		}
+	}
 
-			data, err := client.Get("/members/", params)
+	dates, err := dateRangeFilter("created_at", membersSince, membersUntil)
+	if err != nil {
+		return err
+	}
+	filter := joinFilters(membersFilter, dates)
+
+	var allMembers []Member
+	var pagination *Pagination
+
+	if membersAll && streaming() {
+		for m, err := range client.Members.All(context.Background(), &api.ListOptions{Filter: filter}) {
 			if err != nil {
 				return err
 			}
-
-			var resp membersResponse
-			if err := json.Unmarshal(data, &resp); err != nil {
-				return fmt.Errorf("parsing response: %w", err)
-			}
-
-			allMembers = append(allMembers, resp.Members...)
-
-			if resp.Meta.Pagination.Next == 0 {
-				break
+			if err := streamItems([]Member{m}); err != nil {
+				return err
 			}
-			page = resp.Meta.Pagination.Next
 		}
-	} else {
-		params := url.Values{}
-		params.Set("limit", fmt.Sprintf("%d", membersLimit))
-		if membersFilter != "" {
-			params.Set("filter", membersFilter)
-		}
-
-		data, err := client.Get("/members/", params)
+	} else if membersAll {
+		allMembers, err = listAllMembers(client, filter)
 		if err != nil {
 			return err
 		}
-
-		var resp membersResponse
-		if err := json.Unmarshal(data, &resp); err != nil {
-			return fmt.Errorf("parsing response: %w", err)
+	} else {
+		list, err := client.Members.List(context.Background(), &api.ListOptions{Filter: filter, Limit: membersLimit})
+		if err != nil {
+			return err
 		}
-		allMembers = resp.Members
+		allMembers = list.Items
+		pagination = &list.Pagination
 	}
 
-	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(allMembers)
+	if pagination != nil && config.OutputFormat() == "json" {
+		return encodePage("members", allMembers, *pagination)
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tEMAIL\tNAME\tSTATUS")
-	for _, m := range allMembers {
-		name := m.Name
-		if name == "" {
-			name = "-"
-		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.ID, m.Email, name, m.Status)
+	columns := make([]output.Column[Member], len(membersColumns))
+	for i, c := range membersColumns {
+		columns[i] = memberColumns[c]
 	}
-	return w.Flush()
+	return render(allMembers, columns)
 }
 
 func runMembersGet(cmd *cobra.Command, args []string) error {
@@ -193,7 +297,7 @@ func runMembersGet(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	member, err := getMember(client, args[0])
 	if err != nil {
@@ -201,9 +305,7 @@ func runMembersGet(cmd *cobra.Command, args []string) error {
 	}
 
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(member)
+		return printJSON(member)
 	}
 
 	fmt.Printf("ID:         %s\n", member.ID)
@@ -235,7 +337,7 @@ func runMembersCreate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	member := map[string]interface{}{
 		"email": args[0],
@@ -255,30 +357,13 @@ func runMembersCreate(cmd *cobra.Command, args []string) error {
 		member["labels"] = labels
 	}
 
-	body := map[string]interface{}{
-		"members": []interface{}{member},
-	}
-
-	data, err := client.Post("/members/", body)
+	created, err := client.Members.Create(context.Background(), member, nil)
 	if err != nil {
 		return err
 	}
 
-	var resp membersResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
-
-	if len(resp.Members) == 0 {
-		return fmt.Errorf("no member in response")
-	}
-
-	created := resp.Members[0]
-
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(created)
+		return printJSON(created)
 	}
 
 	fmt.Printf("Created member: %s\n", created.Email)
@@ -292,7 +377,7 @@ func runMembersUpdate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	existing, err := getMember(client, args[0])
 	if err != nil {
@@ -319,89 +404,631 @@ func runMembersUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no updates specified")
 	}
 
-	body := map[string]interface{}{
-		"members": []interface{}{member},
+	updated, err := client.Members.Update(context.Background(), existing.ID, member, nil)
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(updated)
 	}
 
-	data, err := client.Put(fmt.Sprintf("/members/%s/", existing.ID), body)
+	fmt.Printf("Updated member: %s\n", updated.Email)
+	fmt.Printf("  ID: %s\n", updated.ID)
+	return nil
+}
+
+func runMembersDelete(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
+	client := newClient(cfg)
 
-	var resp membersResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
+	existing, err := getMember(client, args[0])
+	if err != nil {
+		return err
 	}
 
-	if len(resp.Members) == 0 {
-		return fmt.Errorf("no member in response")
+	if err := confirmOrAbort(fmt.Sprintf("Delete member '%s' (%s)?", existing.Email, existing.ID), deleteForce); err != nil {
+		return err
 	}
 
-	updated := resp.Members[0]
+	if err := client.Members.Delete(context.Background(), existing.ID); err != nil {
+		return err
+	}
 
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(updated)
+		return printJSON(map[string]string{
+			"deleted": existing.ID,
+			"email":   existing.Email,
+		})
 	}
 
-	fmt.Printf("Updated member: %s\n", updated.Email)
-	fmt.Printf("  ID: %s\n", updated.ID)
+	fmt.Printf("Deleted member: %s (%s)\n", existing.Email, existing.ID)
 	return nil
 }
 
-func runMembersDelete(cmd *cobra.Command, args []string) error {
+func runMembersLabel(cmd *cobra.Command, args []string) error {
+	if len(labelAdd) == 0 && len(labelRemove) == 0 {
+		return fmt.Errorf("no labels specified (use --add and/or --remove)")
+	}
+	if membersFilter == "" && !labelAll {
+		return fmt.Errorf("specify --filter or --all")
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
-	existing, err := getMember(client, args[0])
+	var add, remove []Label
+	for _, name := range labelAdd {
+		label, err := findLabel(client, name)
+		if err != nil {
+			return err
+		}
+		if label == nil {
+			if label, err = createLabel(client, name); err != nil {
+				return err
+			}
+		}
+		add = append(add, *label)
+	}
+	for _, name := range labelRemove {
+		label, err := findLabel(client, name)
+		if err != nil {
+			return err
+		}
+		if label == nil {
+			fmt.Fprintf(os.Stderr, "warning: label not found, skipping: %s\n", name)
+			continue
+		}
+		remove = append(remove, *label)
+	}
+
+	// The bulk endpoint doesn't say which members it changed, so the report
+	// works that out from the members beforehand
+	var before []Member
+	if reportPath != "" {
+		if before, err = listAllMembers(client, membersFilter); err != nil {
+			return err
+		}
+	}
+
+	result := map[string]int{}
+	bulkErr := func() error {
+		for _, l := range add {
+			n, err := bulkEditMembers(client, membersFilter, "addLabel", l)
+			if err != nil {
+				return err
+			}
+			result["added:"+l.Name] = n
+		}
+		for _, l := range remove {
+			n, err := bulkEditMembers(client, membersFilter, "removeLabel", l)
+			if err != nil {
+				return err
+			}
+			result["removed:"+l.Name] = n
+		}
+		return nil
+	}()
+
+	// Only sites without the bulk endpoint fall back; any other error, a
+	// dry run included, would fail the same way for every member
+	if bulkErr != nil && !bulkUnsupported(bulkErr) {
+		return bulkErr
+	}
+
+	var results []MemberResult
+	failed := 0
+	if bulkErr != nil {
+		fmt.Fprintf(os.Stderr, "The site has no bulk edit endpoint (%v), updating members individually...\n", bulkErr)
+		results, err = relabelMembers(client, membersFilter, add, remove, labelWorkers)
+		if err != nil {
+			return err
+		}
+		updated := 0
+		for _, r := range results {
+			switch r.Status {
+			case "updated":
+				updated++
+			case "failed":
+				failed++
+			}
+		}
+		result = map[string]int{"updated": updated}
+	} else {
+		for _, m := range before {
+			r := MemberResult{ID: m.ID, Email: m.Email, Status: "unchanged"}
+			if _, changed := applyLabelChanges(m.Labels, add, remove); changed {
+				r.Status = "updated"
+			}
+			results = append(results, r)
+		}
+	}
+	if err := writeReport(cmd, cfg, results, failed); err != nil {
+		return err
+	}
+
+	switch {
+	case config.OutputFormat() == "json":
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	case bulkErr != nil:
+		fmt.Printf("Updated %d members\n", result["updated"])
+	default:
+		for _, l := range add {
+			fmt.Printf("Added label %s to %d members\n", l.Name, result["added:"+l.Name])
+		}
+		for _, l := range remove {
+			fmt.Printf("Removed label %s from %d members\n", l.Name, result["removed:"+l.Name])
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d members failed to update", failed, len(results))
+	}
+	return nil
+}
+
+func runMembersDeleteBulk(cmd *cobra.Command, args []string) error {
+	if strings.TrimSpace(membersFilter) == "" {
+		return fmt.Errorf("--filter must not be empty")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	count, err := countMembers(client, membersFilter)
 	if err != nil {
 		return err
 	}
+	// The report lists the members, which the bulk delete doesn't return
+	var matched []Member
+	if reportPath != "" && count > 0 {
+		if matched, err = listAllMembers(client, membersFilter); err != nil {
+			return err
+		}
+	}
+
+	if config.FlagDryRun || count == 0 {
+		if err := writeReport(cmd, cfg, memberResults(matched, "would delete"), 0); err != nil {
+			return err
+		}
+		if config.OutputFormat() == "json" {
+			return printJSON(map[string]interface{}{
+				"filter":  membersFilter,
+				"matched": count,
+				"deleted": 0,
+			})
+		}
+		fmt.Printf("%d members match filter: %s\n", count, membersFilter)
+		return nil
+	}
+
+	if err := confirmOrAbort(fmt.Sprintf("Delete %d members matching '%s'?", count, membersFilter), deleteForce); err != nil {
+		return err
+	}
 
-	_, err = client.Delete(fmt.Sprintf("/members/%s/", existing.ID))
+	params := url.Values{}
+	params.Set("filter", membersFilter)
+	data, err := client.Delete("/members/?" + params.Encode())
 	if err != nil {
 		return err
 	}
 
+	var resp struct {
+		Meta struct {
+			Stats struct {
+				Successful   int `json:"successful"`
+				Unsuccessful int `json:"unsuccessful"`
+			} `json:"stats"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	stats := resp.Meta.Stats
+
+	results := memberResults(matched, "deleted")
+	if reportPath != "" && stats.Unsuccessful > 0 {
+		// The members that still match are the ones that weren't deleted
+		left, err := listAllMembers(client, membersFilter)
+		if err != nil {
+			return err
+		}
+		remaining := map[string]bool{}
+		for _, m := range left {
+			remaining[m.ID] = true
+		}
+		for i := range results {
+			if remaining[results[i].ID] {
+				results[i].Status = "failed"
+			}
+		}
+	}
+	if err := writeReport(cmd, cfg, results, stats.Unsuccessful); err != nil {
+		return err
+	}
+
 	if config.OutputFormat() == "json" {
-		return json.NewEncoder(os.Stdout).Encode(map[string]string{
-			"deleted": existing.ID,
-			"email":   existing.Email,
+		return printJSON(map[string]interface{}{
+			"filter":  membersFilter,
+			"matched": count,
+			"deleted": stats.Successful,
+			"failed":  stats.Unsuccessful,
 		})
 	}
 
-	fmt.Printf("Deleted member: %s (%s)\n", existing.Email, existing.ID)
+	fmt.Printf("Deleted %d members\n", stats.Successful)
+	if stats.Unsuccessful > 0 {
+		return fmt.Errorf("%d members could not be deleted", stats.Unsuccessful)
+	}
 	return nil
 }
 
-func getMember(client *api.Client, idOrEmail string) (*Member, error) {
-	data, err := client.Get(fmt.Sprintf("/members/%s/", idOrEmail), nil)
-	if err == nil {
-		var resp membersResponse
-		if err := json.Unmarshal(data, &resp); err == nil && len(resp.Members) > 0 {
-			return &resp.Members[0], nil
+func runMembersAnnotate(cmd *cobra.Command, args []string) error {
+	notes, err := readMemberNotes(args[0])
+	if err != nil {
+		return err
+	}
+	if len(notes) == 0 {
+		return fmt.Errorf("no notes found in %s", args[0])
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	var emails []string
+	for email := range notes {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+
+	// Look members up in batches rather than one request per email
+	var members []Member
+	const batchSize = 50
+	for i := 0; i < len(emails); i += batchSize {
+		batch := emails[i:min(i+batchSize, len(emails))]
+		quoted := make([]string, len(batch))
+		for j, e := range batch {
+			quoted[j] = "'" + strings.ReplaceAll(e, "'", "\\'") + "'"
+		}
+		found, err := listAllMembers(client, "email:["+strings.Join(quoted, ",")+"]")
+		if err != nil {
+			return err
+		}
+		members = append(members, found...)
+	}
+
+	found := map[string]bool{}
+	var missing []string
+	for _, m := range members {
+		found[strings.ToLower(m.Email)] = true
+	}
+	for _, e := range emails {
+		if !found[e] {
+			missing = append(missing, e)
+		}
+	}
+
+	workers := max(annotateWorkers, 1)
+	results := memberResults(members, "unchanged")
+	jobs := make(chan int)
+	var (
+		mu        sync.Mutex
+		updated   int
+		unchanged int
+		errs      []error
+		wg        sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				m, r := members[j], &results[j]
+				note := mergeNote(m.Note, notes[strings.ToLower(m.Email)], annotateReplace)
+				if note == m.Note {
+					mu.Lock()
+					unchanged++
+					mu.Unlock()
+					continue
+				}
+				if config.FlagDryRun {
+					mu.Lock()
+					updated++
+					r.Status = "would update"
+					mu.Unlock()
+					continue
+				}
+				_, err := client.Members.Update(context.Background(), m.ID, map[string]interface{}{"note": note}, nil)
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", m.Email, err))
+					r.Status, r.Error = "failed", err.Error()
+				} else {
+					updated++
+					r.Status = "updated"
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for j := range members {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	}
+	for _, e := range missing {
+		results = append(results, MemberResult{Email: e, Status: "not found"})
+	}
+	if err := writeReport(cmd, cfg, results, len(errs)); err != nil {
+		return err
+	}
+
+	key, verb := "updated", "Updated"
+	if config.FlagDryRun {
+		key, verb = "would_update", "Would update"
+	}
+	if config.OutputFormat() == "json" {
+		if err := printJSON(map[string]interface{}{
+			key:         updated,
+			"unchanged": unchanged,
+			"failed":    len(errs),
+			"missing":   missing,
+		}); err != nil {
+			return err
 		}
+	} else {
+		for _, e := range missing {
+			fmt.Fprintf(os.Stderr, "warning: no member with email %s\n", e)
+		}
+		fmt.Printf("%s %d members (%d unchanged, %d not found)\n", verb, updated, unchanged, len(missing))
 	}
 
+	if len(errs) > 0 {
+		return fmt.Errorf("%d members failed to update", len(errs))
+	}
+	return nil
+}
+
+// readMemberNotes reads email/note pairs from a CSV file with a header row.
+// Emails are lowercased; repeated emails have their notes joined.
+func readMemberNotes(path string) (map[string]string, error) {
+	var r io.Reader
+	if path == "-" {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("opening file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	emailCol, noteCol := -1, -1
+	for i, h := range records[0] {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "email":
+			emailCol = i
+		case "note":
+			noteCol = i
+		}
+	}
+	if emailCol < 0 || noteCol < 0 {
+		return nil, fmt.Errorf("CSV must have 'email' and 'note' columns")
+	}
+
+	notes := map[string]string{}
+	for _, rec := range records[1:] {
+		email := strings.ToLower(strings.TrimSpace(rec[emailCol]))
+		note := strings.TrimSpace(rec[noteCol])
+		if email == "" || note == "" {
+			continue
+		}
+		if existing, ok := notes[email]; ok {
+			note = existing + "\n" + note
+		}
+		notes[email] = note
+	}
+	return notes, nil
+}
+
+// mergeNote combines a member's current note with a new one
+func mergeNote(current, note string, replace bool) string {
+	if replace || current == "" {
+		return note
+	}
+	if strings.Contains(current, note) {
+		return current
+	}
+	return current + "\n" + note
+}
+
+// countMembers returns the number of members matching filter
+func countMembers(client *api.Client, filter string) (int, error) {
+	list, err := client.Members.List(context.Background(), &api.ListOptions{Filter: filter, Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	return list.Pagination.Total, nil
+}
+
+// bulkEditMembers applies a bulk label action to all members matching filter
+// and returns the number of members changed
+func bulkEditMembers(client *api.Client, filter, action string, label Label) (int, error) {
 	params := url.Values{}
-	params.Set("filter", fmt.Sprintf("email:%s", idOrEmail))
-	data, err = client.Get("/members/", params)
+	if filter != "" {
+		params.Set("filter", filter)
+	} else {
+		params.Set("all", "true")
+	}
+
+	body := map[string]interface{}{
+		"bulk": map[string]interface{}{
+			"action": action,
+			"meta": map[string]interface{}{
+				"label": map[string]string{"id": label.ID},
+			},
+		},
+	}
+
+	data, err := client.Put("/members/bulk/?"+params.Encode(), body)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	var resp membersResponse
+	var resp struct {
+		Bulk struct {
+			Meta struct {
+				Stats struct {
+					Successful   int `json:"successful"`
+					Unsuccessful int `json:"unsuccessful"`
+				} `json:"stats"`
+			} `json:"meta"`
+		} `json:"bulk"`
+	}
 	if err := json.Unmarshal(data, &resp); err != nil {
-		return nil, fmt.Errorf("parsing response: %w", err)
+		return 0, fmt.Errorf("parsing response: %w", err)
 	}
+	return resp.Bulk.Meta.Stats.Successful, nil
+}
+
+// bulkUnsupported reports whether err is the site saying it has no bulk
+// members endpoint, as Ghost versions before it was added do
+func bulkUnsupported(err error) bool {
+	var apiErr *api.APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusMethodNotAllowed)
+}
+
+// relabelMembers updates the labels of each matching member individually,
+// using a pool of workers, and returns what was done with each
+func relabelMembers(client *api.Client, filter string, add, remove []Label, workers int) ([]MemberResult, error) {
+	members, err := listAllMembers(client, filter)
+	if err != nil {
+		return nil, err
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := memberResults(members, "unchanged")
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				m, r := members[j], &results[j]
+				labels, changed := applyLabelChanges(m.Labels, add, remove)
+				if !changed {
+					continue
+				}
+				if config.FlagDryRun {
+					r.Status = "would update"
+					continue
+				}
+				update := map[string]interface{}{"labels": labels}
+				if _, err := client.Members.Update(context.Background(), m.ID, update, nil); err != nil {
+					fmt.Fprintf(os.Stderr, "error: %s: %v\n", m.Email, err)
+					r.Status, r.Error = "failed", err.Error()
+					continue
+				}
+				r.Status = "updated"
+			}
+		}()
+	}
+	for j := range members {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+	return results, nil
+}
 
-	if len(resp.Members) == 0 {
-		return nil, fmt.Errorf("member not found: %s", idOrEmail)
+// applyLabelChanges returns the new label set for a member and whether it
+// differs from the current one
+func applyLabelChanges(current, add, remove []Label) ([]map[string]string, bool) {
+	removeIDs := map[string]bool{}
+	for _, l := range remove {
+		removeIDs[l.ID] = true
 	}
 
-	return &resp.Members[0], nil
+	changed := false
+	have := map[string]bool{}
+	var labels []map[string]string
+	for _, l := range current {
+		if removeIDs[l.ID] {
+			changed = true
+			continue
+		}
+		have[l.ID] = true
+		labels = append(labels, map[string]string{"id": l.ID})
+	}
+	for _, l := range add {
+		if !have[l.ID] {
+			changed = true
+			have[l.ID] = true
+			labels = append(labels, map[string]string{"id": l.ID})
+		}
+	}
+	return labels, changed
+}
+
+func listAllMembers(client *api.Client, filter string) ([]Member, error) {
+	var allMembers []Member
+	for m, err := range client.Members.All(context.Background(), &api.ListOptions{Filter: filter}) {
+		if err != nil {
+			return nil, err
+		}
+		allMembers = append(allMembers, m)
+	}
+	return allMembers, nil
+}
+
+// findLabel looks up a label by name, returning nil if it doesn't exist
+func findLabel(client *api.Client, name string) (*Label, error) {
+	list, err := client.Labels.List(context.Background(), &api.ListOptions{
+		Filter: fmt.Sprintf("name:'%s'", strings.ReplaceAll(name, "'", "\\'")),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+	return &list.Items[0], nil
+}
+
+func createLabel(client *api.Client, name string) (*Label, error) {
+	return client.Labels.Create(context.Background(), map[string]string{"name": name}, nil)
+}
+
+func getMember(client *api.Client, idOrEmail string) (*Member, error) {
+	return client.Members.Lookup(context.Background(), idOrEmail, nil)
 }
@@ -0,0 +1,473 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/content"
+	"github.com/teal-bauer/specter/internal/migrate"
+	"github.com/teal-bauer/specter/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Import content from Hugo, Jekyll or WordPress",
+}
+
+var migrateHugoCmd = &cobra.Command{
+	Use:   "hugo <site-dir>",
+	Short: "Create posts and pages from a Hugo site",
+	Long: `Create a post for every markdown file in the sections of a Hugo site's
+content/ directory, and a page for every file directly in content/.
+
+Frontmatter is mapped to Ghost: date or publishDate becomes the publish
+date, draft: true a draft, tags and categories tags, summary or description
+the excerpt, and the first of images, image or cover the feature image.
+Built-in shortcodes are converted: figure to an image, highlight to a code
+block, ref and relref to links, and youtube, vimeo, gist, tweet and
+instagram to embed cards. Other shortcodes are left in place with a
+warning.
+
+Images referenced by the content are uploaded, looking up site paths such
+as /images/a.png in static/. Items whose slug is already taken are skipped.
+
+With --dry-run, nothing is uploaded or created, and the result lists what
+would be. Use --redirects to write the items' old URLs, from aliases and
+Hugo's section paths, to a redirects file for "specter deploy".`,
+	Example: `  specter migrate hugo ~/blog --dry-run
+  specter migrate hugo ~/blog --status draft --redirects redirects.yaml --report migrate.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrate(cmd, args[0], migrate.ReadHugo)
+	},
+}
+
+var migrateJekyllCmd = &cobra.Command{
+	Use:   "jekyll <site-dir>",
+	Short: "Create posts and pages from a Jekyll site",
+	Long: `Create a post for every file in a Jekyll site's _posts/ directory, a draft
+for every file in _drafts/, and a page for every markdown file at the top of
+the site or in _pages/.
+
+Frontmatter is mapped to Ghost: the date from the frontmatter or the file
+name becomes the publish date, published: false a draft, tags and
+categories tags, excerpt or description the excerpt, and image the feature
+image. Liquid highlight blocks become code blocks and post_url and link
+tags links; other Liquid is left in place with a warning.
+
+Images referenced by the content are uploaded, looking up site paths such
+as /assets/a.png in the site directory. Items whose slug is already taken
+are skipped.
+
+With --dry-run, nothing is uploaded or created, and the result lists what
+would be. Use --redirects to write the items' old URLs, from the permalink
+setting in _config.yml, permalink and redirect_from, to a redirects file
+for "specter deploy".`,
+	Example: `  specter migrate jekyll ~/blog --dry-run
+  specter migrate jekyll ~/blog --redirects redirects.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrate(cmd, args[0], migrate.ReadJekyll)
+	},
+}
+
+var migrateWordPressCmd = &cobra.Command{
+	Use:   "wordpress <export.xml>",
+	Short: "Create posts and pages from a WordPress export",
+	Long: `Create the posts and pages in a WordPress export file (WXR), made with
+Tools → Export in WordPress admin.
+
+Content is converted to markdown and rendered as by "posts create": embeds
+become embed cards, [caption] shortcodes captioned images, and other
+shortcodes are left in place with a warning. Slugs and publish dates are
+kept; drafts, pending and private posts become drafts, and scheduled posts
+stay scheduled. Categories and tags become tags, the main category first,
+and featured images feature images. Links between the migrated posts are
+changed to their new paths.
+
+Images on the WordPress site are downloaded and uploaded to Ghost; images
+elsewhere are left where they are. Items whose slug is already taken are
+skipped.
+
+Posts get the default author unless --authors-map maps WordPress logins to
+staff users, by email, slug or ID:
+
+  admin: jane@example.com
+  jdoe: john
+
+With --dry-run, nothing is downloaded, uploaded or created, and the result
+lists what would be. Use --redirects to write the items' old permalinks to
+a redirects file for "specter deploy".`,
+	Example: `  specter migrate wordpress export.xml --dry-run
+  specter migrate wordpress export.xml --authors-map authors.yaml --redirects redirects.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrate(cmd, args[0], migrate.ReadWordPress)
+	},
+}
+
+var (
+	migrateStatus     string
+	migrateRedirects  string
+	migrateAuthorsMap string
+)
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateWordPressCmd.Flags().StringVar(&migrateAuthorsMap, "authors-map", "", "YAML file mapping WordPress logins to staff emails, slugs or IDs")
+	for _, cmd := range []*cobra.Command{migrateHugoCmd, migrateJekyllCmd, migrateWordPressCmd} {
+		migrateCmd.AddCommand(cmd)
+		cmd.Flags().StringVar(&migrateStatus, "status", "", "Status for every item instead of its own (draft, published)")
+		cmd.Flags().StringVar(&migrateRedirects, "redirects", "", "Write redirects from the items' old paths to this YAML file")
+		markdownOptionFlags.addFlags(cmd)
+		addReportFlag(cmd)
+	}
+}
+
+// MigrateResult is the outcome of migrating one file
+type MigrateResult struct {
+	File   string `json:"file"`
+	Type   string `json:"type"`
+	Slug   string `json:"slug"`
+	Status string `json:"status"`
+	// Images is the number of local images the item references
+	Images int    `json:"images"`
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func runMigrate(cmd *cobra.Command, dir string, read func(string) (*migrate.Site, error)) error {
+	switch migrateStatus {
+	case "", "draft", "published":
+	default:
+		return fmt.Errorf("--status must be draft or published")
+	}
+	site, err := read(dir)
+	if err != nil {
+		return err
+	}
+	if len(site.Items) == 0 {
+		return fmt.Errorf("no posts or pages found in %s", dir)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	taken, err := takenSlugs(client)
+	if err != nil {
+		return err
+	}
+	authors, err := loadAuthorsMap(client, migrateAuthorsMap)
+	if err != nil {
+		return err
+	}
+
+	posts, pages := 0, 0
+	for _, it := range site.Items {
+		if taken[it.Slug] {
+			continue
+		}
+		if it.Page {
+			pages++
+		} else {
+			posts++
+		}
+	}
+	if posts+pages > 0 {
+		if err := confirmOrAbort(fmt.Sprintf("Create %s from %s?", countPostsAndPages(posts, pages), dir), false); err != nil {
+			return err
+		}
+	}
+
+	opts, err := markdownOptions(cfg, client)
+	if err != nil {
+		return err
+	}
+	// Nothing is uploaded in a dry run, but images uploaded before resolve
+	uploadClient := client
+	if config.FlagDryRun {
+		uploadClient = nil
+	}
+	cache := loadImageCache()
+	now := time.Now()
+	unmapped := map[string]bool{}
+
+	results := make([]MigrateResult, len(site.Items))
+	failed := 0
+	for i := range site.Items {
+		it := &site.Items[i]
+		r := &results[i]
+		*r = MigrateResult{File: it.File, Type: "post", Slug: it.Slug}
+		if it.Page {
+			r.Type = "page"
+		}
+		for _, w := range it.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", it.File, w)
+		}
+		if taken[it.Slug] {
+			r.Status = "exists"
+			continue
+		}
+
+		var refs []map[string]string
+		for _, login := range it.Authors {
+			if ref, ok := authors[login]; ok {
+				refs = append(refs, ref)
+			} else if authors != nil && !unmapped[login] {
+				unmapped[login] = true
+				fmt.Fprintf(os.Stderr, "Warning: %s isn't in %s; their posts get the default author\n", login, migrateAuthorsMap)
+			}
+		}
+
+		id, err := migrateItem(client, it, opts, migrateImages(cfg, uploadClient, site, it, cache, &r.Images), refs, now)
+		switch {
+		case err != nil:
+			r.Status, r.Error = "failed", err.Error()
+			failed++
+		case config.FlagDryRun:
+			r.Status = "would create"
+		default:
+			r.Status, r.ID = "created", id
+			if !it.Page {
+				savePostBase(cfg, client, id)
+			}
+		}
+	}
+	if err := cache.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: saving image cache: %v\n", err)
+	}
+
+	if migrateRedirects != "" {
+		if err := writeMigrateRedirects(migrateRedirects, site.Items); err != nil {
+			return err
+		}
+	}
+	if err := writeReport(cmd, cfg, results, failed); err != nil {
+		return err
+	}
+	err = render(results, []output.Column[MigrateResult]{
+		{Header: "FILE", Value: func(r MigrateResult) string { return r.File }},
+		{Header: "TYPE", Value: func(r MigrateResult) string { return r.Type }},
+		{Header: "SLUG", Value: func(r MigrateResult) string { return r.Slug }},
+		{Header: "IMAGES", Value: func(r MigrateResult) string { return fmt.Sprint(r.Images) }, Wide: true},
+		{Header: "STATUS", Value: func(r MigrateResult) string { return r.Status }},
+		{Header: "ID", Value: func(r MigrateResult) string { return orDash(r.ID) }, Wide: true},
+		{Header: "ERROR", Value: func(r MigrateResult) string { return orDash(r.Error) }},
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d items could not be migrated", failed, len(site.Items))
+	}
+	return nil
+}
+
+// takenSlugs returns the slugs of the site's posts and pages
+func takenSlugs(client *api.Client) (map[string]bool, error) {
+	taken := map[string]bool{}
+	opts := &api.ListOptions{ReadOptions: api.ReadOptions{Fields: []string{"slug"}}}
+	for p, err := range client.Posts.All(context.Background(), opts) {
+		if err != nil {
+			return nil, err
+		}
+		taken[p.Slug] = true
+	}
+	for p, err := range client.Pages.All(context.Background(), opts) {
+		if err != nil {
+			return nil, err
+		}
+		taken[p.Slug] = true
+	}
+	return taken, nil
+}
+
+// migrateImages returns a resolver for the images of an item, which
+// counts them in n. Site paths such as /images/a.png are looked up in the
+// site's static files, other paths next to the item. For sites that are
+// only online, the images on the site are downloaded instead.
+func migrateImages(cfg *config.Config, client *api.Client, site *migrate.Site, it *migrate.Item, cache *imageCache, n *int) content.ImageResolver {
+	if site.URL != "" {
+		remote := remoteImageUploader(cfg, client, cache)
+		return func(dest string) (string, error) {
+			src := siteImageURL(site.URL, dest)
+			if src == "" {
+				return "", nil
+			}
+			*n++
+			return remote(src)
+		}
+	}
+
+	relative := localImageUploader(cfg, client, it.Dir, cache)
+	static := localImageUploader(cfg, client, site.StaticDir, cache)
+	return func(dest string) (string, error) {
+		if strings.Contains(dest, "://") || strings.HasPrefix(dest, "//") {
+			return "", nil
+		}
+		resolve := relative
+		if strings.HasPrefix(dest, "/") {
+			dest = strings.TrimPrefix(dest, "/")
+			if _, err := os.Stat(filepath.Join(site.StaticDir, dest)); err != nil {
+				return "", nil
+			}
+			resolve = static
+		}
+		*n++
+		return resolve(dest)
+	}
+}
+
+// siteImageURL returns the URL of an image on the site at siteURL, or ""
+// if it is elsewhere
+func siteImageURL(siteURL, dest string) string {
+	site, err := url.Parse(siteURL)
+	if err != nil {
+		return ""
+	}
+	u, err := site.Parse(dest)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	if strings.TrimPrefix(u.Host, "www.") != strings.TrimPrefix(site.Host, "www.") {
+		return ""
+	}
+	return u.String()
+}
+
+// loadAuthorsMap reads an --authors-map file, mapping logins on the other
+// platform to staff users by email, slug or ID, and returns the author
+// each login becomes. It returns nil if there is no file.
+func loadAuthorsMap(client *api.Client, path string) (map[string]map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := readFileOrStdin(path)
+	if err != nil {
+		return nil, err
+	}
+	var logins map[string]string
+	if err := yaml.Unmarshal([]byte(data), &logins); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	users, err := listAllUsers(client)
+	if err != nil {
+		return nil, err
+	}
+	authors := map[string]map[string]string{}
+	for login, ref := range logins {
+		ref = strings.TrimSpace(ref)
+		for _, u := range users {
+			if strings.EqualFold(u.Email, ref) || u.Slug == ref || u.ID == ref {
+				authors[login] = map[string]string{"id": u.ID}
+				break
+			}
+		}
+		if authors[login] == nil {
+			return nil, fmt.Errorf("%s: no staff user %s for %s", path, ref, login)
+		}
+	}
+	return authors, nil
+}
+
+// migrateItem creates the post or page for an item, returning its ID. In a
+// dry run, the item is only rendered.
+func migrateItem(client *api.Client, it *migrate.Item, opts content.Options, images content.ImageResolver, authors []map[string]string, now time.Time) (string, error) {
+	data, err := it.Markdown(now)
+	if err != nil {
+		return "", err
+	}
+	opts.ResolveImage = images
+	parsed, err := content.Parse(data, opts)
+	if err != nil {
+		return "", fmt.Errorf("parsing: %w", err)
+	}
+	for _, w := range parsed.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", it.File, w)
+	}
+
+	fm := parsed.Frontmatter
+	if fm.FeatureImg != "" {
+		resolved, err := images(fm.FeatureImg)
+		if err != nil {
+			return "", fmt.Errorf("image %s: %w", fm.FeatureImg, err)
+		}
+		fm.FeatureImg = flagOr(resolved, fm.FeatureImg)
+	}
+	if config.FlagDryRun {
+		return "", nil
+	}
+
+	item := map[string]interface{}{
+		"title":  fm.Title,
+		"slug":   fm.Slug,
+		"html":   parsed.HTML,
+		"status": flagOr(migrateStatus, fm.Status),
+	}
+	if fm.PublishedAt != "" {
+		item["published_at"] = fm.PublishedAt
+	}
+	if fm.Excerpt != "" {
+		item["custom_excerpt"] = fm.Excerpt
+	}
+	if fm.FeatureImg != "" {
+		item["feature_image"] = fm.FeatureImg
+	}
+	if len(fm.Tags) > 0 {
+		var tags []map[string]string
+		for _, t := range fm.Tags {
+			tags = append(tags, map[string]string{"name": t})
+		}
+		item["tags"] = tags
+	}
+	if len(authors) > 0 {
+		item["authors"] = authors
+	}
+
+	if it.Page {
+		page, err := client.Pages.Create(context.Background(), item, nil)
+		if err != nil {
+			return "", err
+		}
+		return page.ID, nil
+	}
+	post, err := client.Posts.Create(context.Background(), item, nil)
+	if err != nil {
+		return "", err
+	}
+	return post.ID, nil
+}
+
+// writeMigrateRedirects writes a Ghost redirects file that sends the
+// items' old paths to their new ones
+func writeMigrateRedirects(path string, items []migrate.Item) error {
+	redirects := map[string]string{}
+	for _, it := range items {
+		for _, alias := range it.Aliases {
+			redirects[alias] = "/" + it.Slug + "/"
+		}
+	}
+	data, err := yaml.Marshal(map[int]map[string]string{301: redirects})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing redirects: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s to %s\n", plural(len(redirects), "redirect"), path)
+	return nil
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import "github.com/teal-bauer/specter/api"
+
+// Resource types shared with the api package
+type (
+	Post       = api.Post
+	Page       = api.Page
+	Tag        = api.Tag
+	Member     = api.Member
+	Label      = api.Label
+	Newsletter = api.Newsletter
+	Tier       = api.Tier
+	Offer      = api.Offer
+	User       = api.User
+	Role       = api.Role
+	Pagination = api.Pagination
+)
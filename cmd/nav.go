@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/dest"
+	"gopkg.in/yaml.v3"
+)
+
+var navCmd = &cobra.Command{
+	Use:   "nav",
+	Short: "Manage site navigation",
+}
+
+var navExportCmd = &cobra.Command{
+	Use:   "export [nav.yaml]",
+	Short: "Export primary and secondary navigation to a file",
+	Long: `Export primary and secondary navigation as YAML, to a file or to stdout if
+none is given. The file can also be an s3:// or gs:// URL (see "specter help
+destinations").`,
+	Example: `  specter nav export nav.yaml
+  specter nav export s3://backups/blog/nav.yaml`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runNavExport,
+}
+
+var navImportCmd = &cobra.Command{
+	Use:   "import <nav.yaml>",
+	Short: "Replace navigation with the menus in a file",
+	Long: `Replace navigation with the menus in a YAML file. Use - to read from
+stdin. A menu that is missing from the file is left unchanged; an empty list
+clears it.
+
+File format:
+
+  primary:
+    - label: Home
+      url: /
+    - label: About
+      url: /about/
+  secondary:
+    - label: RSS
+      url: /rss/`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNavImport,
+}
+
+func init() {
+	rootCmd.AddCommand(navCmd)
+	navCmd.AddCommand(navExportCmd)
+	navCmd.AddCommand(navImportCmd)
+}
+
+// NavItem is a link in a navigation menu
+type NavItem struct {
+	Label string `json:"label" yaml:"label"`
+	URL   string `json:"url" yaml:"url"`
+}
+
+// Navigation holds the site's menus. Nil menus are left unchanged on import.
+type Navigation struct {
+	Primary   []NavItem `json:"primary" yaml:"primary"`
+	Secondary []NavItem `json:"secondary" yaml:"secondary"`
+}
+
+func runNavExport(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	settings, err := getSettings(client)
+	if err != nil {
+		return err
+	}
+
+	var nav Navigation
+	if nav.Primary, err = settingNav(settings, "navigation"); err != nil {
+		return err
+	}
+	if nav.Secondary, err = settingNav(settings, "secondary_navigation"); err != nil {
+		return err
+	}
+
+	var data []byte
+	if config.OutputFormat() == "json" {
+		data, err = json.MarshalIndent(nav, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(nav)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 || args[0] == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	err = dest.WriteTo(context.Background(), args[0], func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", args[0], err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d primary and %d secondary items to %s\n",
+		len(nav.Primary), len(nav.Secondary), args[0])
+	return nil
+}
+
+func runNavImport(cmd *cobra.Command, args []string) error {
+	data, err := readFileOrStdin(args[0])
+	if err != nil {
+		return err
+	}
+
+	var nav Navigation
+	if err := yaml.Unmarshal([]byte(data), &nav); err != nil {
+		return fmt.Errorf("parsing %s: %w", args[0], err)
+	}
+	for _, item := range append(append([]NavItem{}, nav.Primary...), nav.Secondary...) {
+		if item.Label == "" || item.URL == "" {
+			return fmt.Errorf("every navigation item needs a label and a url")
+		}
+	}
+
+	var updates []Setting
+	for key, items := range map[string][]NavItem{
+		"navigation":           nav.Primary,
+		"secondary_navigation": nav.Secondary,
+	} {
+		if items == nil {
+			continue
+		}
+		value, err := json.Marshal(items)
+		if err != nil {
+			return err
+		}
+		updates = append(updates, Setting{Key: key, Value: string(value)})
+	}
+	if len(updates) == 0 {
+		return fmt.Errorf("%s has no primary or secondary menu", args[0])
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	if _, err := updateSettings(client, updates); err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(nav)
+	}
+
+	if nav.Primary != nil {
+		fmt.Printf("Primary navigation:   %d items\n", len(nav.Primary))
+	}
+	if nav.Secondary != nil {
+		fmt.Printf("Secondary navigation: %d items\n", len(nav.Secondary))
+	}
+	return nil
+}
+
+// settingNav decodes a navigation setting, which Ghost stores as a JSON
+// string
+func settingNav(settings []Setting, key string) ([]NavItem, error) {
+	items := []NavItem{}
+	for _, s := range settings {
+		if s.Key != key || s.Value == nil {
+			continue
+		}
+		var raw []byte
+		if v, ok := s.Value.(string); ok {
+			raw = []byte(v)
+		} else {
+			var err error
+			if raw, err = json.Marshal(s.Value); err != nil {
+				return nil, err
+			}
+		}
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, fmt.Errorf("parsing %s setting: %w", key, err)
+		}
+	}
+	return items, nil
+}
@@ -1,15 +1,15 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"os"
-	"text/tabwriter"
+	"sort"
+	"strconv"
 
 	"github.com/spf13/cobra"
 	"github.com/teal-bauer/specter/api"
 	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
 )
 
 var newslettersCmd = &cobra.Command{
@@ -44,19 +44,48 @@ var newslettersUpdateCmd = &cobra.Command{
 	RunE:  runNewslettersUpdate,
 }
 
+var newslettersArchiveCmd = &cobra.Command{
+	Use:   "archive <id-or-slug>",
+	Short: "Archive a newsletter",
+	Long:  "Archive a newsletter. Ghost doesn't allow deleting newsletters; archived newsletters stop sending and are hidden from members.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runNewslettersArchive,
+}
+
+var newslettersActivateCmd = &cobra.Command{
+	Use:   "activate <id-or-slug>",
+	Short: "Reactivate an archived newsletter",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runNewslettersActivate,
+}
+
+var newslettersReorderCmd = &cobra.Command{
+	Use:   "reorder <id-or-slug>...",
+	Short: "Set the display order of newsletters",
+	Long: `Set the display order of newsletters. Newsletters are given sort_order
+0, 1, 2, ... in the order listed; unlisted newsletters are placed after them
+in their current order.
+
+Example:
+  specter newsletters reorder weekly announcements digest`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runNewslettersReorder,
+}
+
 var (
-	nlSlug           string
-	nlDescription    string
-	nlSenderName     string
-	nlSenderEmail    string
-	nlSenderReplyTo  string
-	nlStatus         string
+	nlSlug              string
+	nlDescription       string
+	nlSenderName        string
+	nlSenderEmail       string
+	nlSenderReplyTo     string
+	nlStatus            string
 	nlSubscribeOnSignup string
-	nlTitleFont      string
-	nlBodyFont       string
-	nlShowHeaderIcon string
-	nlShowHeaderTitle string
-	nlShowHeaderName string
+	nlTitleFont         string
+	nlBodyFont          string
+	nlShowHeaderIcon    string
+	nlShowHeaderTitle   string
+	nlShowHeaderName    string
+	nlVisibility        string
 )
 
 func init() {
@@ -65,12 +94,18 @@ func init() {
 	newslettersCmd.AddCommand(newslettersGetCmd)
 	newslettersCmd.AddCommand(newslettersCreateCmd)
 	newslettersCmd.AddCommand(newslettersUpdateCmd)
+	newslettersCmd.AddCommand(newslettersArchiveCmd)
+	newslettersCmd.AddCommand(newslettersActivateCmd)
+	newslettersCmd.AddCommand(newslettersReorderCmd)
+
+	addExecFlag(newslettersListCmd)
 
 	newslettersCreateCmd.Flags().StringVar(&nlSlug, "slug", "", "Newsletter slug")
 	newslettersCreateCmd.Flags().StringVar(&nlDescription, "description", "", "Newsletter description")
 	newslettersCreateCmd.Flags().StringVar(&nlSenderName, "sender-name", "", "Sender name")
 	newslettersCreateCmd.Flags().StringVar(&nlSenderEmail, "sender-email", "", "Sender email")
 	newslettersCreateCmd.Flags().StringVar(&nlSenderReplyTo, "reply-to", "", "Reply-to address")
+	newslettersCreateCmd.Flags().StringVar(&nlVisibility, "visibility", "", "Who can subscribe: members or paid")
 
 	newslettersUpdateCmd.Flags().StringVar(&nlSlug, "slug", "", "Update newsletter slug")
 	newslettersUpdateCmd.Flags().StringVar(&nlDescription, "description", "", "Update description")
@@ -78,6 +113,7 @@ func init() {
 	newslettersUpdateCmd.Flags().StringVar(&nlSenderEmail, "sender-email", "", "Update sender email")
 	newslettersUpdateCmd.Flags().StringVar(&nlSenderReplyTo, "reply-to", "", "Update reply-to")
 	newslettersUpdateCmd.Flags().StringVar(&nlStatus, "status", "", "Update status (active/archived)")
+	newslettersUpdateCmd.Flags().StringVar(&nlVisibility, "visibility", "", "Update who can subscribe: members or paid")
 	newslettersUpdateCmd.Flags().StringVar(&nlSubscribeOnSignup, "subscribe-on-signup", "", "Subscribe on signup (true/false)")
 	newslettersUpdateCmd.Flags().StringVar(&nlTitleFont, "title-font", "", "Title font")
 	newslettersUpdateCmd.Flags().StringVar(&nlBodyFont, "body-font", "", "Body font")
@@ -86,69 +122,24 @@ func init() {
 	newslettersUpdateCmd.Flags().StringVar(&nlShowHeaderName, "show-header-name", "", "Show header name (true/false)")
 }
 
-type Newsletter struct {
-	ID                string `json:"id"`
-	Name              string `json:"name"`
-	Slug              string `json:"slug"`
-	Description       string `json:"description,omitempty"`
-	SenderName        string `json:"sender_name,omitempty"`
-	SenderEmail       string `json:"sender_email,omitempty"`
-	SenderReplyTo     string `json:"sender_reply_to,omitempty"`
-	Status            string `json:"status"`
-	Visibility        string `json:"visibility"`
-	SubscribeOnSignup bool   `json:"subscribe_on_signup"`
-	SortOrder         int    `json:"sort_order"`
-	CreatedAt         string `json:"created_at"`
-	UpdatedAt         string `json:"updated_at"`
-	TitleFont         string `json:"title_font_category,omitempty"`
-	BodyFont          string `json:"body_font_category,omitempty"`
-	ShowHeaderIcon    bool   `json:"show_header_icon"`
-	ShowHeaderTitle   bool   `json:"show_header_title"`
-	ShowHeaderName    bool   `json:"show_header_name"`
-}
-
-type newslettersResponse struct {
-	Newsletters []Newsletter `json:"newsletters"`
-	Meta        struct {
-		Pagination struct {
-			Page  int `json:"page"`
-			Limit int `json:"limit"`
-			Pages int `json:"pages"`
-			Total int `json:"total"`
-			Next  int `json:"next"`
-		} `json:"pagination"`
-	} `json:"meta"`
-}
-
 func runNewslettersList(cmd *cobra.Command, args []string) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
-	data, err := client.Get("/newsletters/", nil)
+	newsletters, err := listAllNewsletters(client)
 	if err != nil {
 		return err
 	}
 
-	var resp newslettersResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
-
-	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(resp.Newsletters)
-	}
-
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tNAME\tSTATUS\tSUBSCRIBE ON SIGNUP")
-	for _, n := range resp.Newsletters {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%v\n", n.ID, n.Name, n.Status, n.SubscribeOnSignup)
-	}
-	return w.Flush()
+	return render(newsletters, []output.Column[Newsletter]{
+		{Header: "ID", Value: func(n Newsletter) string { return n.ID }},
+		{Header: "NAME", Value: func(n Newsletter) string { return n.Name }},
+		{Header: "STATUS", Value: func(n Newsletter) string { return n.Status }},
+		{Header: "SUBSCRIBE ON SIGNUP", Value: func(n Newsletter) string { return strconv.FormatBool(n.SubscribeOnSignup) }},
+	})
 }
 
 func runNewslettersGet(cmd *cobra.Command, args []string) error {
@@ -156,7 +147,7 @@ func runNewslettersGet(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	nl, err := getNewsletter(client, args[0])
 	if err != nil {
@@ -164,15 +155,14 @@ func runNewslettersGet(cmd *cobra.Command, args []string) error {
 	}
 
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(nl)
+		return printJSON(nl)
 	}
 
 	fmt.Printf("ID:               %s\n", nl.ID)
 	fmt.Printf("Name:             %s\n", nl.Name)
 	fmt.Printf("Slug:             %s\n", nl.Slug)
 	fmt.Printf("Status:           %s\n", nl.Status)
+	fmt.Printf("Visibility:       %s\n", nl.Visibility)
 	if nl.Description != "" {
 		fmt.Printf("Description:      %s\n", nl.Description)
 	}
@@ -191,7 +181,7 @@ func runNewslettersCreate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	nl := map[string]interface{}{
 		"name": args[0],
@@ -212,31 +202,17 @@ func runNewslettersCreate(cmd *cobra.Command, args []string) error {
 	if nlSenderReplyTo != "" {
 		nl["sender_reply_to"] = nlSenderReplyTo
 	}
-
-	body := map[string]interface{}{
-		"newsletters": []interface{}{nl},
+	if nlVisibility != "" {
+		nl["visibility"] = nlVisibility
 	}
 
-	data, err := client.Post("/newsletters/", body)
+	created, err := client.Newsletters.Create(context.Background(), nl, nil)
 	if err != nil {
 		return err
 	}
 
-	var resp newslettersResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
-
-	if len(resp.Newsletters) == 0 {
-		return fmt.Errorf("no newsletter in response")
-	}
-
-	created := resp.Newsletters[0]
-
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(created)
+		return printJSON(created)
 	}
 
 	fmt.Printf("Created newsletter: %s\n", created.Name)
@@ -250,7 +226,7 @@ func runNewslettersUpdate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	existing, err := getNewsletter(client, args[0])
 	if err != nil {
@@ -277,6 +253,9 @@ func runNewslettersUpdate(cmd *cobra.Command, args []string) error {
 	if nlStatus != "" {
 		nl["status"] = nlStatus
 	}
+	if nlVisibility != "" {
+		nl["visibility"] = nlVisibility
+	}
 	if nlSubscribeOnSignup != "" {
 		nl["subscribe_on_signup"] = nlSubscribeOnSignup == "true"
 	}
@@ -300,61 +279,130 @@ func runNewslettersUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no updates specified")
 	}
 
-	body := map[string]interface{}{
-		"newsletters": []interface{}{nl},
+	updated, err := updateNewsletter(client, existing.ID, nl)
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(updated)
 	}
 
-	data, err := client.Put(fmt.Sprintf("/newsletters/%s/", existing.ID), body)
+	fmt.Printf("Updated newsletter: %s\n", updated.Name)
+	fmt.Printf("  ID: %s\n", updated.ID)
+	return nil
+}
+
+func runNewslettersArchive(cmd *cobra.Command, args []string) error {
+	return setNewsletterStatus(args[0], "archived")
+}
+
+func runNewslettersActivate(cmd *cobra.Command, args []string) error {
+	return setNewsletterStatus(args[0], "active")
+}
+
+func setNewsletterStatus(idOrSlug, status string) error {
+	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
+	client := newClient(cfg)
 
-	var resp newslettersResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
+	existing, err := getNewsletter(client, idOrSlug)
+	if err != nil {
+		return err
 	}
 
-	if len(resp.Newsletters) == 0 {
-		return fmt.Errorf("no newsletter in response")
+	updated, err := updateNewsletter(client, existing.ID, map[string]interface{}{"status": status})
+	if err != nil {
+		return err
 	}
 
-	updated := resp.Newsletters[0]
-
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(updated)
+		return printJSON(updated)
 	}
 
-	fmt.Printf("Updated newsletter: %s\n", updated.Name)
-	fmt.Printf("  ID: %s\n", updated.ID)
+	fmt.Printf("Newsletter %s is now %s\n", updated.Name, updated.Status)
 	return nil
 }
 
-func getNewsletter(client *api.Client, idOrSlug string) (*Newsletter, error) {
-	data, err := client.Get(fmt.Sprintf("/newsletters/%s/", idOrSlug), nil)
-	if err == nil {
-		var resp newslettersResponse
-		if err := json.Unmarshal(data, &resp); err == nil && len(resp.Newsletters) > 0 {
-			return &resp.Newsletters[0], nil
-		}
+func runNewslettersReorder(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
 	}
+	client := newClient(cfg)
 
-	params := url.Values{}
-	params.Set("filter", fmt.Sprintf("slug:%s", idOrSlug))
-	data, err = client.Get("/newsletters/", params)
+	newsletters, err := listAllNewsletters(client)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	sort.SliceStable(newsletters, func(i, j int) bool {
+		return newsletters[i].SortOrder < newsletters[j].SortOrder
+	})
+
+	// Listed newsletters first, in the order given, then the rest
+	var ordered []Newsletter
+	seen := map[string]bool{}
+	for _, arg := range args {
+		found := false
+		for _, n := range newsletters {
+			if n.ID == arg || n.Slug == arg {
+				if seen[n.ID] {
+					return fmt.Errorf("newsletter listed twice: %s", arg)
+				}
+				ordered = append(ordered, n)
+				seen[n.ID] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("newsletter not found: %s", arg)
+		}
+	}
+	for _, n := range newsletters {
+		if !seen[n.ID] {
+			ordered = append(ordered, n)
+		}
 	}
 
-	var resp newslettersResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return nil, fmt.Errorf("parsing response: %w", err)
+	var result []Newsletter
+	for i, n := range ordered {
+		if n.SortOrder != i {
+			updated, err := updateNewsletter(client, n.ID, map[string]interface{}{"sort_order": i})
+			if err != nil {
+				return fmt.Errorf("updating %s: %w", n.Slug, err)
+			}
+			n = *updated
+		}
+		result = append(result, n)
 	}
 
-	if len(resp.Newsletters) == 0 {
-		return nil, fmt.Errorf("newsletter not found: %s", idOrSlug)
+	return render(result, []output.Column[Newsletter]{
+		{Header: "ORDER", Value: func(n Newsletter) string { return strconv.Itoa(n.SortOrder) }},
+		{Header: "SLUG", Value: func(n Newsletter) string { return n.Slug }},
+		{Header: "NAME", Value: func(n Newsletter) string { return n.Name }},
+		{Header: "STATUS", Value: func(n Newsletter) string { return n.Status }},
+	})
+}
+
+func updateNewsletter(client *api.Client, id string, nl map[string]interface{}) (*Newsletter, error) {
+	return client.Newsletters.Update(context.Background(), id, nl, nil)
+}
+
+// listAllNewsletters returns every newsletter of the site
+func listAllNewsletters(client *api.Client) ([]Newsletter, error) {
+	var newsletters []Newsletter
+	for n, err := range client.Newsletters.All(context.Background(), nil) {
+		if err != nil {
+			return nil, err
+		}
+		newsletters = append(newsletters, n)
 	}
+	return newsletters, nil
+}
 
-	return &resp.Newsletters[0], nil
+func getNewsletter(client *api.Client, idOrSlug string) (*Newsletter, error) {
+	return client.Newsletters.Lookup(context.Background(), idOrSlug, nil)
 }
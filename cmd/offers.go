@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var offersCmd = &cobra.Command{
+	Use:   "offers",
+	Short: "List offers and print their signup links",
+}
+
+var offersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List offers",
+	RunE:  runOffersList,
+}
+
+var offersURLCmd = &cobra.Command{
+	Use:   "url <id-or-code>",
+	Short: "Print the link that redeems an offer",
+	Long: `Print the shareable link of an offer, made from its code, and the portal
+link that opens the offer in place on the site (#/portal/offers/<id>).
+-o json prints both, with the value for a data-portal attribute.`,
+	Example: `  specter offers url black-friday
+  specter offers url black-friday -o json | jq -r .data_portal`,
+	Args: cobra.ExactArgs(1),
+	RunE: runOffersURL,
+}
+
+var offersQuery listQuery
+
+func init() {
+	rootCmd.AddCommand(offersCmd)
+	offersCmd.AddCommand(offersListCmd)
+	offersCmd.AddCommand(offersURLCmd)
+
+	offersQuery.addFlags(offersListCmd, "status:active")
+	addExecFlag(offersListCmd)
+}
+
+func runOffersList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	var offers []Offer
+	for o, err := range client.Offers.All(context.Background(), offersQuery.options("")) {
+		if err != nil {
+			return err
+		}
+		offers = append(offers, o)
+	}
+
+	return render(offers, []output.Column[Offer]{
+		{Header: "ID", Value: func(o Offer) string { return o.ID }},
+		{Header: "NAME", Value: func(o Offer) string { return o.Name }},
+		{Header: "CODE", Value: func(o Offer) string { return o.Code }},
+		{Header: "STATUS", Value: func(o Offer) string { return o.Status }},
+		{Header: "TIER", Value: func(o Offer) string {
+			if o.Tier == nil {
+				return "-"
+			}
+			return o.Tier.Name
+		}},
+		{Header: "DISCOUNT", Value: offerDiscount},
+		{Header: "CADENCE", Value: func(o Offer) string { return o.Cadence }, Wide: true},
+		{Header: "DURATION", Value: func(o Offer) string { return o.Duration }, Wide: true},
+	})
+}
+
+// offerDiscount describes what an offer gives: a percentage or amount off,
+// or free trial days
+func offerDiscount(o Offer) string {
+	switch o.Type {
+	case "percent":
+		return fmt.Sprintf("%d%% off", o.Amount)
+	case "fixed":
+		return fmt.Sprintf("%d %s off", o.Amount, o.Currency)
+	case "trial":
+		return fmt.Sprintf("%d days free", o.Amount)
+	}
+	return orDash(o.Type)
+}
+
+func runOffersURL(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	offer, err := client.Offers.Lookup(context.Background(), args[0], nil)
+	if err != nil {
+		return err
+	}
+	siteURL, err := portalSiteURL(client, cfg)
+	if err != nil {
+		return err
+	}
+	if offer.Status != "active" {
+		fmt.Fprintf(os.Stderr, "Warning: offer %q is %s, so its link won't work\n", offer.Name, offer.Status)
+	}
+
+	link := offerLink(siteURL, *offer)
+	if config.OutputFormat() == "json" {
+		return printJSON(link)
+	}
+	fmt.Println(link.URL)
+	fmt.Println(portalLink(siteURL, "", link.Portal).URL)
+	return nil
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+)
+
+// openFlags are --open and --copy-url, which hand a post or page over for
+// review right after it is written
+type openFlags struct {
+	open    string
+	copyURL bool
+}
+
+// writeOpenFlags is shared by the commands that create and update posts
+// and pages
+var writeOpenFlags openFlags
+
+func (o *openFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.open, "open", "", "Open the result in the browser: preview (the default) or --open=editor")
+	cmd.Flags().Lookup("open").NoOptDefVal = "preview"
+	cmd.Flags().BoolVar(&o.copyURL, "copy-url", false, "Copy the URL of the result to the clipboard")
+}
+
+// validate checks --open before anything is written
+func (o *openFlags) validate() error {
+	if o.open != "" && o.open != "preview" && o.open != "editor" {
+		return fmt.Errorf("--open must be preview or editor, not %q", o.open)
+	}
+	return nil
+}
+
+// run opens and copies the URLs of the post or page (kind) with the given
+// ID and URL, which Ghost gives drafts as their preview link. It is
+// already written, so failures are only warned about.
+func (o *openFlags) run(cfg *config.Config, kind, id, url string) {
+	if url == "" && (o.copyURL || o.open == "preview") {
+		fmt.Fprintf(os.Stderr, "Warning: no URL for the %s in the response\n", kind)
+	} else if o.copyURL {
+		if err := copyToClipboard(url); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: can't copy the URL: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Copied %s to the clipboard\n", url)
+		}
+	}
+	if o.open == "editor" {
+		url = strings.TrimSuffix(cfg.URL, "/") + "/ghost/#/editor/" + kind + "/" + id
+	}
+	if o.open == "" || url == "" {
+		return
+	}
+	if err := openBrowser(url); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: can't open %s: %v\n", url, err)
+	}
+}
+
+// copyToClipboard puts text on the clipboard with the platform's clipboard
+// tool
+func copyToClipboard(text string) error {
+	var tools [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		tools = [][]string{{"pbcopy"}}
+	case "windows":
+		tools = [][]string{{"clip"}}
+	default:
+		tools = [][]string{{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	}
+	var tried []string
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool[0]); err != nil {
+			tried = append(tried, tool[0])
+			continue
+		}
+		cmd := exec.Command(tool[0], tool[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return fmt.Errorf("no clipboard tool found (tried %s)", strings.Join(tried, ", "))
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+// outputOptions returns the rendering options set by the global flags
+func outputOptions() output.Options {
+	return output.Options{
+		Format:    config.OutputFormat(),
+		NoHeaders: config.FlagNoHeaders,
+		Wide:      config.FlagWide,
+	}
+}
+
+// render writes items to stdout as a table, JSON, CSV or template output,
+// or runs --exec for each of them
+func render[T any](items []T, columns []output.Column[T]) error {
+	if execCommand != "" {
+		return execEach(items)
+	}
+	return output.Render(os.Stdout, items, columns, outputOptions())
+}
+
+// streaming reports whether list commands should write each page of results
+// as soon as it is fetched instead of collecting them first
+func streaming() bool {
+	return config.OutputFormat() == "ndjson" || execCommand != ""
+}
+
+// streamItems writes one page of results as NDJSON, or runs --exec for each
+// of them
+func streamItems[T any](items []T) error {
+	if execCommand != "" {
+		return execEach(items)
+	}
+	return output.NDJSON(os.Stdout, items)
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	return output.JSON(os.Stdout, v)
+}
@@ -1,17 +1,17 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/url"
 	"os"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/teal-bauer/specter/api"
 	"github.com/teal-bauer/specter/internal/config"
 	"github.com/teal-bauer/specter/internal/content"
+	"github.com/teal-bauer/specter/internal/output"
 )
 
 var pagesCmd = &cobra.Command{
@@ -58,6 +58,11 @@ var (
 	pagesPage   int
 	pagesAll    bool
 	pagesStatus string
+	pagesAuthor string
+	pagesSince  string
+	pagesUntil  string
+	pagesQuery  listQuery
+	pagesForce  bool
 )
 
 func init() {
@@ -68,41 +73,25 @@ func init() {
 	pagesCmd.AddCommand(pagesUpdateCmd)
 	pagesCmd.AddCommand(pagesDeleteCmd)
 
+	pagesDeleteCmd.Flags().BoolVar(&pagesForce, "force", false, "Delete without asking for confirmation")
+
 	pagesListCmd.Flags().IntVar(&pagesLimit, "limit", 15, "Number of pages to return")
 	pagesListCmd.Flags().IntVar(&pagesPage, "page", 1, "Page number")
 	pagesListCmd.Flags().BoolVar(&pagesAll, "all", false, "Fetch all pages")
+	pagesListCmd.Flags().StringVar(&pagesAuthor, "author", "", "Only list pages by this author (slug)")
+	pagesListCmd.Flags().StringVar(&pagesSince, "since", "", "Only list pages published since this date or duration ago (e.g. 2025-01-01, 30d)")
+	pagesListCmd.Flags().StringVar(&pagesUntil, "until", "", "Only list pages published until this date or duration ago")
+	pagesQuery.addFlags(pagesListCmd, "featured:true")
+	addExecFlag(pagesListCmd)
 
 	pagesCreateCmd.Flags().StringVar(&pagesStatus, "status", "", "Page status: draft or published")
 	pagesUpdateCmd.Flags().StringVar(&pagesStatus, "status", "", "Update page status")
-}
-
-type Page struct {
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	Slug        string `json:"slug"`
-	HTML        string `json:"html,omitempty"`
-	Status      string `json:"status"`
-	Featured    bool   `json:"featured"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
-	PublishedAt string `json:"published_at,omitempty"`
-	URL         string `json:"url,omitempty"`
-	FeatureImg  string `json:"feature_image,omitempty"`
-	Tags        []Tag  `json:"tags,omitempty"`
-}
-
-type pagesResponse struct {
-	Pages []Page `json:"pages"`
-	Meta  struct {
-		Pagination struct {
-			Page  int `json:"page"`
-			Limit int `json:"limit"`
-			Pages int `json:"pages"`
-			Total int `json:"total"`
-			Next  int `json:"next"`
-			Prev  int `json:"prev"`
-		} `json:"pagination"`
-	} `json:"meta"`
+	contentVarsFlags.addFlags(pagesCreateCmd)
+	markdownOptionFlags.addFlags(pagesCreateCmd)
+	writeOpenFlags.addFlags(pagesCreateCmd)
+	contentVarsFlags.addFlags(pagesUpdateCmd)
+	markdownOptionFlags.addFlags(pagesUpdateCmd)
+	writeOpenFlags.addFlags(pagesUpdateCmd)
 }
 
 func runPagesList(cmd *cobra.Command, args []string) error {
@@ -110,73 +99,60 @@ func runPagesList(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
+
+	dates, err := dateRangeFilter("published_at", pagesSince, pagesUntil)
+	if err != nil {
+		return err
+	}
+	filter := joinFilters(authorFilter(pagesAuthor), dates)
+
+	var include []string
+	if pagesAuthor != "" {
+		include = append(include, "authors")
+	}
+	opts := pagesQuery.options(filter, include...)
 
 	var allPages []Page
+	var pagination *Pagination
 
 	if pagesAll {
-		page := 1
-		for {
-			params := url.Values{}
-			params.Set("limit", "100")
-			params.Set("page", fmt.Sprintf("%d", page))
-
-			data, err := client.Get("/pages/", params)
+		for p, err := range client.Pages.All(context.Background(), opts) {
 			if err != nil {
 				return err
 			}
-
-			var resp pagesResponse
-			if err := json.Unmarshal(data, &resp); err != nil {
-				return fmt.Errorf("parsing response: %w", err)
+			if streaming() {
+				if err := streamItems([]Page{p}); err != nil {
+					return err
+				}
+			} else {
+				allPages = append(allPages, p)
 			}
-
-			allPages = append(allPages, resp.Pages...)
-
-			if resp.Meta.Pagination.Next == 0 {
-				break
-			}
-			page = resp.Meta.Pagination.Next
 		}
 	} else {
-		params := url.Values{}
-		params.Set("limit", fmt.Sprintf("%d", pagesLimit))
-		params.Set("page", fmt.Sprintf("%d", pagesPage))
-
-		data, err := client.Get("/pages/", params)
+		opts.Limit, opts.Page = pagesLimit, pagesPage
+		list, err := client.Pages.List(context.Background(), opts)
 		if err != nil {
 			return err
 		}
-
-		var resp pagesResponse
-		if err := json.Unmarshal(data, &resp); err != nil {
-			return fmt.Errorf("parsing response: %w", err)
-		}
-		allPages = resp.Pages
+		allPages = list.Items
+		pagination = &list.Pagination
 	}
 
-	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(allPages)
-	}
-
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tTITLE\tSTATUS\tPUBLISHED")
-	for _, p := range allPages {
-		published := p.PublishedAt
-		if published == "" {
-			published = "-"
-		} else if len(published) > 10 {
-			published = published[:10]
-		}
-		title := p.Title
-		if len(title) > 50 {
-			title = title[:47] + "..."
-		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.ID, title, p.Status, published)
+	if pagination != nil && config.OutputFormat() == "json" {
+		return encodePage("pages", allPages, *pagination)
 	}
-	return w.Flush()
+	return render(allPages, pageColumns)
+}
+
+// pageColumns are the columns of the pages list
+var pageColumns = []output.Column[Page]{
+	{Header: "ID", Value: func(p Page) string { return p.ID }},
+	{Header: "TITLE", Value: func(p Page) string { return p.Title }, Width: 50},
+	{Header: "STATUS", Value: func(p Page) string { return p.Status }},
+	{Header: "PUBLISHED", Value: func(p Page) string { return orDash(truncateDate(p.PublishedAt)) }},
+	{Header: "SLUG", Value: func(p Page) string { return p.Slug }, Wide: true},
+	{Header: "URL", Value: func(p Page) string { return p.URL }, Wide: true},
 }
 
 func runPagesGet(cmd *cobra.Command, args []string) error {
@@ -184,7 +160,7 @@ func runPagesGet(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	page, err := getPage(client, args[0])
 	if err != nil {
@@ -192,9 +168,7 @@ func runPagesGet(cmd *cobra.Command, args []string) error {
 	}
 
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(page)
+		return printJSON(page)
 	}
 
 	fmt.Printf("ID:        %s\n", page.ID)
@@ -216,16 +190,24 @@ func runPagesGet(cmd *cobra.Command, args []string) error {
 }
 
 func runPagesCreate(cmd *cobra.Command, args []string) error {
+	if err := writeOpenFlags.validate(); err != nil {
+		return err
+	}
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
-	parsed, err := content.ParseFile(args[0])
+	opts, err := markdownOptions(cfg, client)
+	if err != nil {
+		return err
+	}
+	parsed, err := content.ParseFile(args[0], opts)
 	if err != nil {
 		return fmt.Errorf("parsing file: %w", err)
 	}
+	printWarnings(parsed.Warnings)
 
 	page := map[string]interface{}{
 		"title": parsed.Frontmatter.Title,
@@ -238,16 +220,19 @@ func runPagesCreate(cmd *cobra.Command, args []string) error {
 	if parsed.Frontmatter.FeatureImg != "" {
 		page["feature_image"] = parsed.Frontmatter.FeatureImg
 	}
+	if parsed.Frontmatter.Visibility != "" {
+		page["visibility"] = parsed.Frontmatter.Visibility
+	}
 	if parsed.Frontmatter.Featured {
 		page["featured"] = true
 	}
-
-	status := "draft"
-	if parsed.Frontmatter.Status != "" {
-		status = parsed.Frontmatter.Status
+	if err := applyPostMeta(client, page, parsed.Frontmatter); err != nil {
+		return err
 	}
-	if pagesStatus != "" {
-		status = pagesStatus
+
+	status := flagOr(pagesStatus, flagOr(parsed.Frontmatter.Status, cfg.DefaultStatus))
+	if status == "" {
+		status = "draft"
 	}
 	page["status"] = status
 
@@ -259,30 +244,14 @@ func runPagesCreate(cmd *cobra.Command, args []string) error {
 		page["tags"] = tags
 	}
 
-	body := map[string]interface{}{
-		"pages": []interface{}{page},
-	}
-
-	data, err := client.Post("/pages/", body)
+	created, err := client.Pages.Create(context.Background(), page, nil)
 	if err != nil {
 		return err
 	}
-
-	var resp pagesResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
-
-	if len(resp.Pages) == 0 {
-		return fmt.Errorf("no page in response")
-	}
-
-	created := resp.Pages[0]
+	writeOpenFlags.run(cfg, "page", created.ID, created.URL)
 
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(created)
+		return printJSON(created)
 	}
 
 	fmt.Printf("Created page: %s\n", created.Title)
@@ -293,11 +262,14 @@ func runPagesCreate(cmd *cobra.Command, args []string) error {
 }
 
 func runPagesUpdate(cmd *cobra.Command, args []string) error {
+	if err := writeOpenFlags.validate(); err != nil {
+		return err
+	}
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	existing, err := getPage(client, args[0])
 	if err != nil {
@@ -309,10 +281,15 @@ func runPagesUpdate(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(args) > 1 {
-		parsed, err := content.ParseFile(args[1])
+		opts, err := markdownOptions(cfg, client)
+		if err != nil {
+			return err
+		}
+		parsed, err := content.ParseFile(args[1], opts)
 		if err != nil {
 			return fmt.Errorf("parsing file: %w", err)
 		}
+		printWarnings(parsed.Warnings)
 
 		if parsed.Frontmatter.Title != "" {
 			page["title"] = parsed.Frontmatter.Title
@@ -325,6 +302,9 @@ func runPagesUpdate(cmd *cobra.Command, args []string) error {
 		if parsed.Frontmatter.FeatureImg != "" {
 			page["feature_image"] = parsed.Frontmatter.FeatureImg
 		}
+		if parsed.Frontmatter.Visibility != "" {
+			page["visibility"] = parsed.Frontmatter.Visibility
+		}
 		page["featured"] = parsed.Frontmatter.Featured
 
 		if parsed.Frontmatter.Status != "" && pagesStatus == "" {
@@ -338,36 +318,23 @@ func runPagesUpdate(cmd *cobra.Command, args []string) error {
 			}
 			page["tags"] = tags
 		}
+		if err := applyPostMeta(client, page, parsed.Frontmatter); err != nil {
+			return err
+		}
 	}
 
 	if pagesStatus != "" {
 		page["status"] = pagesStatus
 	}
 
-	body := map[string]interface{}{
-		"pages": []interface{}{page},
-	}
-
-	data, err := client.Put(fmt.Sprintf("/pages/%s/", existing.ID), body)
+	updated, err := client.Pages.Update(context.Background(), existing.ID, page, nil)
 	if err != nil {
 		return err
 	}
-
-	var resp pagesResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
-
-	if len(resp.Pages) == 0 {
-		return fmt.Errorf("no page in response")
-	}
-
-	updated := resp.Pages[0]
+	writeOpenFlags.run(cfg, "page", updated.ID, updated.URL)
 
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(updated)
+		return printJSON(updated)
 	}
 
 	fmt.Printf("Updated page: %s\n", updated.Title)
@@ -381,20 +348,32 @@ func runPagesDelete(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	existing, err := getPage(client, args[0])
 	if err != nil {
 		return err
 	}
 
-	_, err = client.Delete(fmt.Sprintf("/pages/%s/", existing.ID))
-	if err != nil {
+	prompt := fmt.Sprintf("Delete page '%s' (%s)?", existing.Title, existing.ID)
+	if confirming(pagesForce) {
+		links, err := pageNavLinks(client, existing)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: can't check the navigation for links to this page: %v\n", err)
+		} else if len(links) > 0 {
+			prompt = fmt.Sprintf("Delete page '%s' (%s)? It is linked from %s, which will break.", existing.Title, existing.ID, strings.Join(links, " and "))
+		}
+	}
+	if err := confirmOrAbort(prompt, pagesForce); err != nil {
+		return err
+	}
+
+	if err := client.Pages.Delete(context.Background(), existing.ID); err != nil {
 		return err
 	}
 
 	if config.OutputFormat() == "json" {
-		return json.NewEncoder(os.Stdout).Encode(map[string]string{
+		return printJSON(map[string]string{
 			"deleted": existing.ID,
 			"title":   existing.Title,
 		})
@@ -404,30 +383,42 @@ func runPagesDelete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func getPage(client *api.Client, idOrSlug string) (*Page, error) {
-	data, err := client.Get(fmt.Sprintf("/pages/%s/", idOrSlug), nil)
-	if err == nil {
-		var resp pagesResponse
-		if err := json.Unmarshal(data, &resp); err == nil && len(resp.Pages) > 0 {
-			return &resp.Pages[0], nil
-		}
-	}
-
-	params := url.Values{}
-	params.Set("filter", fmt.Sprintf("slug:%s", idOrSlug))
-	data, err = client.Get("/pages/", params)
+// pageNavLinks describes the navigation items that link to page, e.g.
+// `the primary navigation as "About"`
+func pageNavLinks(client *api.Client, page *Page) ([]string, error) {
+	settings, err := getSettings(client)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp pagesResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return nil, fmt.Errorf("parsing response: %w", err)
+	// Navigation URLs are absolute or site-relative, so compare paths
+	paths := map[string]bool{"/" + page.Slug: true}
+	host := ""
+	if u, err := url.Parse(page.URL); err == nil && u.Path != "" {
+		paths[strings.TrimSuffix(u.Path, "/")] = true
+		host = u.Host
 	}
 
-	if len(resp.Pages) == 0 {
-		return nil, fmt.Errorf("page not found: %s", idOrSlug)
+	var links []string
+	for _, menu := range []struct{ key, name string }{
+		{"navigation", "primary"},
+		{"secondary_navigation", "secondary"},
+	} {
+		items, err := settingNav(settings, menu.key)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			u, err := url.Parse(item.URL)
+			if err != nil || (u.Host != "" && u.Host != host) || !paths[strings.TrimSuffix(u.Path, "/")] {
+				continue
+			}
+			links = append(links, fmt.Sprintf("the %s navigation as %q", menu.name, item.Label))
+		}
 	}
+	return links, nil
+}
 
-	return &resp.Pages[0], nil
+func getPage(client *api.Client, idOrSlug string) (*Page, error) {
+	return client.Pages.Lookup(context.Background(), idOrSlug, nil)
 }
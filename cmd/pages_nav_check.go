@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var pagesNavCheckCmd = &cobra.Command{
+	Use:   "nav-check",
+	Short: "List published pages that nothing links to",
+	Long: `List published pages that aren't in the primary or secondary navigation
+and aren't linked from any published post or other page, e.g. pages left
+behind when a redesign changed the menus.
+
+Links are compared by path, so full URLs and paths such as /about/ both
+count. Links that only the theme adds, e.g. in a hard-coded footer, aren't
+seen; check the pages listed before unpublishing them.
+
+Exits with an error if any orphaned page is found.`,
+	Example: `  specter pages nav-check
+  specter pages nav-check -o json | jq -r '.[].url'`,
+	Args: cobra.NoArgs,
+	RunE: runPagesNavCheck,
+}
+
+func init() {
+	pagesCmd.AddCommand(pagesNavCheckCmd)
+}
+
+// OrphanedPage is a published page that nothing on the site links to
+type OrphanedPage struct {
+	ID          string `json:"id"`
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	PublishedAt string `json:"published_at,omitempty"`
+}
+
+func runPagesNavCheck(cmd *cobra.Command, args []string) error {
+	// Orphans found are listed, not a usage mistake
+	cmd.SilenceUsage = true
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	siteURL, err := portalSiteURL(client, cfg)
+	if err != nil {
+		return err
+	}
+	sl, err := newSiteLinks(siteURL, cfg.URL)
+	if err != nil {
+		return err
+	}
+
+	settings, err := getSettings(client)
+	if err != nil {
+		return err
+	}
+	for _, key := range []string{"navigation", "secondary_navigation"} {
+		items, err := settingNav(settings, key)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			sl.add(item.URL, "")
+		}
+	}
+
+	posts := 0
+	opts := &api.ListOptions{
+		Filter:      "status:published",
+		ReadOptions: api.ReadOptions{Fields: []string{"id", "slug", "url", "html"}, Formats: []string{"html"}},
+	}
+	for p, err := range client.Posts.All(context.Background(), opts) {
+		if err != nil {
+			return err
+		}
+		sl.addHTML(p.HTML, p.URL)
+		posts++
+	}
+
+	var pages []api.Page
+	opts.ReadOptions.Fields = []string{"id", "slug", "title", "url", "html", "published_at"}
+	for p, err := range client.Pages.All(context.Background(), opts) {
+		if err != nil {
+			return err
+		}
+		sl.addHTML(p.HTML, p.URL)
+		pages = append(pages, p)
+	}
+
+	orphans := []OrphanedPage{}
+	for _, p := range pages {
+		if !sl.linked[sitemapPath(p.URL)] {
+			orphans = append(orphans, OrphanedPage{ID: p.ID, Slug: p.Slug, Title: p.Title, URL: p.URL, PublishedAt: p.PublishedAt})
+		}
+	}
+
+	if len(orphans) == 0 {
+		fmt.Fprintf(os.Stderr, "No orphaned pages: all %s are linked from the navigation, %s or other pages.\n", plural(len(pages), "published page"), plural(posts, "post"))
+		if config.OutputFormat() == "json" {
+			return printJSON(orphans)
+		}
+		return nil
+	}
+	err = render(orphans, []output.Column[OrphanedPage]{
+		{Header: "ID", Value: func(r OrphanedPage) string { return r.ID }, Wide: true},
+		{Header: "SLUG", Value: func(r OrphanedPage) string { return r.Slug }},
+		{Header: "TITLE", Value: func(r OrphanedPage) string { return r.Title }},
+		{Header: "URL", Value: func(r OrphanedPage) string { return r.URL }},
+		{Header: "PUBLISHED", Value: func(r OrphanedPage) string { return orDash(truncateDate(r.PublishedAt)) }, Wide: true},
+	})
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("found %s of %d", plural(len(orphans), "orphaned page"), len(pages))
+}
+
+// siteLinks collects the paths on the site that something links to
+type siteLinks struct {
+	base  *url.URL
+	hosts map[string]bool
+	// linked holds the linked paths, as sitemapPath gives them
+	linked map[string]bool
+}
+
+// newSiteLinks returns a siteLinks for the site at the given URLs, the
+// first of which relative links are resolved against
+func newSiteLinks(urls ...string) (*siteLinks, error) {
+	base, err := url.Parse(strings.TrimSuffix(urls[0], "/") + "/")
+	if err != nil {
+		return nil, err
+	}
+	sl := &siteLinks{base: base, hosts: map[string]bool{}, linked: map[string]bool{}}
+	for _, u := range urls {
+		if parsed, err := url.Parse(u); err == nil {
+			sl.hosts[parsed.Host] = true
+		}
+	}
+	return sl, nil
+}
+
+// add records a link, unless it is to another site or to from, the URL of
+// the post or page it is in; a page linking to itself doesn't count
+func (sl *siteLinks) add(link, from string) {
+	link = strings.TrimSpace(link)
+	if link == "" || strings.HasPrefix(link, "#") {
+		return
+	}
+	u, err := sl.base.Parse(link)
+	if err != nil || !sl.hosts[u.Host] {
+		return
+	}
+	p := sitemapPath(u.Path)
+	if from != "" && p == sitemapPath(from) {
+		return
+	}
+	sl.linked[p] = true
+}
+
+// addHTML records the links in a post or page's HTML
+func (sl *siteLinks) addHTML(doc, from string) {
+	for _, m := range hrefRe.FindAllStringSubmatch(doc, -1) {
+		sl.add(html.UnescapeString(m[1]), from)
+	}
+}
@@ -0,0 +1,17 @@
+package cmd
+
+// encodePage writes a single page of a list as JSON, keeping the pagination
+// metadata next to the items so that scripts can page and show totals:
+//
+//	{"posts": [...], "meta": {"pagination": {...}}}
+func encodePage(key string, items interface{}, p Pagination) error {
+	if execCommand != "" {
+		return execEach(items)
+	}
+	return printJSON(map[string]interface{}{
+		key: items,
+		"meta": map[string]interface{}{
+			"pagination": p,
+		},
+	})
+}
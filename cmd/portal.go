@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var portalCmd = &cobra.Command{
+	Use:   "portal",
+	Short: "Membership portal links and settings",
+}
+
+var portalLinksCmd = &cobra.Command{
+	Use:   "links",
+	Short: "Print links that open the portal for signup, each tier and each offer",
+	Long: `Print the links that open Ghost's membership portal: signup and signin,
+the account pages, signup for each active paid tier (monthly and yearly),
+and each active offer. Use them in emails, on other sites, or in the
+navigation.
+
+With --html, print copyable buttons instead. The data-portal attribute
+opens the portal in place on the site itself; elsewhere the link goes to
+the site with the portal open.`,
+	Example: `  specter settings portal links
+  specter settings portal links --html`,
+	Args: cobra.NoArgs,
+	RunE: runPortalLinks,
+}
+
+var portalGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Show the portal settings",
+	Args:  cobra.NoArgs,
+	RunE:  runPortalGet,
+}
+
+var portalSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Change the portal button and the plans it offers",
+	Long: `Change the portal settings given as flags; others are left alone.
+
+--plans is a comma-separated list of the plans shown at signup: free,
+monthly and yearly.`,
+	Example: `  specter settings portal set --button-style text-only --signup-text 'Join us'
+  specter settings portal set --plans free,yearly --default-plan yearly`,
+	Args: cobra.NoArgs,
+	RunE: runPortalSet,
+}
+
+var (
+	portalHTML bool
+
+	portalButton      bool
+	portalButtonStyle string
+	portalSignupText  string
+	portalShowName    bool
+	portalPlans       []string
+	portalDefaultPlan string
+)
+
+// portalSettings are the portal's settings, with the flags of portal set
+// that change them
+var portalSettings = []struct{ key, flag string }{
+	{"portal_button", "button"},
+	{"portal_button_style", "button-style"},
+	{"portal_button_signup_text", "signup-text"},
+	{"portal_name", "show-name"},
+	{"portal_plans", "plans"},
+	{"portal_default_plan", "default-plan"},
+}
+
+func init() {
+	settingsCmd.AddCommand(portalCmd)
+	portalCmd.AddCommand(portalLinksCmd)
+	portalCmd.AddCommand(portalGetCmd)
+	portalCmd.AddCommand(portalSetCmd)
+
+	portalLinksCmd.Flags().BoolVar(&portalHTML, "html", false, "Print an HTML button for each link")
+
+	portalSetCmd.Flags().BoolVar(&portalButton, "button", true, "Show the floating portal button")
+	portalSetCmd.Flags().StringVar(&portalButtonStyle, "button-style", "", "Button style: icon-and-text, icon-only or text-only")
+	portalSetCmd.Flags().StringVar(&portalSignupText, "signup-text", "", "Text of the portal button")
+	portalSetCmd.Flags().BoolVar(&portalShowName, "show-name", true, "Ask for the member's name at signup")
+	portalSetCmd.Flags().StringSliceVar(&portalPlans, "plans", nil, "Plans offered at signup: free, monthly, yearly")
+	portalSetCmd.Flags().StringVar(&portalDefaultPlan, "default-plan", "", "Plan selected by default: monthly or yearly")
+}
+
+// PortalLink is a link that opens the membership portal
+type PortalLink struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Portal is the value of a data-portal attribute that opens the same
+	// page of the portal in place
+	Portal string `json:"data_portal"`
+}
+
+func runPortalLinks(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	siteURL, err := portalSiteURL(client, cfg)
+	if err != nil {
+		return err
+	}
+
+	var links []PortalLink
+	add := func(name, portal string) {
+		links = append(links, portalLink(siteURL, name, portal))
+	}
+	add("Sign up", "signup")
+	add("Sign up (free)", "signup/free")
+	add("Sign in", "signin")
+	add("Account", "account")
+	add("Account: plans", "account/plans")
+	add("Account: profile", "account/profile")
+	add("Account: newsletters", "account/newsletters")
+
+	opts := &api.ListOptions{Filter: "type:paid+active:true"}
+	for t, err := range client.Tiers.All(context.Background(), opts) {
+		if err != nil {
+			return err
+		}
+		if t.MonthlyPrice > 0 {
+			add(t.Name+" (monthly)", tierPortal(t, "monthly"))
+		}
+		if t.YearlyPrice > 0 {
+			add(t.Name+" (yearly)", tierPortal(t, "yearly"))
+		}
+	}
+
+	for o, err := range client.Offers.All(context.Background(), &api.ListOptions{Filter: "status:active"}) {
+		if err != nil {
+			return err
+		}
+		links = append(links, offerLink(siteURL, o))
+	}
+
+	if portalHTML {
+		for _, l := range links {
+			fmt.Printf("<!-- %s -->\n<a href=\"%s\" data-portal=\"%s\">%s</a>\n",
+				strings.ReplaceAll(l.Name, "--", "-"), html.EscapeString(l.URL), html.EscapeString(l.Portal), html.EscapeString(l.Name))
+		}
+		return nil
+	}
+	return render(links, []output.Column[PortalLink]{
+		{Header: "NAME", Value: func(l PortalLink) string { return l.Name }},
+		{Header: "URL", Value: func(l PortalLink) string { return l.URL }},
+		{Header: "DATA-PORTAL", Value: func(l PortalLink) string { return l.Portal }, Wide: true},
+	})
+}
+
+func runPortalGet(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	settings, err := getSettings(client)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]string{}
+	for _, s := range portalSettings {
+		values[s.key] = settingText(settings, s.key)
+	}
+	if config.OutputFormat() == "json" {
+		return printJSON(values)
+	}
+	for _, s := range portalSettings {
+		fmt.Printf("%-26s %s\n", s.key+":", orDash(values[s.key]))
+	}
+	return nil
+}
+
+func runPortalSet(cmd *cobra.Command, args []string) error {
+	var updates []Setting
+	for _, s := range portalSettings {
+		if !cmd.Flags().Changed(s.flag) {
+			continue
+		}
+		var value interface{}
+		switch s.flag {
+		case "button":
+			value = portalButton
+		case "button-style":
+			if !slices.Contains([]string{"icon-and-text", "icon-only", "text-only"}, portalButtonStyle) {
+				return fmt.Errorf("invalid --button-style %q: expected icon-and-text, icon-only or text-only", portalButtonStyle)
+			}
+			value = portalButtonStyle
+		case "signup-text":
+			value = portalSignupText
+		case "show-name":
+			value = portalShowName
+		case "plans":
+			for _, p := range portalPlans {
+				if !slices.Contains([]string{"free", "monthly", "yearly"}, p) {
+					return fmt.Errorf("invalid plan %q: expected free, monthly or yearly", p)
+				}
+			}
+			// Ghost stores the list as a JSON string
+			data, err := json.Marshal(portalPlans)
+			if err != nil {
+				return err
+			}
+			value = string(data)
+		case "default-plan":
+			if portalDefaultPlan != "monthly" && portalDefaultPlan != "yearly" {
+				return fmt.Errorf("invalid --default-plan %q: expected monthly or yearly", portalDefaultPlan)
+			}
+			value = portalDefaultPlan
+		}
+		updates = append(updates, Setting{Key: s.key, Value: value})
+	}
+	if len(updates) == 0 {
+		return fmt.Errorf("nothing to change (see --help for the settings)")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	current, err := getSettings(client)
+	if err != nil {
+		return err
+	}
+	settings, err := updateSettings(client, updates)
+	if err != nil {
+		return err
+	}
+
+	type change struct {
+		Key      string `json:"key"`
+		Previous string `json:"previous"`
+		Value    string `json:"value"`
+	}
+	var changes []change
+	for _, u := range updates {
+		changes = append(changes, change{u.Key, settingText(current, u.Key), settingText(settings, u.Key)})
+	}
+	if config.OutputFormat() == "json" {
+		return printJSON(changes)
+	}
+	for _, c := range changes {
+		fmt.Printf("Updated %s: %s -> %s\n", c.Key, orDash(c.Previous), orDash(c.Value))
+	}
+	return nil
+}
+
+// portalSiteURL returns the site's public URL, without a trailing slash,
+// for portal links
+func portalSiteURL(client *api.Client, cfg *config.Config) (string, error) {
+	var site siteResponse
+	if err := client.GetJSON("/site/", nil, &site); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(flagOr(site.Site.URL, cfg.URL), "/"), nil
+}
+
+// portalLink returns the link that opens the portal at the given page
+func portalLink(siteURL, name, portal string) PortalLink {
+	return PortalLink{Name: name, URL: siteURL + "/#/portal/" + portal, Portal: portal}
+}
+
+// tierPortal returns the portal page that signs up for a tier with the
+// given cadence, monthly or yearly. Free tiers have no cadence.
+func tierPortal(t Tier, cadence string) string {
+	if t.Type == "free" {
+		return "signup/free"
+	}
+	return "signup/" + t.ID + "/" + cadence
+}
+
+// offerLink returns the link of an offer. Its URL is the shareable one Ghost
+// makes from the offer's code; the data-portal value opens the offer in
+// place.
+func offerLink(siteURL string, o Offer) PortalLink {
+	return PortalLink{Name: "Offer: " + o.Name, URL: siteURL + "/" + o.Code, Portal: "offers/" + o.ID}
+}
+
+// settingText returns a setting's value as text, whatever its type, or ""
+// if it is unset
+func settingText(settings []Setting, key string) string {
+	for _, s := range settings {
+		if s.Key != key || s.Value == nil {
+			continue
+		}
+		if v, ok := s.Value.(string); ok {
+			return v
+		}
+		data, _ := json.Marshal(s.Value)
+		return string(data)
+	}
+	return ""
+}
@@ -1,17 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/teal-bauer/specter/api"
 	"github.com/teal-bauer/specter/internal/config"
 	"github.com/teal-bauer/specter/internal/content"
+	"github.com/teal-bauer/specter/internal/output"
 )
 
 var postsCmd = &cobra.Command{
@@ -43,9 +47,92 @@ var postsCreateCmd = &cobra.Command{
 var postsUpdateCmd = &cobra.Command{
 	Use:   "update <id-or-slug> [file.md]",
 	Short: "Update a post",
-	Long:  "Update a post. Provide a markdown file to update content, or use flags to update metadata only.",
-	Args:  cobra.RangeArgs(1, 2),
-	RunE:  runPostsUpdate,
+	Long: `Update a post. Provide a markdown file to update content, or use flags to
+update metadata only.
+
+If the post was changed in Ghost since specter last wrote it, the changes on
+both sides are shown before anything is sent. In a terminal you can merge
+them, overwrite the changes in Ghost or abort; otherwise use --theirs to
+merge, keeping Ghost's version where both changed the same lines, or
+--force to overwrite. A merge that can't be done automatically is written
+next to the file with conflict markers to resolve by hand.
+
+With --dry-run, the changes the file would make are shown as a diff, as by
+"posts diff".`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runPostsUpdate,
+}
+
+var postsPublishCmd = &cobra.Command{
+	Use:   "publish <id-or-slug> [file.md]",
+	Short: "Publish a post",
+	Long: `Publish an existing post. Use --newsletter to also send it by email, or
+--email-only to send it without publishing it on the site.
+
+If a markdown file is given, the post content is updated from it first, and
+its 'newsletter' and 'email_segment' frontmatter keys select the email
+recipients unless overridden by flags.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runPostsPublish,
+}
+
+var postsEmailPreviewCmd = &cobra.Command{
+	Use:   "email-preview <id-or-slug>",
+	Short: "Show a post as it would be sent by email",
+	Long:  "Print the rendered email HTML for a post (or the plaintext version with --plaintext), e.g. to open it in a browser.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPostsEmailPreview,
+}
+
+var postsEmailTestCmd = &cobra.Command{
+	Use:   "email-test <id-or-slug>",
+	Short: "Send a test email of a post",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPostsEmailTest,
+}
+
+var postsRevisionsCmd = &cobra.Command{
+	Use:   "revisions <id-or-slug>",
+	Short: "List saved revisions of a post",
+	Long: `List saved revisions of a post, newest first. Revisions can be referred to
+by ID or by their number in this list.
+
+Examples:
+  specter posts revisions my-post
+  specter posts revisions show my-post 3
+  specter posts revisions restore my-post 3`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPostsRevisions,
+}
+
+var postsRevisionsShowCmd = &cobra.Command{
+	Use:   "show <id-or-slug> <revision>",
+	Short: "Show the content of a post revision",
+	Long:  "Show the text of a post revision. With -o json, the full revision including its lexical document is printed.",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runPostsRevisionsShow,
+}
+
+var postsRevisionsRestoreCmd = &cobra.Command{
+	Use:   "restore <id-or-slug> <revision>",
+	Short: "Restore a post to an earlier revision",
+	Long:  "Replace the post's title and content with those of an earlier revision. The current content is kept as a new revision by Ghost.",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runPostsRevisionsRestore,
+}
+
+var postsCopyCmd = &cobra.Command{
+	Use:   "copy <id-or-slug>",
+	Short: "Copy a post to other profiles",
+	Long: `Copy a post to the sites of other profiles, e.g. to cross-post it.
+
+Copies are created as drafts unless --status is given. If the original is
+published, each copy's canonical_url points to it so search engines don't
+treat the copies as duplicate content; use --no-canonical to skip this.`,
+	Example: `  specter posts copy my-post --to work,personal
+  specter -p work posts copy my-post --to personal --status published`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPostsCopy,
 }
 
 var postsDeleteCmd = &cobra.Command{
@@ -57,11 +144,31 @@ var postsDeleteCmd = &cobra.Command{
 
 // Flag variables
 var (
-	postsLimit     int
-	postsPage      int
-	postsAll       bool
-	postsStatus    string
-	postsPublishAt string
+	postsLimit        int
+	postsPage         int
+	postsAll          bool
+	postsStatus       string
+	postsPublishAt    string
+	postsNewsletter   string
+	postsEmailSegment string
+	postsEmailOnly    bool
+	postsPlaintext    bool
+	postsTestTo       []string
+	postsForce        bool
+	postsCopyTo       []string
+	postsCopyStatus   string
+	postsNoCanonical  bool
+	postsUploadImages bool
+	postsTheirs       bool
+	postsDrafts       bool
+	postsScheduled    bool
+	postsPublished    bool
+	postsAuthor       string
+	postsAuthors      []string
+	postsAuthorList   []string
+	postsSince        string
+	postsUntil        string
+	postsQuery        listQuery
 )
 
 func init() {
@@ -70,52 +177,96 @@ func init() {
 	postsCmd.AddCommand(postsGetCmd)
 	postsCmd.AddCommand(postsCreateCmd)
 	postsCmd.AddCommand(postsUpdateCmd)
+	postsCmd.AddCommand(postsPublishCmd)
+	postsCmd.AddCommand(postsEmailPreviewCmd)
+	postsCmd.AddCommand(postsEmailTestCmd)
+	postsCmd.AddCommand(postsRevisionsCmd)
+	postsRevisionsCmd.AddCommand(postsRevisionsShowCmd)
+	postsRevisionsCmd.AddCommand(postsRevisionsRestoreCmd)
+	postsCmd.AddCommand(postsCopyCmd)
 	postsCmd.AddCommand(postsDeleteCmd)
 
 	postsListCmd.Flags().IntVar(&postsLimit, "limit", 15, "Number of posts to return")
 	postsListCmd.Flags().IntVar(&postsPage, "page", 1, "Page number")
 	postsListCmd.Flags().BoolVar(&postsAll, "all", false, "Fetch all posts (ignores limit/page)")
+	postsListCmd.Flags().BoolVar(&postsDrafts, "drafts", false, "Only list drafts")
+	postsListCmd.Flags().BoolVar(&postsScheduled, "scheduled", false, "Only list scheduled posts")
+	postsListCmd.Flags().BoolVar(&postsPublished, "published", false, "Only list published posts")
+	postsListCmd.Flags().StringVar(&postsAuthor, "author", "", "Only list posts by this author (slug)")
+	postsListCmd.Flags().StringVar(&postsSince, "since", "", "Only list posts published since this date or duration ago (e.g. 2025-01-01, 30d)")
+	postsListCmd.Flags().StringVar(&postsUntil, "until", "", "Only list posts published until this date or duration ago")
+	postsQuery.addFlags(postsListCmd, "tag:news")
+	addExecFlag(postsListCmd)
+	postsListCmd.MarkFlagsMutuallyExclusive("drafts", "scheduled", "published")
 
 	postsCreateCmd.Flags().StringVar(&postsStatus, "status", "", "Post status: draft, published, or scheduled")
 	postsCreateCmd.Flags().StringVar(&postsPublishAt, "publish-at", "", "Scheduled publish time (ISO 8601)")
+	postsCreateCmd.Flags().StringVar(&postsNewsletter, "newsletter", "", "Send by email through this newsletter (slug)")
+	postsCreateCmd.Flags().StringVar(&postsEmailSegment, "email-segment", "", "Members to email, e.g. 'status:free' or 'status:-free' (default all)")
+	postsCreateCmd.Flags().BoolVar(&postsEmailOnly, "email-only", false, "Send as email only, without publishing on the site (requires a newsletter; status defaults to published)")
+	postsCreateCmd.Flags().BoolVar(&postsUploadImages, "upload-images", false, "Upload images referenced by local path and use their Ghost URLs")
 
 	postsUpdateCmd.Flags().StringVar(&postsStatus, "status", "", "Update post status")
 	postsUpdateCmd.Flags().StringVar(&postsPublishAt, "publish-at", "", "Scheduled publish time (ISO 8601)")
+	postsUpdateCmd.Flags().StringVar(&postsNewsletter, "newsletter", "", "Send by email through this newsletter when publishing (slug)")
+	postsUpdateCmd.Flags().StringVar(&postsEmailSegment, "email-segment", "", "Members to email, e.g. 'status:free' or 'status:-free' (default all)")
+	postsUpdateCmd.Flags().BoolVar(&postsUploadImages, "upload-images", false, "Upload images referenced by local path and use their Ghost URLs")
+	for _, c := range []*cobra.Command{postsCreateCmd, postsUpdateCmd} {
+		c.Flags().StringArrayVar(&postsAuthors, "author", nil, "Author by email, slug or ID; repeat for several, the first being the primary author")
+		c.Flags().StringSliceVar(&postsAuthorList, "authors", nil, "Authors by email, slug or ID (comma-separated)")
+	}
+	postsUpdateCmd.Flags().BoolVar(&postsForce, "force", false, "Overwrite changes made in Ghost since specter last updated the post")
+	postsUpdateCmd.Flags().BoolVar(&postsTheirs, "theirs", false, "Merge changes made in Ghost, keeping theirs where both sides changed the same lines")
+	contentVarsFlags.addFlags(postsCreateCmd)
+	markdownOptionFlags.addFlags(postsCreateCmd)
+	writeOpenFlags.addFlags(postsCreateCmd)
+	contentVarsFlags.addFlags(postsUpdateCmd)
+	markdownOptionFlags.addFlags(postsUpdateCmd)
+	writeOpenFlags.addFlags(postsUpdateCmd)
+	contentVarsFlags.addFlags(postsPublishCmd)
+	markdownOptionFlags.addFlags(postsPublishCmd)
+
+	postsPublishCmd.Flags().StringVar(&postsNewsletter, "newsletter", "", "Send by email through this newsletter (slug)")
+	postsPublishCmd.Flags().StringVar(&postsEmailSegment, "email-segment", "", "Members to email, e.g. 'status:free' or 'status:-free' (default all)")
+	postsPublishCmd.Flags().BoolVar(&postsEmailOnly, "email-only", false, "Send as email only, without publishing on the site (requires a newsletter)")
+
+	postsEmailPreviewCmd.Flags().BoolVar(&postsPlaintext, "plaintext", false, "Print the plaintext version instead of HTML")
+	postsEmailPreviewCmd.Flags().StringVar(&postsNewsletter, "newsletter", "", "Render with this newsletter's design (slug)")
+	postsEmailPreviewCmd.Flags().StringVar(&postsEmailSegment, "email-segment", "", "Render as seen by this segment, e.g. 'status:free'")
+
+	postsEmailTestCmd.Flags().StringSliceVar(&postsTestTo, "to", nil, "Recipient addresses (comma-separated)")
+	postsEmailTestCmd.Flags().StringVar(&postsNewsletter, "newsletter", "", "Send with this newsletter's design (slug)")
+	postsEmailTestCmd.Flags().StringVar(&postsEmailSegment, "email-segment", "", "Send as seen by this segment, e.g. 'status:free'")
+	postsEmailTestCmd.MarkFlagRequired("to")
+
+	postsCopyCmd.Flags().StringSliceVar(&postsCopyTo, "to", nil, "Profiles to copy the post to (comma-separated)")
+	postsCopyCmd.Flags().StringVar(&postsCopyStatus, "status", "draft", "Status of the copies: draft, published, or scheduled")
+	postsCopyCmd.Flags().BoolVar(&postsNoCanonical, "no-canonical", false, "Don't point the copies' canonical URL at the original")
+	addReportFlag(postsCopyCmd)
+	_ = postsCopyCmd.MarkFlagRequired("to")
+
+	postsDeleteCmd.Flags().BoolVar(&postsForce, "force", false, "Delete without asking for confirmation")
+	postsRevisionsRestoreCmd.Flags().BoolVar(&postsForce, "force", false, "Restore without asking for confirmation")
+}
+
+// PostRevision is a saved version of a post's content
+type PostRevision struct {
+	ID         string `json:"id"`
+	PostID     string `json:"post_id"`
+	Title      string `json:"title"`
+	Lexical    string `json:"lexical,omitempty"`
+	FeatureImg string `json:"feature_image,omitempty"`
+	PostStatus string `json:"post_status,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	Author     *User  `json:"author,omitempty"`
 }
 
-// Post represents a Ghost post
-type Post struct {
-	ID          string   `json:"id"`
-	UUID        string   `json:"uuid"`
-	Title       string   `json:"title"`
-	Slug        string   `json:"slug"`
-	HTML        string   `json:"html,omitempty"`
-	Status      string   `json:"status"`
-	Visibility  string   `json:"visibility"`
-	Featured    bool     `json:"featured"`
-	CreatedAt   string   `json:"created_at"`
-	UpdatedAt   string   `json:"updated_at"`
-	PublishedAt string   `json:"published_at,omitempty"`
-	Excerpt     string   `json:"excerpt,omitempty"`
-	Tags        []Tag    `json:"tags,omitempty"`
-	URL         string   `json:"url,omitempty"`
-	FeatureImg  string   `json:"feature_image,omitempty"`
-	MetaTitle   string   `json:"meta_title,omitempty"`
-	MetaDesc    string   `json:"meta_description,omitempty"`
-}
-
-type postsResponse struct {
-	Posts []Post `json:"posts"`
-	Meta  struct {
-		Pagination struct {
-			Page  int `json:"page"`
-			Limit int `json:"limit"`
-			Pages int `json:"pages"`
-			Total int `json:"total"`
-			Next  int `json:"next"`
-			Prev  int `json:"prev"`
-		} `json:"pagination"`
-	} `json:"meta"`
+// EmailPreview is a post rendered as an email
+type EmailPreview struct {
+	Subject   string `json:"subject"`
+	HTML      string `json:"html"`
+	Plaintext string `json:"plaintext"`
 }
 
 func runPostsList(cmd *cobra.Command, args []string) error {
@@ -123,73 +274,73 @@ func runPostsList(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
+
+	dates, err := dateRangeFilter("published_at", postsSince, postsUntil)
+	if err != nil {
+		return err
+	}
+	filter := joinFilters(postsStatusFilter(), authorFilter(postsAuthor), dates)
+
+	var include []string
+	if postsAuthor != "" {
+		include = append(include, "authors")
+	}
+	opts := postsQuery.options(filter, include...)
 
 	var allPosts []Post
+	var pagination *Pagination
 
 	if postsAll {
-		page := 1
-		for {
-			params := url.Values{}
-			params.Set("limit", "100")
-			params.Set("page", fmt.Sprintf("%d", page))
-
-			data, err := client.Get("/posts/", params)
+		for p, err := range client.Posts.All(context.Background(), opts) {
 			if err != nil {
 				return err
 			}
-
-			var resp postsResponse
-			if err := json.Unmarshal(data, &resp); err != nil {
-				return fmt.Errorf("parsing response: %w", err)
-			}
-
-			allPosts = append(allPosts, resp.Posts...)
-
-			if resp.Meta.Pagination.Next == 0 {
-				break
+			if streaming() {
+				if err := streamItems([]Post{p}); err != nil {
+					return err
+				}
+			} else {
+				allPosts = append(allPosts, p)
 			}
-			page = resp.Meta.Pagination.Next
 		}
 	} else {
-		params := url.Values{}
-		params.Set("limit", fmt.Sprintf("%d", postsLimit))
-		params.Set("page", fmt.Sprintf("%d", postsPage))
-
-		data, err := client.Get("/posts/", params)
+		opts.Limit, opts.Page = postsLimit, postsPage
+		list, err := client.Posts.List(context.Background(), opts)
 		if err != nil {
 			return err
 		}
-
-		var resp postsResponse
-		if err := json.Unmarshal(data, &resp); err != nil {
-			return fmt.Errorf("parsing response: %w", err)
-		}
-		allPosts = resp.Posts
+		allPosts = list.Items
+		pagination = &list.Pagination
 	}
 
-	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(allPosts)
+	if pagination != nil && config.OutputFormat() == "json" {
+		return encodePage("posts", allPosts, *pagination)
 	}
+	return render(allPosts, postColumns)
+}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tTITLE\tSTATUS\tPUBLISHED")
-	for _, p := range allPosts {
-		published := p.PublishedAt
-		if published == "" {
-			published = "-"
-		} else if len(published) > 10 {
-			published = published[:10]
-		}
-		title := p.Title
-		if len(title) > 50 {
-			title = title[:47] + "..."
-		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.ID, title, p.Status, published)
+// postColumns are the columns of the posts list
+var postColumns = []output.Column[Post]{
+	{Header: "ID", Value: func(p Post) string { return p.ID }},
+	{Header: "TITLE", Value: func(p Post) string { return p.Title }, Width: 50},
+	{Header: "STATUS", Value: func(p Post) string { return p.Status }},
+	{Header: "PUBLISHED", Value: func(p Post) string { return orDash(truncateDate(p.PublishedAt)) }},
+	{Header: "SLUG", Value: func(p Post) string { return p.Slug }, Wide: true},
+	{Header: "URL", Value: func(p Post) string { return p.URL }, Wide: true},
+}
+
+// postsStatusFilter returns the NQL filter for the status shortcut flags
+func postsStatusFilter() string {
+	switch {
+	case postsDrafts:
+		return "status:draft"
+	case postsScheduled:
+		return "status:scheduled"
+	case postsPublished:
+		return "status:published"
 	}
-	return w.Flush()
+	return ""
 }
 
 func runPostsGet(cmd *cobra.Command, args []string) error {
@@ -197,56 +348,18 @@ func runPostsGet(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
-
-	idOrSlug := args[0]
-	path := fmt.Sprintf("/posts/%s/", idOrSlug)
+	client := newClient(cfg)
 
-	// Try by ID first, then by slug
-	data, err := client.Get(path, nil)
+	post, err := getPost(client, args[0])
 	if err != nil {
-		// Try by slug
-		params := url.Values{}
-		params.Set("filter", fmt.Sprintf("slug:%s", idOrSlug))
-		data, err = client.Get("/posts/", params)
-		if err != nil {
-			return err
-		}
-
-		var resp postsResponse
-		if err := json.Unmarshal(data, &resp); err != nil {
-			return fmt.Errorf("parsing response: %w", err)
-		}
-		if len(resp.Posts) == 0 {
-			return fmt.Errorf("post not found: %s", idOrSlug)
-		}
-
-		if config.OutputFormat() == "json" {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(resp.Posts[0])
-		}
-
-		printPost(resp.Posts[0])
-		return nil
-	}
-
-	var resp postsResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
-
-	if len(resp.Posts) == 0 {
-		return fmt.Errorf("post not found: %s", idOrSlug)
+		return err
 	}
 
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(resp.Posts[0])
+		return printJSON(post)
 	}
 
-	printPost(resp.Posts[0])
+	printPost(*post)
 	return nil
 }
 
@@ -272,17 +385,25 @@ func printPost(p Post) {
 }
 
 func runPostsCreate(cmd *cobra.Command, args []string) error {
+	if err := writeOpenFlags.validate(); err != nil {
+		return err
+	}
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
-	parsed, err := content.ParseFile(args[0])
+	parsed, err := parsePostFile(cfg, client, args[0])
 	if err != nil {
-		return fmt.Errorf("parsing file: %w", err)
+		return err
 	}
+	return createPost(cfg, client, parsed, postsStatus, postsPublishAt)
+}
 
+// createPost creates a post from a parsed markdown file and prints it.
+// status and publishAt override the frontmatter if set.
+func createPost(cfg *config.Config, client *api.Client, parsed *content.ParsedContent, status, publishAt string) error {
 	post := map[string]interface{}{
 		"title": parsed.Frontmatter.Title,
 		"html":  parsed.HTML,
@@ -303,22 +424,42 @@ func runPostsCreate(cmd *cobra.Command, args []string) error {
 	if parsed.Frontmatter.FeatureImg != "" {
 		post["feature_image"] = parsed.Frontmatter.FeatureImg
 	}
+	if parsed.Frontmatter.Visibility != "" {
+		post["visibility"] = parsed.Frontmatter.Visibility
+	}
 	if parsed.Frontmatter.Featured {
 		post["featured"] = true
 	}
-
-	// Status priority: CLI flag > frontmatter > default (draft)
-	status := "draft"
-	if parsed.Frontmatter.Status != "" {
-		status = parsed.Frontmatter.Status
+	if err := applyPostMeta(client, post, parsed.Frontmatter); err != nil {
+		return err
 	}
-	if postsStatus != "" {
-		status = postsStatus
+	if err := applyAuthorFlags(client, post); err != nil {
+		return err
+	}
+	newsletter := emailNewsletter(cfg, parsed.Frontmatter.Newsletter)
+	segment := flagOr(postsEmailSegment, parsed.Frontmatter.EmailSegment)
+	if postsEmailOnly {
+		if newsletter == "" {
+			return fmt.Errorf("--email-only requires a newsletter (--newsletter or frontmatter)")
+		}
+		post["email_only"] = true
+	}
+
+	// Status priority: CLI flag > frontmatter > profile > default (draft,
+	// or published for --email-only, as Ghost doesn't email drafts)
+	status = flagOr(status, flagOr(parsed.Frontmatter.Status, cfg.DefaultStatus))
+	switch {
+	case postsEmailOnly && status == "":
+		status = "published"
+	case postsEmailOnly && status != "published" && status != "scheduled":
+		return fmt.Errorf("--email-only needs status published or scheduled, not %s: Ghost doesn't email a %s post", status, status)
+	case status == "":
+		status = "draft"
 	}
 	post["status"] = status
 
-	if postsPublishAt != "" {
-		post["published_at"] = postsPublishAt
+	if publishAt != "" {
+		post["published_at"] = publishAt
 	} else if parsed.Frontmatter.PublishedAt != "" {
 		post["published_at"] = parsed.Frontmatter.PublishedAt
 	}
@@ -332,30 +473,15 @@ func runPostsCreate(cmd *cobra.Command, args []string) error {
 		post["tags"] = tags
 	}
 
-	body := map[string]interface{}{
-		"posts": []interface{}{post},
-	}
-
-	data, err := client.Post("/posts/", body)
+	created, err := client.Posts.Create(context.Background(), post, &api.WriteOptions{Newsletter: newsletter, EmailSegment: segment})
 	if err != nil {
 		return err
 	}
-
-	var resp postsResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
-
-	if len(resp.Posts) == 0 {
-		return fmt.Errorf("no post in response")
-	}
-
-	created := resp.Posts[0]
+	savePostBase(cfg, client, created.ID)
+	writeOpenFlags.run(cfg, "post", created.ID, created.URL)
 
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(created)
+		return printJSON(created)
 	}
 
 	fmt.Printf("Created post: %s\n", created.Title)
@@ -367,11 +493,14 @@ func runPostsCreate(cmd *cobra.Command, args []string) error {
 }
 
 func runPostsUpdate(cmd *cobra.Command, args []string) error {
+	if err := writeOpenFlags.validate(); err != nil {
+		return err
+	}
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	idOrSlug := args[0]
 
@@ -384,46 +513,30 @@ func runPostsUpdate(cmd *cobra.Command, args []string) error {
 	post := map[string]interface{}{
 		"updated_at": existing.UpdatedAt,
 	}
+	var newsletter, segment string
 
 	// If a file is provided, update content
 	if len(args) > 1 {
-		parsed, err := content.ParseFile(args[1])
+		parsed, err := parsePostFile(cfg, client, args[1])
 		if err != nil {
-			return fmt.Errorf("parsing file: %w", err)
-		}
-
-		if parsed.Frontmatter.Title != "" {
-			post["title"] = parsed.Frontmatter.Title
+			return err
 		}
-		post["html"] = parsed.HTML
 
-		if parsed.Frontmatter.Slug != "" {
-			post["slug"] = parsed.Frontmatter.Slug
-		}
-		if parsed.Frontmatter.Excerpt != "" {
-			post["custom_excerpt"] = parsed.Frontmatter.Excerpt
-		}
-		if parsed.Frontmatter.MetaTitle != "" {
-			post["meta_title"] = parsed.Frontmatter.MetaTitle
-		}
-		if parsed.Frontmatter.MetaDesc != "" {
-			post["meta_description"] = parsed.Frontmatter.MetaDesc
-		}
-		if parsed.Frontmatter.FeatureImg != "" {
-			post["feature_image"] = parsed.Frontmatter.FeatureImg
+		if err := applyPostFile(client, post, parsed); err != nil {
+			return err
 		}
-		post["featured"] = parsed.Frontmatter.Featured
 
 		if parsed.Frontmatter.Status != "" && postsStatus == "" {
 			post["status"] = parsed.Frontmatter.Status
 		}
+		newsletter, segment = parsed.Frontmatter.Newsletter, parsed.Frontmatter.EmailSegment
+	}
 
-		if len(parsed.Frontmatter.Tags) > 0 {
-			var tags []map[string]string
-			for _, t := range parsed.Frontmatter.Tags {
-				tags = append(tags, map[string]string{"name": t})
-			}
-			post["tags"] = tags
+	base, hasBase := loadPostBase(cfg, existing.ID)
+	if len(args) > 1 && hasBase && base.UpdatedAt != existing.UpdatedAt && !postsForce {
+		post, err = resolvePostConflict(cfg, client, base, existing, post, args[1])
+		if err != nil {
+			return err
 		}
 	}
 
@@ -431,48 +544,560 @@ func runPostsUpdate(cmd *cobra.Command, args []string) error {
 	if postsStatus != "" {
 		post["status"] = postsStatus
 	}
+	if err := applyAuthorFlags(client, post); err != nil {
+		return err
+	}
 	if postsPublishAt != "" {
 		post["published_at"] = postsPublishAt
 	}
 
-	body := map[string]interface{}{
-		"posts": []interface{}{post},
+	// A dry run shows what the file would change before the request
+	if config.FlagDryRun && len(args) > 1 {
+		diff, err := postUpdateDiff(client, existing.ID, post, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Print(flagOr(diff, "No changes to the post.\n"))
 	}
 
-	data, err := client.Put(fmt.Sprintf("/posts/%s/", existing.ID), body)
+	email := &api.WriteOptions{Newsletter: emailNewsletter(cfg, newsletter), EmailSegment: flagOr(postsEmailSegment, segment)}
+	updated, err := client.Posts.Update(context.Background(), existing.ID, post, email)
+	if isUpdateCollision(err) {
+		return fmt.Errorf("%q was changed in Ghost while it was being updated; run the update again to see and merge the changes", existing.Title)
+	}
 	if err != nil {
 		return err
 	}
+	// A base that is behind changes made in Ghost stays, unless this
+	// update was checked against them
+	if len(args) > 1 || !hasBase || base.UpdatedAt == existing.UpdatedAt {
+		savePostBase(cfg, client, updated.ID)
+	}
+	writeOpenFlags.run(cfg, "post", updated.ID, updated.URL)
+
+	if config.OutputFormat() == "json" {
+		return printJSON(updated)
+	}
 
-	var resp postsResponse
+	fmt.Printf("Updated post: %s\n", updated.Title)
+	fmt.Printf("  ID:     %s\n", updated.ID)
+	fmt.Printf("  Status: %s\n", updated.Status)
+	return nil
+}
+
+func runPostsPublish(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	existing, err := getPost(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	var parsed *content.ParsedContent
+	if len(args) > 1 {
+		if parsed, err = parsePostFile(cfg, client, args[1]); err != nil {
+			return err
+		}
+	}
+	return publishPost(cfg, client, existing, parsed)
+}
+
+// publishPost publishes an existing post and prints it, updating its
+// content from parsed first if given. A post that is already published is
+// only updated: Ghost emails a post once, when it is first published.
+func publishPost(cfg *config.Config, client *api.Client, existing *Post, parsed *content.ParsedContent) error {
+	post := map[string]interface{}{
+		"updated_at": existing.UpdatedAt,
+	}
+	var newsletter, segment string
+
+	if parsed != nil {
+		if err := applyPostFile(client, post, parsed); err != nil {
+			return err
+		}
+		newsletter, segment = parsed.Frontmatter.Newsletter, parsed.Frontmatter.EmailSegment
+	}
+	post["status"] = "published"
+
+	newsletter = emailNewsletter(cfg, newsletter)
+	segment = flagOr(postsEmailSegment, segment)
+	if postsEmailOnly {
+		if newsletter == "" {
+			return fmt.Errorf("--email-only requires a newsletter (--newsletter or frontmatter)")
+		}
+		post["email_only"] = true
+	}
+	wasPublished := existing.Status == "published"
+	if wasPublished {
+		if postsNewsletter != "" || postsEmailOnly {
+			fmt.Fprintf(os.Stderr, "Warning: %q is already published, so it isn't emailed again\n", existing.Title)
+		}
+		newsletter = ""
+		delete(post, "email_only")
+	}
+
+	published, err := client.Posts.Update(context.Background(), existing.ID, post, &api.WriteOptions{Newsletter: newsletter, EmailSegment: segment})
+	if err != nil {
+		return err
+	}
+	if parsed != nil {
+		savePostBase(cfg, client, published.ID)
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(published)
+	}
+
+	if wasPublished {
+		fmt.Printf("Updated post: %s\n", published.Title)
+	} else {
+		fmt.Printf("Published post: %s\n", published.Title)
+	}
+	fmt.Printf("  ID:     %s\n", published.ID)
+	fmt.Printf("  Status: %s\n", published.Status)
+	fmt.Printf("  URL:    %s\n", published.URL)
+	if newsletter != "" {
+		fmt.Printf("  Email:  sent via %s\n", newsletter)
+	}
+	return nil
+}
+
+func runPostsEmailPreview(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	existing, err := getPost(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	if postsNewsletter != "" {
+		params.Set("newsletter", postsNewsletter)
+	}
+	if postsEmailSegment != "" {
+		params.Set("memberSegment", postsEmailSegment)
+	}
+
+	data, err := client.Get(fmt.Sprintf("/email_previews/posts/%s/", existing.ID), params)
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		EmailPreviews []EmailPreview `json:"email_previews"`
+	}
 	if err := json.Unmarshal(data, &resp); err != nil {
 		return fmt.Errorf("parsing response: %w", err)
 	}
 
-	if len(resp.Posts) == 0 {
-		return fmt.Errorf("no post in response")
+	if len(resp.EmailPreviews) == 0 {
+		return fmt.Errorf("no email preview in response")
 	}
 
-	updated := resp.Posts[0]
+	preview := resp.EmailPreviews[0]
 
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(updated)
+		return printJSON(preview)
 	}
 
-	fmt.Printf("Updated post: %s\n", updated.Title)
-	fmt.Printf("  ID:     %s\n", updated.ID)
-	fmt.Printf("  Status: %s\n", updated.Status)
+	if postsPlaintext {
+		fmt.Println(preview.Plaintext)
+	} else {
+		fmt.Println(preview.HTML)
+	}
+	return nil
+}
+
+func runPostsEmailTest(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	existing, err := getPost(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{
+		"emails": postsTestTo,
+	}
+	if postsEmailSegment != "" {
+		body["memberSegment"] = postsEmailSegment
+	}
+	if postsNewsletter != "" {
+		body["newsletter"] = postsNewsletter
+	}
+
+	if _, err := client.Post(fmt.Sprintf("/email_previews/posts/%s/", existing.ID), body); err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(map[string]interface{}{
+			"post": existing.ID,
+			"sent": postsTestTo,
+		})
+	}
+
+	fmt.Printf("Sent test email of '%s' to %s\n", existing.Title, strings.Join(postsTestTo, ", "))
+	return nil
+}
+
+func runPostsRevisions(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	_, revisions, err := getPostRevisions(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(revisions)
+	}
+
+	if len(revisions) == 0 {
+		fmt.Println("No revisions saved for this post.")
+		return nil
+	}
+
+	numbers := make(map[string]int, len(revisions))
+	for i, r := range revisions {
+		numbers[r.ID] = i + 1
+	}
+	return render(revisions, []output.Column[PostRevision]{
+		{Header: "#", Value: func(r PostRevision) string { return strconv.Itoa(numbers[r.ID]) }},
+		{Header: "ID", Value: func(r PostRevision) string { return r.ID }},
+		{Header: "CREATED", Value: func(r PostRevision) string { return r.CreatedAt }},
+		{Header: "AUTHOR", Value: func(r PostRevision) string {
+			if r.Author != nil {
+				return r.Author.Name
+			}
+			return "-"
+		}},
+		{Header: "STATUS", Value: func(r PostRevision) string { return r.PostStatus }},
+		{Header: "REASON", Value: func(r PostRevision) string { return orDash(r.Reason) }},
+		{Header: "TITLE", Value: func(r PostRevision) string { return r.Title }},
+	})
+}
+
+func runPostsRevisionsShow(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	_, revisions, err := getPostRevisions(client, args[0])
+	if err != nil {
+		return err
+	}
+	rev, err := findRevision(revisions, args[1])
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(rev)
+	}
+
+	fmt.Printf("Revision: %s\n", rev.ID)
+	fmt.Printf("Created:  %s\n", rev.CreatedAt)
+	fmt.Printf("Title:    %s\n", rev.Title)
+	fmt.Println()
+	fmt.Print(lexicalText(rev.Lexical))
 	return nil
 }
 
+func runPostsRevisionsRestore(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	existing, revisions, err := getPostRevisions(client, args[0])
+	if err != nil {
+		return err
+	}
+	rev, err := findRevision(revisions, args[1])
+	if err != nil {
+		return err
+	}
+	if rev.Lexical == "" {
+		return fmt.Errorf("revision %s has no content to restore", rev.ID)
+	}
+
+	if err := confirmOrAbort(fmt.Sprintf("Restore '%s' to the revision from %s?", existing.Title, rev.CreatedAt), postsForce); err != nil {
+		return err
+	}
+
+	post := map[string]interface{}{
+		"updated_at": existing.UpdatedAt,
+		"title":      rev.Title,
+		"lexical":    rev.Lexical,
+	}
+	if rev.FeatureImg != "" {
+		post["feature_image"] = rev.FeatureImg
+	}
+
+	restored, err := client.Posts.Update(context.Background(), existing.ID, post, nil)
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(restored)
+	}
+
+	fmt.Printf("Restored post: %s\n", restored.Title)
+	fmt.Printf("  ID:       %s\n", restored.ID)
+	fmt.Printf("  Revision: %s (%s)\n", rev.ID, rev.CreatedAt)
+	return nil
+}
+
+// getPostRevisions returns a post and its saved revisions, newest first
+func getPostRevisions(client *api.Client, idOrSlug string) (*Post, []PostRevision, error) {
+	existing, err := getPost(client, idOrSlug)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params := url.Values{}
+	params.Set("include", "post_revisions,post_revisions.author")
+	params.Set("formats", "lexical")
+
+	data, err := client.Get(fmt.Sprintf("/posts/%s/", existing.ID), params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var resp struct {
+		Posts []struct {
+			PostRevisions []PostRevision `json:"post_revisions"`
+		} `json:"posts"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, nil, fmt.Errorf("parsing response: %w", err)
+	}
+	if len(resp.Posts) == 0 {
+		return nil, nil, fmt.Errorf("post not found: %s", idOrSlug)
+	}
+
+	revisions := resp.Posts[0].PostRevisions
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].CreatedAt > revisions[j].CreatedAt
+	})
+	return existing, revisions, nil
+}
+
+// findRevision looks up a revision by ID or by its 1-based list position
+func findRevision(revisions []PostRevision, ref string) (*PostRevision, error) {
+	for i, r := range revisions {
+		if r.ID == ref {
+			return &revisions[i], nil
+		}
+	}
+	if n, err := strconv.Atoi(ref); err == nil && n >= 1 && n <= len(revisions) {
+		return &revisions[n-1], nil
+	}
+	return nil, fmt.Errorf("revision not found: %s", ref)
+}
+
+// lexicalText extracts readable text from a Lexical editor document, one
+// block per paragraph
+func lexicalText(doc string) string {
+	var root struct {
+		Root json.RawMessage `json:"root"`
+	}
+	if err := json.Unmarshal([]byte(doc), &root); err != nil {
+		return doc
+	}
+
+	type node struct {
+		Type     string `json:"type"`
+		Text     string `json:"text"`
+		Children []node `json:"children"`
+	}
+	var top node
+	if err := json.Unmarshal(root.Root, &top); err != nil {
+		return doc
+	}
+
+	var b strings.Builder
+	var inline func(n node)
+	inline = func(n node) {
+		if n.Type == "linebreak" {
+			b.WriteString("\n")
+		}
+		b.WriteString(n.Text)
+		for _, c := range n.Children {
+			inline(c)
+		}
+	}
+	for _, block := range top.Children {
+		if block.Type == "list" {
+			for _, item := range block.Children {
+				b.WriteString("- ")
+				inline(item)
+				b.WriteString("\n")
+			}
+		} else {
+			inline(block)
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// postCopyFields are the fields carried over when copying a post
+var postCopyFields = []string{
+	"title", "slug", "lexical", "custom_excerpt", "visibility", "featured",
+	"feature_image", "feature_image_alt", "feature_image_caption",
+	"meta_title", "meta_description",
+	"og_image", "og_title", "og_description",
+	"twitter_image", "twitter_title", "twitter_description",
+	"canonical_url",
+}
+
+type postCopyResult struct {
+	Profile string `json:"profile"`
+	ID      string `json:"id,omitempty"`
+	Status  string `json:"status,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func runPostsCopy(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	existing, err := getPost(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("formats", "lexical")
+	params.Set("include", "tags")
+	data, err := client.Get(fmt.Sprintf("/posts/%s/", existing.ID), params)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Posts []map[string]interface{} `json:"posts"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	if len(resp.Posts) == 0 {
+		return fmt.Errorf("post not found: %s", args[0])
+	}
+	source := resp.Posts[0]
+
+	post := map[string]interface{}{"status": postsCopyStatus}
+	for _, field := range postCopyFields {
+		if v, ok := source[field]; ok && v != nil {
+			post[field] = v
+		}
+	}
+	if tags, ok := source["tags"].([]interface{}); ok {
+		var names []map[string]interface{}
+		for _, t := range tags {
+			if tag, ok := t.(map[string]interface{}); ok {
+				names = append(names, map[string]interface{}{"name": tag["name"]})
+			}
+		}
+		post["tags"] = names
+	}
+
+	// Keep an existing canonical URL, since the original may itself be a copy
+	if !postsNoCanonical && post["canonical_url"] == nil {
+		if existing.Status == "published" {
+			post["canonical_url"] = existing.URL
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: %q is not published, so the copies get no canonical URL\n", existing.Title)
+		}
+	}
+
+	var results []postCopyResult
+	failed := 0
+	for _, profile := range postsCopyTo {
+		result := postCopyResult{Profile: profile}
+		created, err := copyPostTo(profile, post)
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+		} else {
+			result.ID = created.ID
+			result.Status = created.Status
+			result.URL = created.URL
+		}
+		results = append(results, result)
+	}
+
+	if err := writeReport(cmd, cfg, results, failed); err != nil {
+		return err
+	}
+	err = render(results, []output.Column[postCopyResult]{
+		{Header: "PROFILE", Value: func(r postCopyResult) string { return r.Profile }},
+		{Header: "ID", Value: func(r postCopyResult) string { return orDash(r.ID) }},
+		{Header: "STATUS", Value: func(r postCopyResult) string {
+			if r.Error != "" {
+				return "failed"
+			}
+			return r.Status
+		}},
+		{Header: "URL", Value: func(r postCopyResult) string {
+			if r.Error != "" {
+				return r.Error
+			}
+			return r.URL
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d copies failed", failed, len(results))
+	}
+	return nil
+}
+
+// copyPostTo creates post on the site of the given profile
+func copyPostTo(profile string, post map[string]interface{}) (*Post, error) {
+	cfg, err := config.LoadProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return newClient(cfg).Posts.Create(context.Background(), post, nil)
+}
+
 func runPostsDelete(cmd *cobra.Command, args []string) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	idOrSlug := args[0]
 
@@ -482,13 +1107,16 @@ func runPostsDelete(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	_, err = client.Delete(fmt.Sprintf("/posts/%s/", existing.ID))
-	if err != nil {
+	if err := confirmOrAbort(fmt.Sprintf("Delete post '%s' (%s)?", existing.Title, existing.ID), postsForce); err != nil {
+		return err
+	}
+
+	if err := client.Posts.Delete(context.Background(), existing.ID); err != nil {
 		return err
 	}
 
 	if config.OutputFormat() == "json" {
-		return json.NewEncoder(os.Stdout).Encode(map[string]string{
+		return printJSON(map[string]string{
 			"deleted": existing.ID,
 			"title":   existing.Title,
 		})
@@ -498,32 +1126,251 @@ func runPostsDelete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func getPost(client *api.Client, idOrSlug string) (*Post, error) {
-	// Try by ID first
-	data, err := client.Get(fmt.Sprintf("/posts/%s/", idOrSlug), nil)
-	if err == nil {
-		var resp postsResponse
-		if err := json.Unmarshal(data, &resp); err == nil && len(resp.Posts) > 0 {
-			return &resp.Posts[0], nil
+// markdownOptions returns the profile's rendering options, resolving wiki
+// links against the titles of existing posts and pages and expanding
+// template variables from --var and --vars
+func markdownOptions(cfg *config.Config, client *api.Client) (content.Options, error) {
+	opts := cfg.Markdown
+	markdownOptionFlags.apply(&opts)
+	vars, err := contentVarsFlags.load()
+	if err != nil {
+		return opts, err
+	}
+	opts.Vars = vars
+	opts.ResolveWikiLink = func(target string) (string, bool) {
+		slug := content.Slugify(target)
+		if p, err := getPost(client, slug); err == nil {
+			return p.URL, true
 		}
+		if p, err := getPage(client, slug); err == nil {
+			return p.URL, true
+		}
+		return "", false
+	}
+	opts.ResolveBookmark = func(pageURL string) (*content.Bookmark, error) {
+		return fetchBookmark(client, pageURL)
 	}
+	opts.ResolveEmbed = func(pageURL string) (*content.Embed, error) {
+		return fetchEmbed(client, pageURL)
+	}
+	return opts, nil
+}
 
-	// Try by slug
-	params := url.Values{}
-	params.Set("filter", fmt.Sprintf("slug:%s", idOrSlug))
-	data, err = client.Get("/posts/", params)
+// parsePostFile parses a post's markdown file, uploading local images first
+// if --upload-images is set
+func parsePostFile(cfg *config.Config, client *api.Client, path string) (*content.ParsedContent, error) {
+	opts, err := markdownOptions(cfg, client)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp postsResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return nil, fmt.Errorf("parsing response: %w", err)
+	var cache *imageCache
+	if postsUploadImages {
+		baseDir := "."
+		if path != "-" {
+			baseDir = filepath.Dir(path)
+		}
+		// Nothing is uploaded in a dry run, but images uploaded before resolve
+		uploadClient := client
+		if config.FlagDryRun {
+			uploadClient = nil
+		}
+		cache = loadImageCache()
+		opts.ResolveImage = localImageUploader(cfg, uploadClient, baseDir, cache)
 	}
 
-	if len(resp.Posts) == 0 {
-		return nil, fmt.Errorf("post not found: %s", idOrSlug)
+	parsed, err := content.ParseFile(path, opts)
+	if err != nil {
+		return nil, fmt.Errorf("parsing file: %w", err)
+	}
+	printWarnings(parsed.Warnings)
+
+	if opts.ResolveImage != nil {
+		fm := &parsed.Frontmatter
+		for _, img := range []*string{&fm.FeatureImg, &fm.OGImage, &fm.TwitterImage} {
+			if *img == "" {
+				continue
+			}
+			resolved, err := opts.ResolveImage(*img)
+			if err != nil {
+				return nil, fmt.Errorf("image %s: %w", *img, err)
+			}
+			if resolved != "" {
+				*img = resolved
+			}
+		}
+		if err := cache.save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: saving image cache: %v\n", err)
+		}
+	}
+	return parsed, nil
+}
+
+// printWarnings reports non-fatal content problems on stderr
+func printWarnings(warnings []string) {
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+}
+
+// applyPostFile copies content and metadata from a parsed markdown file into
+// a post update. Status is left to the caller.
+func applyPostFile(client *api.Client, post map[string]interface{}, parsed *content.ParsedContent) error {
+	if parsed.Frontmatter.Title != "" {
+		post["title"] = parsed.Frontmatter.Title
+	}
+	post["html"] = parsed.HTML
+
+	if parsed.Frontmatter.Slug != "" {
+		post["slug"] = parsed.Frontmatter.Slug
+	}
+	if parsed.Frontmatter.Excerpt != "" {
+		post["custom_excerpt"] = parsed.Frontmatter.Excerpt
+	}
+	if parsed.Frontmatter.MetaTitle != "" {
+		post["meta_title"] = parsed.Frontmatter.MetaTitle
+	}
+	if parsed.Frontmatter.MetaDesc != "" {
+		post["meta_description"] = parsed.Frontmatter.MetaDesc
+	}
+	if parsed.Frontmatter.FeatureImg != "" {
+		post["feature_image"] = parsed.Frontmatter.FeatureImg
+	}
+	if parsed.Frontmatter.Visibility != "" {
+		post["visibility"] = parsed.Frontmatter.Visibility
+	}
+	post["featured"] = parsed.Frontmatter.Featured
+
+	if len(parsed.Frontmatter.Tags) > 0 {
+		var tags []map[string]string
+		for _, t := range parsed.Frontmatter.Tags {
+			tags = append(tags, map[string]string{"name": t})
+		}
+		post["tags"] = tags
+	}
+	return applyPostMeta(client, post, parsed.Frontmatter)
+}
+
+// applyPostMeta copies the frontmatter keys that posts and pages share
+// beyond the basics into a post or page: authors, tiers, canonical URL,
+// social cards, template and code injection. Authors are given by email,
+// slug or ID and tiers by slug or ID.
+func applyPostMeta(client *api.Client, post map[string]interface{}, fm content.Frontmatter) error {
+	if fm.Visibility == "tiers" && len(fm.Tiers) == 0 {
+		return fmt.Errorf("visibility 'tiers' needs the tiers that can see it, e.g. 'tiers: [gold]'")
+	}
+	if len(fm.Tiers) > 0 {
+		if fm.Visibility != "tiers" {
+			return fmt.Errorf("tiers are only used with 'visibility: tiers'")
+		}
+		var tiers []map[string]string
+		for _, ref := range fm.Tiers {
+			t, err := getTier(client, ref)
+			if err != nil {
+				return err
+			}
+			tiers = append(tiers, map[string]string{"id": t.ID})
+		}
+		post["tiers"] = tiers
+	}
+
+	if len(fm.Authors) > 0 {
+		authors, err := authorRefs(client, fm.Authors)
+		if err != nil {
+			return err
+		}
+		post["authors"] = authors
+	}
+
+	fields := map[string]string{
+		"canonical_url":       fm.CanonicalURL,
+		"og_title":            fm.OGTitle,
+		"og_description":      fm.OGDesc,
+		"og_image":            fm.OGImage,
+		"twitter_title":       fm.TwitterTitle,
+		"twitter_description": fm.TwitterDesc,
+		"twitter_image":       fm.TwitterImage,
+		"custom_template":     fm.CustomTemplate,
+		"codeinjection_head":  fm.CodeHead,
+		"codeinjection_foot":  fm.CodeFoot,
 	}
+	for field, v := range fields {
+		if v != "" {
+			post[field] = v
+		}
+	}
+	return nil
+}
 
-	return &resp.Posts[0], nil
+// authorRefs looks up staff users by email, slug or ID and returns them as
+// a post's authors, the first being the primary author
+func authorRefs(client *api.Client, refs []string) ([]map[string]string, error) {
+	var authors []map[string]string
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			continue
+		}
+		var u *User
+		if strings.Contains(ref, "@") {
+			opts := &api.ListOptions{Filter: fmt.Sprintf("email:'%s'", strings.ReplaceAll(ref, "'", `\'`)), Limit: 1}
+			list, err := client.Users.List(context.Background(), opts)
+			if err != nil {
+				return nil, fmt.Errorf("author %s: %w", ref, err)
+			}
+			if len(list.Items) == 0 {
+				return nil, fmt.Errorf("author %s: no staff user has this email", ref)
+			}
+			u = &list.Items[0]
+		} else {
+			var err error
+			if u, err = getUser(client, ref); err != nil {
+				return nil, fmt.Errorf("author %s: %w", ref, err)
+			}
+		}
+		if !seen[u.ID] {
+			seen[u.ID] = true
+			authors = append(authors, map[string]string{"id": u.ID})
+		}
+	}
+	return authors, nil
+}
+
+// applyAuthorFlags sets a post's authors from --author and --authors,
+// which override the frontmatter
+func applyAuthorFlags(client *api.Client, post map[string]interface{}) error {
+	refs := append(append([]string{}, postsAuthors...), postsAuthorList...)
+	if len(refs) == 0 {
+		return nil
+	}
+	authors, err := authorRefs(client, refs)
+	if err != nil {
+		return err
+	}
+	post["authors"] = authors
+	return nil
+}
+
+// flagOr returns the flag value if set, otherwise the fallback
+func flagOr(flag, fallback string) string {
+	if flag != "" {
+		return flag
+	}
+	return fallback
+}
+
+// emailNewsletter returns the newsletter to email a post through when it is
+// published: --newsletter, else the frontmatter's, else the profile's. A
+// newsletter of "none" sends no email.
+func emailNewsletter(cfg *config.Config, frontmatter string) string {
+	newsletter := flagOr(postsNewsletter, flagOr(frontmatter, cfg.Newsletter))
+	if newsletter == "none" {
+		return ""
+	}
+	return newsletter
+}
+
+func getPost(client *api.Client, idOrSlug string) (*Post, error) {
+	return client.Posts.Lookup(context.Background(), idOrSlug, nil)
 }
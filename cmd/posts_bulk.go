@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var postsBulkCmd = &cobra.Command{
+	Use:   "bulk",
+	Short: "Change fields and tags of every post matching a filter",
+	Long: `Change fields and tags of every post matching --filter, e.g. to unfeature
+everything or archive old drafts, without a script.
+
+--set takes field=value and may be repeated. The fields that can be set
+are:
+
+  featured, status, visibility, custom_template, feature_image,
+  canonical_url, custom_excerpt, meta_title, meta_description,
+  published_at
+
+An empty value clears a field, e.g. --set canonical_url=. --add-tag and
+--remove-tag work as --add and --remove of "posts retag".
+
+Posts that wouldn't change are skipped. The others are updated in parallel
+(--workers), and a summary is printed at the end. With --dry-run, the
+changes each post would get are listed and nothing is sent.`,
+	Example: `  specter posts bulk --filter 'featured:true' --set featured=false
+  specter posts bulk --filter 'status:draft+created_at:<2023-01-01' --set featured=false --add-tag archive
+  specter posts bulk --filter 'tag:old' --set visibility=members --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runPostsBulk,
+}
+
+var (
+	bulkFilter    string
+	bulkSet       []string
+	bulkAddTag    []string
+	bulkRemoveTag []string
+	bulkWorkers   int
+)
+
+func init() {
+	postsCmd.AddCommand(postsBulkCmd)
+	postsBulkCmd.Flags().StringVar(&bulkFilter, "filter", "", "Filter posts to change (required)")
+	postsBulkCmd.Flags().StringArrayVar(&bulkSet, "set", nil, "Set a field, e.g. featured=false (repeatable)")
+	postsBulkCmd.Flags().StringSliceVar(&bulkAddTag, "add-tag", nil, "Tags to add (slug or name)")
+	postsBulkCmd.Flags().StringSliceVar(&bulkRemoveTag, "remove-tag", nil, "Tags to remove (slug or name)")
+	postsBulkCmd.Flags().IntVar(&bulkWorkers, "workers", 4, "Concurrent updates")
+	_ = postsBulkCmd.MarkFlagRequired("filter")
+	addReportFlag(postsBulkCmd)
+}
+
+// bulkFields are the fields posts bulk can set, and whether each is a
+// boolean rather than a string
+var bulkFields = map[string]bool{
+	"featured":         true,
+	"status":           false,
+	"visibility":       false,
+	"custom_template":  false,
+	"feature_image":    false,
+	"canonical_url":    false,
+	"custom_excerpt":   false,
+	"meta_title":       false,
+	"meta_description": false,
+	"published_at":     false,
+}
+
+// BulkResult is the outcome of changing one post
+type BulkResult struct {
+	ID      string `json:"id"`
+	Slug    string `json:"slug"`
+	Changes string `json:"changes,omitempty"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// bulkField is a field to set and its new value; nil clears it
+type bulkField struct {
+	Key   string
+	Value interface{}
+}
+
+func runPostsBulk(cmd *cobra.Command, args []string) error {
+	if strings.TrimSpace(bulkFilter) == "" {
+		return fmt.Errorf("--filter must not be empty")
+	}
+	fields, err := parseBulkSet(bulkSet)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 && len(bulkAddTag) == 0 && len(bulkRemoveTag) == 0 {
+		return fmt.Errorf("nothing to do: give --set, --add-tag or --remove-tag")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	add := resolveAddTags(client, bulkAddTag)
+	remove := tagMatcher(bulkRemoveTag)
+	for _, t := range add {
+		if remove(t) {
+			return fmt.Errorf("tag '%s' is both added and removed", t.Name)
+		}
+	}
+	retag := len(add) > 0 || len(bulkRemoveTag) > 0
+
+	keys := []string{"id", "slug", "updated_at"}
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+	}
+	params := url.Values{"filter": {bulkFilter}, "fields": {strings.Join(keys, ",")}}
+	if retag {
+		params.Set("include", "tags")
+	}
+
+	// Work out every post's changes first, so the prompt can say how many
+	// posts will change
+	var results []BulkResult
+	updates := map[int]map[string]interface{}{}
+	for page, err := range client.Paginate("/posts/", params) {
+		if err != nil {
+			return err
+		}
+		var resp struct {
+			Posts []json.RawMessage `json:"posts"`
+		}
+		if err := json.Unmarshal(page, &resp); err != nil {
+			return fmt.Errorf("parsing response: %w", err)
+		}
+		for _, raw := range resp.Posts {
+			var current map[string]interface{}
+			var p Post
+			if err := json.Unmarshal(raw, &current); err != nil {
+				return fmt.Errorf("parsing response: %w", err)
+			}
+			if err := json.Unmarshal(raw, &p); err != nil {
+				return fmt.Errorf("parsing response: %w", err)
+			}
+
+			r := BulkResult{ID: p.ID, Slug: p.Slug, Status: "unchanged"}
+			update := map[string]interface{}{}
+			var changes []string
+			for _, f := range fields {
+				if bulkValueEqual(current[f.Key], f.Value) {
+					continue
+				}
+				update[f.Key] = f.Value
+				changes = append(changes, fmt.Sprintf("%s: %s → %s", f.Key, bulkValueString(current[f.Key]), bulkValueString(f.Value)))
+			}
+			if retag {
+				tags := changeTags(p.Tags, add, remove)
+				if !sameTags(p.Tags, tags) {
+					update["tags"] = tagRefs(tags)
+					changes = append(changes, tagChanges(p.Tags, tags)...)
+				}
+			}
+			if len(update) > 0 {
+				update["updated_at"] = p.UpdatedAt
+				updates[len(results)] = update
+				r.Changes = strings.Join(changes, ", ")
+				r.Status = "pending"
+			}
+			results = append(results, r)
+		}
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no posts match filter: %s", bulkFilter)
+	}
+
+	if len(updates) > 0 {
+		if err := confirmOrAbort(fmt.Sprintf("Update %s matching '%s'?", plural(len(updates), "post"), bulkFilter), false); err != nil {
+			return err
+		}
+	}
+
+	failed := updateBulkPosts(client, results, updates, bulkWorkers)
+
+	if err := writeReport(cmd, cfg, results, failed); err != nil {
+		return err
+	}
+	err = render(results, []output.Column[BulkResult]{
+		{Header: "ID", Value: func(r BulkResult) string { return r.ID }, Wide: true},
+		{Header: "SLUG", Value: func(r BulkResult) string { return r.Slug }},
+		{Header: "CHANGES", Value: func(r BulkResult) string { return orDash(r.Changes) }, Width: 60},
+		{Header: "STATUS", Value: func(r BulkResult) string { return r.Status }},
+		{Header: "ERROR", Value: func(r BulkResult) string { return orDash(r.Error) }},
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, bulkSummary(results))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d posts could not be updated", failed, len(updates))
+	}
+	return nil
+}
+
+// parseBulkSet parses --set field=value flags
+func parseBulkSet(sets []string) ([]bulkField, error) {
+	var fields []bulkField
+	seen := map[string]bool{}
+	for _, s := range sets {
+		key, value, ok := strings.Cut(s, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --set %q: expected field=value", s)
+		}
+		isBool, known := bulkFields[key]
+		if !known {
+			names := make([]string, 0, len(bulkFields))
+			for name := range bulkFields {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("can't set %s; fields that can be set: %s", key, strings.Join(names, ", "))
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%s is set more than once", key)
+		}
+		seen[key] = true
+
+		f := bulkField{Key: key}
+		switch {
+		case isBool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be true or false, not %q", key, value)
+			}
+			f.Value = b
+		case value != "":
+			f.Value = value
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// bulkValueEqual reports whether a post's current value of a field is
+// already the new one. Ghost returns cleared fields as null.
+func bulkValueEqual(current, value interface{}) bool {
+	if current == "" {
+		current = nil
+	}
+	return current == value
+}
+
+// bulkValueString formats a field's value for the CHANGES column
+func bulkValueString(v interface{}) string {
+	if v == nil || v == "" {
+		return "(none)"
+	}
+	return fmt.Sprint(v)
+}
+
+// tagChanges describes the difference between two tag lists, e.g. +archive
+// and -review
+func tagChanges(before, after []Tag) []string {
+	var changes []string
+	for i := range after {
+		if !hasTag(before, &after[i]) {
+			changes = append(changes, "+"+after[i].Name)
+		}
+	}
+	for i := range before {
+		if !hasTag(after, &before[i]) {
+			changes = append(changes, "-"+before[i].Name)
+		}
+	}
+	return changes
+}
+
+// updateBulkPosts sends the updates, keyed by their index in results,
+// using a pool of workers, and returns how many failed. In a dry run
+// nothing is sent.
+func updateBulkPosts(client *api.Client, results []BulkResult, updates map[int]map[string]interface{}, workers int) int {
+	var (
+		mu     sync.Mutex
+		failed int
+		wg     sync.WaitGroup
+	)
+	jobs := make(chan int)
+	for i := 0; i < max(workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				r := &results[j]
+				if config.FlagDryRun {
+					r.Status = "would update"
+					continue
+				}
+				if _, err := client.Posts.Update(context.Background(), r.ID, updates[j], nil); err != nil {
+					r.Status, r.Error = "failed", err.Error()
+					mu.Lock()
+					failed++
+					mu.Unlock()
+					continue
+				}
+				r.Status = "updated"
+			}
+		}()
+	}
+	for j := range results {
+		if _, ok := updates[j]; ok {
+			jobs <- j
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return failed
+}
+
+// bulkSummary says how many of the matching posts were updated, left
+// unchanged and failed
+func bulkSummary(results []BulkResult) string {
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	parts := []string{fmt.Sprintf("%s matched", plural(len(results), "post"))}
+	for _, status := range []string{"would update", "updated", "failed", "unchanged"} {
+		if n := counts[status]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, status))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/dest"
+)
+
+var postsCalendarCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "Show scheduled and recently published posts",
+	Long: `Show the editorial calendar: every scheduled post and the posts published
+since --since (30 days ago by default), oldest first.
+
+With --ical, write them as an iCalendar file instead, with an event at each
+post's publish time, to import into or subscribe to from a shared calendar.
+Scheduled posts are marked tentative. Use --ical - for stdout.`,
+	Example: `  specter posts calendar
+  specter posts calendar --ical calendar.ics --since 90d`,
+	Args: cobra.NoArgs,
+	RunE: runPostsCalendar,
+}
+
+var (
+	postsCalendarICal  string
+	postsCalendarSince string
+)
+
+func init() {
+	postsCmd.AddCommand(postsCalendarCmd)
+	postsCalendarCmd.Flags().StringVar(&postsCalendarICal, "ical", "", "Write an iCalendar (.ics) file, locally or to an s3:// or gs:// URL")
+	postsCalendarCmd.Flags().StringVar(&postsCalendarSince, "since", "30d", "Include posts published since this date or duration ago")
+	addExecFlag(postsCalendarCmd)
+}
+
+func runPostsCalendar(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	published, err := dateRangeFilter("published_at", postsCalendarSince, "")
+	if err != nil {
+		return err
+	}
+	opts := &api.ListOptions{
+		ReadOptions: api.ReadOptions{Include: []string{"tags", "authors"}},
+		Filter:      "status:scheduled,(status:published+" + published + ")",
+		Order:       "published_at asc",
+	}
+
+	var posts []Post
+	for p, err := range client.Posts.All(context.Background(), opts) {
+		if err != nil {
+			return err
+		}
+		posts = append(posts, p)
+	}
+
+	if postsCalendarICal == "" {
+		return render(posts, postColumns)
+	}
+
+	var buf bytes.Buffer
+	if err := writeICal(&buf, posts, cfg.URL, time.Now()); err != nil {
+		return err
+	}
+	if postsCalendarICal == "-" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	err = dest.WriteTo(context.Background(), postsCalendarICal, func(w io.Writer) error {
+		_, err := buf.WriteTo(w)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("writing calendar: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d posts to %s\n", len(posts), postsCalendarICal)
+	return nil
+}
+
+// writeICal writes posts as an RFC 5545 calendar with one event per post at
+// its publish time
+func writeICal(w io.Writer, posts []Post, siteURL string, now time.Time) error {
+	host := siteURL
+	if u, err := url.Parse(siteURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	const stamp = "20060102T150405Z"
+
+	var b strings.Builder
+	line := func(name, value string) {
+		b.WriteString(foldICalLine(name + ":" + value))
+		b.WriteString("\r\n")
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//specter//Ghost posts//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("X-WR-CALNAME", escapeICal("Posts on "+host))
+
+	for _, p := range posts {
+		start, err := time.Parse(time.RFC3339, p.PublishedAt)
+		if err != nil {
+			continue
+		}
+		uid := p.UUID
+		if uid == "" {
+			uid = p.ID
+		}
+
+		line("BEGIN", "VEVENT")
+		line("UID", uid+"@"+host)
+		line("DTSTAMP", now.UTC().Format(stamp))
+		line("DTSTART", start.UTC().Format(stamp))
+		line("SUMMARY", escapeICal(p.Title))
+		if p.Status == "scheduled" {
+			line("STATUS", "TENTATIVE")
+		} else {
+			line("STATUS", "CONFIRMED")
+		}
+		if p.URL != "" {
+			line("URL", p.URL)
+		}
+
+		desc := []string{"Status: " + p.Status}
+		if len(p.Authors) > 0 {
+			var names []string
+			for _, a := range p.Authors {
+				names = append(names, a.Name)
+			}
+			desc = append(desc, "Authors: "+strings.Join(names, ", "))
+		}
+		if p.Excerpt != "" {
+			desc = append(desc, "", p.Excerpt)
+		}
+		line("DESCRIPTION", escapeICal(strings.Join(desc, "\n")))
+
+		if len(p.Tags) > 0 {
+			var tags []string
+			for _, t := range p.Tags {
+				tags = append(tags, escapeICal(t.Name))
+			}
+			line("CATEGORIES", strings.Join(tags, ","))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeICal escapes a text value for an iCalendar property
+var escapeICal = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+).Replace
+
+// foldICalLine splits a content line into lines of at most 75 octets, as
+// RFC 5545 requires, without breaking UTF-8 sequences
+func foldICalLine(s string) string {
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		n := len(string(r))
+		if width+n > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += n
+	}
+	return b.String()
+}
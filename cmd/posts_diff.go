@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/content"
+	"github.com/teal-bauer/specter/internal/textdiff"
+)
+
+var postsDiffCmd = &cobra.Command{
+	Use:   "diff <id-or-slug> <file.md>",
+	Short: "Show what updating a post from a file would change",
+	Long: `Print a unified diff of what "posts update" with a markdown file would
+change: the content and the frontmatter, such as the title, tags and status.
+
+Both sides are compared as markdown, with the post's HTML converted back
+the way "posts edit" does, so markup that renders the same isn't shown as a
+change. Local images are resolved through the cache of earlier
+--upload-images runs, without uploading anything.
+
+"posts update --dry-run" prints the same diff before the request it would
+send.`,
+	Example: `  specter posts diff my-post-slug my-post.md
+  specter posts diff my-post-slug my-post.md -o json | jq -r .diff`,
+	Args: cobra.ExactArgs(2),
+	RunE: runPostsDiff,
+}
+
+func init() {
+	postsCmd.AddCommand(postsDiffCmd)
+	contentVarsFlags.addFlags(postsDiffCmd)
+	markdownOptionFlags.addFlags(postsDiffCmd)
+}
+
+// PostDiff is the difference between a post and a file to update it from
+type PostDiff struct {
+	Post    string `json:"post"`
+	File    string `json:"file"`
+	Changed bool   `json:"changed"`
+	Diff    string `json:"diff"`
+}
+
+func runPostsDiff(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	existing, err := getPost(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	opts, err := markdownOptions(cfg, client)
+	if err != nil {
+		return err
+	}
+	baseDir := "."
+	if args[1] != "-" {
+		baseDir = filepath.Dir(args[1])
+	}
+	opts.ResolveImage = localImageUploader(cfg, nil, baseDir, loadImageCache())
+	parsed, err := content.ParseFile(args[1], opts)
+	if err != nil {
+		return fmt.Errorf("parsing file: %w", err)
+	}
+	printWarnings(parsed.Warnings)
+
+	post := map[string]interface{}{}
+	if err := applyPostFile(client, post, parsed); err != nil {
+		return err
+	}
+	if parsed.Frontmatter.Status != "" {
+		post["status"] = parsed.Frontmatter.Status
+	}
+
+	diff, err := postUpdateDiff(client, existing.ID, post, args[1])
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(PostDiff{Post: existing.Slug, File: args[1], Changed: diff != "", Diff: diff})
+	}
+	if diff == "" {
+		fmt.Fprintln(os.Stderr, "No changes.")
+		return nil
+	}
+	fmt.Print(diff)
+	return nil
+}
+
+// postUpdateDiff returns a unified diff from the post with the given ID as
+// it is in Ghost to the post after applying the update in post, or "" if
+// the update changes nothing
+func postUpdateDiff(client *api.Client, id string, post map[string]interface{}, file string) (string, error) {
+	remote, err := client.Posts.Get(context.Background(), id, &api.ReadOptions{Include: []string{"tags"}, Formats: []string{"html"}})
+	if err != nil {
+		return "", err
+	}
+	theirs, err := postMarkdown(remote)
+	if err != nil {
+		return "", err
+	}
+	ours, err := updatedPostMarkdown(remote, post)
+	if err != nil {
+		return "", err
+	}
+	return textdiff.Unified("ghost/"+remote.Slug, file, string(theirs), string(ours)), nil
+}
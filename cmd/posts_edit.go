@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/content"
+	"gopkg.in/yaml.v3"
+)
+
+var postsEditCmd = &cobra.Command{
+	Use:   "edit <id-or-slug>",
+	Short: "Edit a post as markdown in your editor",
+	Long: `Open a post as markdown with frontmatter in $VISUAL or $EDITOR (vi if
+neither is set), and update the post when the editor exits. Closing the
+editor without saving changes nothing.
+
+The post's HTML is converted to markdown. Cards and other markup markdown
+can't express are kept as HTML, with raw_html set so they survive.
+
+If the post was changed in Ghost while you were editing, the update is
+refused rather than overwriting those changes, and the file with your edits
+is kept. It is also kept if the update fails for any other reason.`,
+	Example: `  specter posts edit my-post-slug
+  EDITOR='code --wait' specter posts edit my-post-slug`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPostsEdit,
+}
+
+func init() {
+	postsCmd.AddCommand(postsEditCmd)
+	postsEditCmd.Flags().BoolVar(&postsUploadImages, "upload-images", false, "Upload images referenced by local path and use their Ghost URLs")
+}
+
+// editFrontmatter is the frontmatter of a post opened for editing, with
+// the keys that applyPostFile reads back
+type editFrontmatter struct {
+	Title          string   `yaml:"title"`
+	Slug           string   `yaml:"slug"`
+	Status         string   `yaml:"status"`
+	PublishedAt    string   `yaml:"published_at,omitempty"`
+	Tags           []string `yaml:"tags,omitempty"`
+	Featured       bool     `yaml:"featured,omitempty"`
+	Visibility     string   `yaml:"visibility,omitempty"`
+	Excerpt        string   `yaml:"excerpt,omitempty"`
+	MetaTitle      string   `yaml:"meta_title,omitempty"`
+	MetaDesc       string   `yaml:"meta_description,omitempty"`
+	FeatureImg     string   `yaml:"feature_image,omitempty"`
+	CanonicalURL   string   `yaml:"canonical_url,omitempty"`
+	OGTitle        string   `yaml:"og_title,omitempty"`
+	OGDesc         string   `yaml:"og_description,omitempty"`
+	OGImage        string   `yaml:"og_image,omitempty"`
+	TwitterTitle   string   `yaml:"twitter_title,omitempty"`
+	TwitterDesc    string   `yaml:"twitter_description,omitempty"`
+	TwitterImage   string   `yaml:"twitter_image,omitempty"`
+	CustomTemplate string   `yaml:"custom_template,omitempty"`
+	CodeHead       string   `yaml:"codeinjection_head,omitempty"`
+	CodeFoot       string   `yaml:"codeinjection_foot,omitempty"`
+	RawHTML        bool     `yaml:"raw_html,omitempty"`
+}
+
+func runPostsEdit(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	opts := &api.ReadOptions{Include: []string{"tags"}, Formats: []string{"html"}}
+	existing, err := client.Posts.Lookup(context.Background(), args[0], opts)
+	if err != nil {
+		return err
+	}
+
+	original, err := postMarkdown(existing)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp("", "specter-"+existing.Slug+"-*.md")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	_, err = f.Write(original)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	if err := runEditor(path); err != nil {
+		os.Remove(path)
+		return err
+	}
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(edited, original) {
+		os.Remove(path)
+		fmt.Fprintln(os.Stderr, "Edit cancelled, no changes made.")
+		return nil
+	}
+
+	updated, err := updateEditedPost(cfg, client, existing, path)
+	if err != nil {
+		if isUpdateCollision(err) {
+			return fmt.Errorf("%q was changed in Ghost while you were editing it, so it wasn't updated. Your edits are in %s; run 'specter posts edit %s' again and copy them over", existing.Title, path, existing.Slug)
+		}
+		return fmt.Errorf("%w (your edits are in %s)", err, path)
+	}
+	os.Remove(path)
+	savePostBase(cfg, client, updated.ID)
+
+	if config.OutputFormat() == "json" {
+		return printJSON(updated)
+	}
+	fmt.Printf("Updated post: %s\n", updated.Title)
+	fmt.Printf("  ID:     %s\n", updated.ID)
+	fmt.Printf("  Status: %s\n", updated.Status)
+	return nil
+}
+
+// postMarkdown returns a post as a markdown file with frontmatter
+func postMarkdown(p *Post) ([]byte, error) {
+	body, raw := content.HTMLToMarkdown(p.HTML)
+	return postDocument(p, body, raw)
+}
+
+// postDocument returns a post's frontmatter followed by body. raw is
+// whether body has HTML that needs raw_html to be read back.
+func postDocument(p *Post, body string, raw bool) ([]byte, error) {
+	fm := editFrontmatter{
+		Title:          p.Title,
+		Slug:           p.Slug,
+		Status:         p.Status,
+		PublishedAt:    p.PublishedAt,
+		Featured:       p.Featured,
+		Visibility:     p.Visibility,
+		Excerpt:        p.CustomExcerpt,
+		MetaTitle:      p.MetaTitle,
+		MetaDesc:       p.MetaDesc,
+		FeatureImg:     p.FeatureImg,
+		CanonicalURL:   p.CanonicalURL,
+		OGTitle:        p.OGTitle,
+		OGDesc:         p.OGDesc,
+		OGImage:        p.OGImage,
+		TwitterTitle:   p.TwitterTitle,
+		TwitterDesc:    p.TwitterDesc,
+		TwitterImage:   p.TwitterImage,
+		CustomTemplate: p.CustomTemplate,
+		CodeHead:       p.CodeHead,
+		CodeFoot:       p.CodeFoot,
+		RawHTML:        raw,
+	}
+	for _, t := range p.Tags {
+		fm.Tags = append(fm.Tags, t.Name)
+	}
+	data, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	b.Write(data)
+	b.WriteString("---\n\n")
+	b.WriteString(body)
+	return b.Bytes(), nil
+}
+
+// runEditor opens path in the user's editor and waits for it to exit
+func runEditor(path string) error {
+	editor := flagOr(os.Getenv("VISUAL"), os.Getenv("EDITOR"))
+	if strings.TrimSpace(editor) == "" {
+		editor = "vi"
+	}
+	args, err := splitCommandLine(editor)
+	if err != nil {
+		return fmt.Errorf("invalid editor %q: %w", editor, err)
+	}
+
+	c := exec.Command(args[0], append(args[1:], path)...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("running %s: %w", editor, err)
+	}
+	return nil
+}
+
+// updateEditedPost updates a post from its edited markdown file. The
+// updated_at of the version that was opened makes Ghost refuse the update
+// if the post changed in the meantime.
+func updateEditedPost(cfg *config.Config, client *api.Client, existing *Post, path string) (*Post, error) {
+	parsed, err := parsePostFile(cfg, client, path)
+	if err != nil {
+		return nil, err
+	}
+
+	post := map[string]interface{}{
+		"updated_at": existing.UpdatedAt,
+	}
+	if err := applyPostFile(client, post, parsed); err != nil {
+		return nil, err
+	}
+	if s := parsed.Frontmatter.Status; s != "" && s != existing.Status {
+		post["status"] = s
+	}
+	if at := parsed.Frontmatter.PublishedAt; at != "" && at != existing.PublishedAt {
+		post["published_at"] = at
+	}
+	return client.Posts.Update(context.Background(), existing.ID, post, nil)
+}
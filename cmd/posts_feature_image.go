@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var postsSetFeatureImageCmd = &cobra.Command{
+	Use:   "set-feature-image",
+	Short: "Set the same feature image on every post matching a filter",
+	Long: `Set the feature image of every post matching --filter, e.g. for a series
+that shares its artwork. A local --image is uploaded once and the same URL
+is used for all posts; a URL is used as is.
+
+With --missing, posts that already have a feature image are left alone.`,
+	Example: `  specter posts set-feature-image --filter 'tag:podcast' --image cover.jpg
+  specter posts set-feature-image --filter 'tag:podcast' --image cover.jpg --alt 'Podcast logo' --missing`,
+	Args: cobra.NoArgs,
+	RunE: runPostsSetFeatureImage,
+}
+
+var (
+	featureImageFilter  string
+	featureImagePath    string
+	featureImageAlt     string
+	featureImageCaption string
+	featureImageMissing bool
+	featureImageForce   bool
+)
+
+func init() {
+	postsCmd.AddCommand(postsSetFeatureImageCmd)
+	postsSetFeatureImageCmd.Flags().StringVar(&featureImageFilter, "filter", "", "Filter posts to update (required)")
+	postsSetFeatureImageCmd.Flags().StringVar(&featureImagePath, "image", "", "Image file or URL (required)")
+	postsSetFeatureImageCmd.Flags().StringVar(&featureImageAlt, "alt", "", "Alt text of the image")
+	postsSetFeatureImageCmd.Flags().StringVar(&featureImageCaption, "caption", "", "Caption of the image")
+	postsSetFeatureImageCmd.Flags().BoolVar(&featureImageMissing, "missing", false, "Only update posts without a feature image")
+	postsSetFeatureImageCmd.Flags().BoolVar(&featureImageForce, "force", false, "Update without asking for confirmation")
+	addReportFlag(postsSetFeatureImageCmd)
+	_ = postsSetFeatureImageCmd.MarkFlagRequired("filter")
+	_ = postsSetFeatureImageCmd.MarkFlagRequired("image")
+}
+
+// FeatureImageResult is the outcome of setting one post's feature image
+type FeatureImageResult struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func runPostsSetFeatureImage(cmd *cobra.Command, args []string) error {
+	if strings.TrimSpace(featureImageFilter) == "" {
+		return fmt.Errorf("--filter must not be empty")
+	}
+	local := !strings.HasPrefix(featureImagePath, "http://") && !strings.HasPrefix(featureImagePath, "https://")
+	if local {
+		if _, err := os.Stat(featureImagePath); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	filter := featureImageFilter
+	if featureImageMissing {
+		filter = joinFilters(filter, "feature_image:null")
+	}
+	opts := &api.ListOptions{Filter: filter, ReadOptions: api.ReadOptions{Fields: []string{"id", "title", "updated_at", "feature_image"}}}
+	var posts []Post
+	for p, err := range client.Posts.All(context.Background(), opts) {
+		if err != nil {
+			return err
+		}
+		posts = append(posts, p)
+	}
+	if len(posts) == 0 {
+		return fmt.Errorf("no posts match filter: %s", filter)
+	}
+
+	if err := confirmOrAbort(fmt.Sprintf("Set the feature image of %s matching '%s'?", plural(len(posts), "post"), filter), featureImageForce); err != nil {
+		return err
+	}
+
+	// Upload after confirming, so an aborted run leaves no orphan image
+	image := featureImagePath
+	if local {
+		image, err = client.UploadImage(featureImagePath, "")
+		if err != nil {
+			return fmt.Errorf("uploading %s: %w", featureImagePath, err)
+		}
+		if config.OutputFormat() != "json" {
+			fmt.Printf("Uploaded %s: %s\n", featureImagePath, image)
+		}
+	}
+
+	var results []FeatureImageResult
+	failed := 0
+	for _, p := range posts {
+		r := FeatureImageResult{ID: p.ID, Title: p.Title, Status: "updated"}
+		post := map[string]interface{}{
+			"updated_at":    p.UpdatedAt,
+			"feature_image": image,
+		}
+		if featureImageAlt != "" {
+			post["feature_image_alt"] = featureImageAlt
+		}
+		if featureImageCaption != "" {
+			post["feature_image_caption"] = featureImageCaption
+		}
+		if p.FeatureImg == image && featureImageAlt == "" && featureImageCaption == "" {
+			r.Status = "unchanged"
+		} else if _, err := client.Posts.Update(context.Background(), p.ID, post, nil); err != nil {
+			if errors.Is(err, api.ErrDryRun) {
+				return err
+			}
+			r.Status, r.Error = "failed", err.Error()
+			failed++
+		}
+		results = append(results, r)
+	}
+
+	if err := writeReport(cmd, cfg, results, failed); err != nil {
+		return err
+	}
+	err = render(results, []output.Column[FeatureImageResult]{
+		{Header: "ID", Value: func(r FeatureImageResult) string { return r.ID }},
+		{Header: "TITLE", Value: func(r FeatureImageResult) string { return r.Title }, Width: 50},
+		{Header: "STATUS", Value: func(r FeatureImageResult) string { return r.Status }},
+		{Header: "ERROR", Value: func(r FeatureImageResult) string { return orDash(r.Error) }},
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d posts could not be updated", failed, len(posts))
+	}
+	return nil
+}
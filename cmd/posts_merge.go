@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/content"
+	"github.com/teal-bauer/specter/internal/textdiff"
+)
+
+// postBase is the version of a post that specter last wrote, as markdown.
+// A different updated_at in Ghost means someone else changed the post
+// since, and the base is the common ancestor for merging their changes
+// with local ones.
+type postBase struct {
+	UpdatedAt string `json:"updated_at"`
+	Markdown  string `json:"markdown"`
+}
+
+// postBasePath returns where the base of a post is kept, by site and post
+// ID, or "" if there is no cache directory
+func postBasePath(cfg *config.Config, id string) string {
+	dir := config.CacheDir()
+	if dir == "" {
+		return ""
+	}
+	site := sha256.Sum256([]byte(cfg.URL))
+	return filepath.Join(dir, "posts", hex.EncodeToString(site[:8]), id+".json")
+}
+
+func loadPostBase(cfg *config.Config, id string) (*postBase, bool) {
+	path := postBasePath(cfg, id)
+	if path == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var base postBase
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, false
+	}
+	return &base, true
+}
+
+// savePostBase records the post with the given ID as specter just wrote
+// it. Without a base, later updates can't tell whether the post changed in
+// Ghost in the meantime, so failures are only warned about.
+func savePostBase(cfg *config.Config, client *api.Client, id string) {
+	path := postBasePath(cfg, id)
+	if path == "" {
+		return
+	}
+	err := func() error {
+		p, err := client.Posts.Get(context.Background(), id, &api.ReadOptions{Include: []string{"tags"}, Formats: []string{"html"}})
+		if err != nil {
+			return err
+		}
+		md, err := postMarkdown(p)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(postBase{UpdatedAt: p.UpdatedAt, Markdown: string(md)}, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0600)
+	}()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: can't save the post for conflict detection: %v\n", err)
+	}
+}
+
+// isUpdateCollision reports whether Ghost refused an update because the
+// post changed since the updated_at that was sent
+func isUpdateCollision(err error) bool {
+	var apiErr *api.APIError
+	return errors.As(err, &apiErr) && len(apiErr.Errors) > 0 && apiErr.Errors[0].Type == "UpdateCollisionError"
+}
+
+// updatedPostMarkdown returns the markdown of existing as it would be after
+// applying the update in post, for comparison with other versions
+func updatedPostMarkdown(existing *Post, post map[string]interface{}) ([]byte, error) {
+	fields := map[string]interface{}{}
+	data, err := json.Marshal(existing)
+	if err == nil {
+		err = json.Unmarshal(data, &fields)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range post {
+		fields[k] = v
+	}
+	var updated Post
+	data, err = json.Marshal(fields)
+	if err == nil {
+		err = json.Unmarshal(data, &updated)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return postMarkdown(&updated)
+}
+
+// resolvePostConflict handles an update of a post that changed in Ghost
+// since specter last wrote it. It shows both sides' changes and, by
+// --theirs or the user's choice, merges them or overwrites Ghost's; it
+// returns the update to send, or an error to stop.
+func resolvePostConflict(cfg *config.Config, client *api.Client, base *postBase, existing *Post, post map[string]interface{}, file string) (map[string]interface{}, error) {
+	remote, err := client.Posts.Get(context.Background(), existing.ID, &api.ReadOptions{Include: []string{"tags"}, Formats: []string{"html"}})
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := postMarkdown(remote)
+	if err != nil {
+		return nil, err
+	}
+	// Saving in Ghost without changes, e.g. publishing, only moves
+	// updated_at
+	if string(theirs) == base.Markdown {
+		post["updated_at"] = remote.UpdatedAt
+		return post, nil
+	}
+	ours, err := updatedPostMarkdown(remote, post)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(os.Stderr, "%q was changed in Ghost since specter last updated it.\n\nChanges in Ghost:\n%s\nYour changes:\n%s\n",
+		remote.Title, orDash(textdiff.Unified("base", "ghost", base.Markdown, string(theirs))), orDash(textdiff.Unified("base", "local", base.Markdown, string(ours))))
+
+	choice := "theirs"
+	if !postsTheirs {
+		if !interactive() {
+			return nil, fmt.Errorf("%q was changed in Ghost; use --theirs to merge the changes, keeping Ghost's where both changed the same lines, or --force to overwrite them", remote.Title)
+		}
+		fmt.Fprint(os.Stderr, "[m]erge, [f]orce (overwrite the changes in Ghost) or [a]bort? ")
+		choice, err = readChoice(map[string]string{"m": "merge", "f": "force", "a": "abort"})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch choice {
+	case "abort":
+		return nil, fmt.Errorf("aborted")
+	case "force":
+		post["updated_at"] = remote.UpdatedAt
+		return post, nil
+	}
+
+	merged, conflicts := textdiff.Merge(base.Markdown, string(ours), string(theirs), "local", "ghost", postsTheirs)
+	mergedPath := mergedFilePath(file, remote.Slug)
+	if err := os.WriteFile(mergedPath, []byte(merged), 0644); err != nil {
+		return nil, err
+	}
+	if conflicts > 0 {
+		return nil, fmt.Errorf("%s has %s between <<<<<<< and >>>>>>>; fix them and run 'specter posts update %s %s --force'", mergedPath, plural(conflicts, "conflict"), remote.Slug, mergedPath)
+	}
+	fmt.Fprintf(os.Stderr, "Merged the changes; the merged post is in %s\n", mergedPath)
+
+	opts, err := markdownOptions(cfg, client)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := content.Parse([]byte(merged), opts)
+	if err != nil {
+		return nil, fmt.Errorf("parsing merged post: %w", err)
+	}
+	printWarnings(parsed.Warnings)
+	post = map[string]interface{}{"updated_at": remote.UpdatedAt}
+	if err := applyPostFile(client, post, parsed); err != nil {
+		return nil, err
+	}
+	if s := parsed.Frontmatter.Status; s != "" && s != remote.Status {
+		post["status"] = s
+	}
+	if at := parsed.Frontmatter.PublishedAt; at != "" && at != remote.PublishedAt {
+		post["published_at"] = at
+	}
+	return post, nil
+}
+
+// mergedFilePath returns where to write the merge of a post's file: next
+// to it, or in the current directory for stdin
+func mergedFilePath(file, slug string) string {
+	if file == "-" {
+		return slug + ".merged.md"
+	}
+	return strings.TrimSuffix(file, filepath.Ext(file)) + ".merged.md"
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/content"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var postsRerenderCmd = &cobra.Command{
+	Use:   "rerender",
+	Short: "Re-render posts from their markdown files",
+	Long: `Render the markdown files of the posts matching --filter again with the
+current markdown settings and update the posts' content, e.g. to apply
+syntax highlighting to posts published before it was enabled.
+
+Files are found under the --from-source directory and matched to posts as
+by "posts verify": by the slug in their frontmatter, or else by the slug of
+their title. Only the content, and the code injection if the file sets it,
+is updated; titles, tags and other metadata are left as they are in Ghost.
+Posts whose rendering hasn't changed are skipped.
+
+A post that was changed in Ghost since specter last wrote it is skipped
+rather than overwriting those changes, unless --force is given.`,
+	Example: `  specter posts rerender --filter 'tag:tutorials' --from-source content/posts
+  specter posts rerender --filter 'status:published' --from-source . --upload-images --yes`,
+	Args: cobra.NoArgs,
+	RunE: runPostsRerender,
+}
+
+var (
+	rerenderFilter string
+	rerenderSource string
+	rerenderForce  bool
+)
+
+func init() {
+	postsCmd.AddCommand(postsRerenderCmd)
+	postsRerenderCmd.Flags().StringVar(&rerenderFilter, "filter", "", "Filter posts to re-render (required)")
+	postsRerenderCmd.Flags().StringVar(&rerenderSource, "from-source", "", "Directory with the posts' markdown files (required)")
+	postsRerenderCmd.Flags().BoolVar(&postsUploadImages, "upload-images", false, "Upload images referenced by local path and use their Ghost URLs")
+	postsRerenderCmd.Flags().BoolVar(&rerenderForce, "force", false, "Overwrite posts that were changed in Ghost")
+	_ = postsRerenderCmd.MarkFlagRequired("filter")
+	_ = postsRerenderCmd.MarkFlagRequired("from-source")
+	contentVarsFlags.addFlags(postsRerenderCmd)
+	markdownOptionFlags.addFlags(postsRerenderCmd)
+	addReportFlag(postsRerenderCmd)
+}
+
+// RerenderResult is the outcome of re-rendering one post
+type RerenderResult struct {
+	ID     string `json:"id"`
+	Slug   string `json:"slug"`
+	File   string `json:"file,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func runPostsRerender(cmd *cobra.Command, args []string) error {
+	if strings.TrimSpace(rerenderFilter) == "" {
+		return fmt.Errorf("--filter must not be empty")
+	}
+	files, err := markdownFiles(rerenderSource)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	opts := &api.ListOptions{Filter: rerenderFilter, ReadOptions: api.ReadOptions{Fields: []string{"id", "title", "slug", "updated_at", "html", "codeinjection_head", "codeinjection_foot"}, Formats: []string{"html"}}}
+	var posts []Post
+	for p, err := range client.Posts.All(context.Background(), opts) {
+		if err != nil {
+			return err
+		}
+		posts = append(posts, p)
+	}
+	if len(posts) == 0 {
+		return fmt.Errorf("no posts match filter: %s", rerenderFilter)
+	}
+
+	sources, err := postSources(cfg, client, files)
+	if err != nil {
+		return err
+	}
+
+	// Render everything first, so the prompt can say what will change
+	results := make([]RerenderResult, len(posts))
+	rendered := map[string]map[string]interface{}{}
+	for i, p := range posts {
+		r := RerenderResult{ID: p.ID, Slug: p.Slug, File: sources[p.Slug]}
+		base, hasBase := loadPostBase(cfg, p.ID)
+		switch {
+		case r.File == "":
+			r.Status = "no source"
+		case hasBase && base.UpdatedAt != p.UpdatedAt && !rerenderForce:
+			r.Status = "changed in Ghost"
+		default:
+			parsed, err := parsePostFile(cfg, client, r.File)
+			if err != nil {
+				r.Status, r.Error = "failed", err.Error()
+				break
+			}
+			if update := rerenderUpdate(&p, parsed); len(update) > 0 {
+				r.Status = "pending"
+				rendered[p.ID] = update
+			} else {
+				r.Status = "unchanged"
+			}
+		}
+		results[i] = r
+	}
+
+	if len(rendered) > 0 {
+		if err := confirmOrAbort(fmt.Sprintf("Update the content of %s matching '%s'?", plural(len(rendered), "post"), rerenderFilter), false); err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	for i, p := range posts {
+		r := &results[i]
+		post, ok := rendered[p.ID]
+		if !ok {
+			if r.Status == "failed" {
+				failed++
+			}
+			continue
+		}
+		post["updated_at"] = p.UpdatedAt
+		if _, err := client.Posts.Update(context.Background(), p.ID, post, nil); err != nil {
+			if errors.Is(err, api.ErrDryRun) {
+				return err
+			}
+			r.Status, r.Error = "failed", err.Error()
+			failed++
+			continue
+		}
+		r.Status = "updated"
+		savePostBase(cfg, client, p.ID)
+	}
+
+	if err := writeReport(cmd, cfg, results, failed); err != nil {
+		return err
+	}
+	err = render(results, []output.Column[RerenderResult]{
+		{Header: "ID", Value: func(r RerenderResult) string { return r.ID }, Wide: true},
+		{Header: "SLUG", Value: func(r RerenderResult) string { return r.Slug }},
+		{Header: "FILE", Value: func(r RerenderResult) string { return orDash(r.File) }},
+		{Header: "STATUS", Value: func(r RerenderResult) string { return r.Status }},
+		{Header: "ERROR", Value: func(r RerenderResult) string { return orDash(r.Error) }},
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d posts could not be re-rendered", failed, len(posts))
+	}
+	return nil
+}
+
+// rerenderUpdate returns the fields of p that change when rendered from
+// parsed: the content, and the code injection if the file sets it
+func rerenderUpdate(p *Post, parsed *content.ParsedContent) map[string]interface{} {
+	update := map[string]interface{}{}
+	if content.HTMLHash(parsed.HTML) != content.HTMLHash(p.HTML) {
+		update["html"] = parsed.HTML
+	}
+	fm := parsed.Frontmatter
+	if fm.CodeHead != "" && fm.CodeHead != p.CodeHead {
+		update["codeinjection_head"] = fm.CodeHead
+	}
+	if fm.CodeFoot != "" && fm.CodeFoot != p.CodeFoot {
+		update["codeinjection_foot"] = fm.CodeFoot
+	}
+	return update
+}
+
+// postSources maps post slugs to the markdown files they are written from.
+// Nothing is uploaded or looked up while finding the slugs.
+func postSources(cfg *config.Config, client *api.Client, files []string) (map[string]string, error) {
+	opts, err := markdownOptions(cfg, client)
+	if err != nil {
+		return nil, err
+	}
+	opts.ResolveWikiLink = nil
+	opts.ResolveBookmark = nil
+	opts.ResolveEmbed = nil
+
+	sources := map[string]string{}
+	for _, path := range files {
+		parsed, err := content.ParseFile(path, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", path, err)
+			continue
+		}
+		slug := fileSlug(parsed)
+		if other, ok := sources[slug]; ok {
+			fmt.Fprintf(os.Stderr, "Warning: %s and %s are both for %s; using %s\n", other, path, slug, other)
+			continue
+		}
+		sources[slug] = filepath.Clean(path)
+	}
+	return sources, nil
+}
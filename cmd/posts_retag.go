@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+)
+
+var postsRetagCmd = &cobra.Command{
+	Use:   "retag",
+	Short: "Add and remove tags on every post matching a filter",
+	Long: `Add and remove tags on every post matching --filter. --add and --remove
+may be repeated or take a comma-separated list.
+
+Tags to add are found by slug or name, and created if they don't exist yet;
+they are added after the post's other tags, so its primary tag stays the
+same. Tags to remove are matched by slug or name. Posts whose tags don't
+change are skipped.`,
+	Example: `  specter posts retag --filter 'tag:js' --add javascript --remove js
+  specter posts retag --filter 'tag:2024+tag:review' --add archive --yes`,
+	Args: cobra.NoArgs,
+	RunE: runPostsRetag,
+}
+
+var (
+	retagFilter string
+	retagAdd    []string
+	retagRemove []string
+)
+
+func init() {
+	postsCmd.AddCommand(postsRetagCmd)
+	postsRetagCmd.Flags().StringVar(&retagFilter, "filter", "", "Filter posts to retag (required)")
+	postsRetagCmd.Flags().StringSliceVar(&retagAdd, "add", nil, "Tags to add (slug or name)")
+	postsRetagCmd.Flags().StringSliceVar(&retagRemove, "remove", nil, "Tags to remove (slug or name)")
+	_ = postsRetagCmd.MarkFlagRequired("filter")
+	addReportFlag(postsRetagCmd)
+}
+
+func runPostsRetag(cmd *cobra.Command, args []string) error {
+	if strings.TrimSpace(retagFilter) == "" {
+		return fmt.Errorf("--filter must not be empty")
+	}
+	if len(retagAdd) == 0 && len(retagRemove) == 0 {
+		return fmt.Errorf("nothing to do: give --add or --remove")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	add := resolveAddTags(client, retagAdd)
+	remove := tagMatcher(retagRemove)
+	for _, t := range add {
+		if remove(t) {
+			return fmt.Errorf("tag '%s' is both added and removed", t.Name)
+		}
+	}
+
+	items, err := taggedItems(client, retagFilter, false)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("no posts match filter: %s", retagFilter)
+	}
+	if err := confirmOrAbort(fmt.Sprintf("Retag %s matching '%s'?", plural(len(items), "post"), retagFilter), false); err != nil {
+		return err
+	}
+
+	results, failed, err := retagItems(client, items, func(tags []Tag) []Tag {
+		return changeTags(tags, add, remove)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writeReport(cmd, cfg, results, failed); err != nil {
+		return err
+	}
+	if err := renderRetagResults(results); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d posts could not be retagged", failed, len(results))
+	}
+	return nil
+}
+
+// findTagByName returns the tag with the given name, ignoring case
+func findTagByName(client *api.Client, name string) (*Tag, bool) {
+	opts := &api.ListOptions{Filter: fmt.Sprintf("name:'%s'", strings.ReplaceAll(name, "'", `\'`))}
+	list, err := client.Tags.List(context.Background(), opts)
+	if err != nil || len(list.Items) == 0 {
+		return nil, false
+	}
+	return &list.Items[0], true
+}
+
+// resolveAddTags looks up tags to add by slug or name. Tags that don't
+// exist yet are returned by name only, and created by Ghost with the first
+// post they are added to.
+func resolveAddTags(client *api.Client, names []string) []Tag {
+	var add []Tag
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if t, err := getTag(client, name); err == nil {
+			add = append(add, *t)
+		} else if t, ok := findTagByName(client, name); ok {
+			add = append(add, *t)
+		} else {
+			fmt.Fprintf(os.Stderr, "Tag '%s' doesn't exist yet and will be created\n", name)
+			add = append(add, Tag{Name: name})
+		}
+	}
+	return add
+}
+
+// tagMatcher returns a function that reports whether a tag is one of the
+// given slugs or names
+func tagMatcher(refs []string) func(Tag) bool {
+	return func(t Tag) bool {
+		for _, r := range refs {
+			if r = strings.TrimSpace(r); strings.EqualFold(t.Slug, r) || strings.EqualFold(t.Name, r) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// changeTags returns tags without those remove matches, followed by the
+// tags in add they don't have yet, so the primary tag stays the same
+func changeTags(tags, add []Tag, remove func(Tag) bool) []Tag {
+	var out []Tag
+	for _, t := range tags {
+		if !remove(t) {
+			out = append(out, t)
+		}
+	}
+	for i := range add {
+		if !hasTag(out, &add[i]) {
+			out = append(out, add[i])
+		}
+	}
+	return out
+}
@@ -0,0 +1,393 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+var postsRewriteLinksCmd = &cobra.Command{
+	Use:   "rewrite-links",
+	Short: "Rewrite internal links in posts and pages from old to new URLs",
+	Long: `Change links to the site's own content according to a map of old to new
+URLs, in every post and page, e.g. after changing the permalink structure in
+routes.yaml. Redirects keep old links working; this saves readers and search
+engines the extra hop.
+
+The map is a CSV file with two columns, the old and the new URL, and an
+optional header row, or a redirects.yaml in Ghost's format, so that the file
+that sets up the redirects can update the links too. URLs may be full URLs
+on the site or paths such as /2023/05/hello/; links are matched by path, and
+keep their #fragment and query.
+
+Links in text, buttons, bookmarks and HTML and markdown cards are rewritten,
+as are canonical URLs on the site. Nothing else about the content changes.`,
+	Example: `  specter posts rewrite-links --map map.csv
+  specter posts rewrite-links --map redirects.yaml --filter 'tag:news' --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runPostsRewriteLinks,
+}
+
+var (
+	rewriteLinksMap    string
+	rewriteLinksFilter string
+)
+
+func init() {
+	postsCmd.AddCommand(postsRewriteLinksCmd)
+	postsRewriteLinksCmd.Flags().StringVar(&rewriteLinksMap, "map", "", "CSV of old and new URLs, or a redirects.yaml (required)")
+	postsRewriteLinksCmd.Flags().StringVar(&rewriteLinksFilter, "filter", "", "Only rewrite posts and pages matching this filter")
+	_ = postsRewriteLinksCmd.MarkFlagRequired("map")
+	addReportFlag(postsRewriteLinksCmd)
+}
+
+// LinkRewriteResult is the outcome of rewriting the links in one post or
+// page
+type LinkRewriteResult struct {
+	Type   string `json:"type"`
+	ID     string `json:"id"`
+	Slug   string `json:"slug"`
+	Links  int    `json:"links"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func runPostsRewriteLinks(cmd *cobra.Command, args []string) error {
+	mapping, err := loadLinkMap(rewriteLinksMap)
+	if err != nil {
+		return err
+	}
+	if len(mapping) == 0 {
+		return fmt.Errorf("%s has no links to rewrite", rewriteLinksMap)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	siteURL, err := portalSiteURL(client, cfg)
+	if err != nil {
+		return err
+	}
+	rw, err := newLinkRewriter(mapping, siteURL, cfg.URL)
+	if err != nil {
+		return err
+	}
+
+	// Rewrite everything first, so the prompt can say what will change
+	var results []LinkRewriteResult
+	updates := map[int]map[string]interface{}{}
+	params := url.Values{"formats": {"lexical"}, "fields": {"id,slug,updated_at,canonical_url"}}
+	if rewriteLinksFilter != "" {
+		params.Set("filter", rewriteLinksFilter)
+	}
+	for _, resource := range []string{"posts", "pages"} {
+		for page, err := range client.Paginate("/"+resource+"/", params) {
+			if err != nil {
+				return err
+			}
+			var list map[string]json.RawMessage
+			var items []struct {
+				ID           string `json:"id"`
+				Slug         string `json:"slug"`
+				UpdatedAt    string `json:"updated_at"`
+				Lexical      string `json:"lexical"`
+				CanonicalURL string `json:"canonical_url"`
+			}
+			if err := json.Unmarshal(page, &list); err != nil {
+				return err
+			}
+			if err := json.Unmarshal(list[resource], &items); err != nil {
+				return err
+			}
+			for _, it := range items {
+				r := LinkRewriteResult{Type: resource[:len(resource)-1], ID: it.ID, Slug: it.Slug, Status: "unchanged"}
+				update := map[string]interface{}{}
+				if it.Lexical != "" {
+					lexical, n, err := rw.rewriteLexical(it.Lexical)
+					if err != nil {
+						r.Status, r.Error = "failed", err.Error()
+						results = append(results, r)
+						continue
+					}
+					if n > 0 {
+						update["lexical"] = lexical
+						r.Links += n
+					}
+				}
+				if canonical, ok := rw.rewrite(it.CanonicalURL); ok {
+					update["canonical_url"] = canonical
+					r.Links++
+				}
+				if len(update) > 0 {
+					update["updated_at"] = it.UpdatedAt
+					updates[len(results)] = update
+					r.Status = "pending"
+				}
+				results = append(results, r)
+			}
+		}
+	}
+
+	if len(updates) > 0 {
+		posts, pages := 0, 0
+		for i := range updates {
+			if results[i].Type == "post" {
+				posts++
+			} else {
+				pages++
+			}
+		}
+		if err := confirmOrAbort(fmt.Sprintf("Rewrite links in %s?", countPostsAndPages(posts, pages)), false); err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	for i := range results {
+		r := &results[i]
+		update, ok := updates[i]
+		if !ok {
+			if r.Status == "failed" {
+				failed++
+			}
+			continue
+		}
+		if r.Type == "post" {
+			_, err = client.Posts.Update(context.Background(), r.ID, update, nil)
+		} else {
+			_, err = client.Pages.Update(context.Background(), r.ID, update, nil)
+		}
+		if err != nil {
+			if errors.Is(err, api.ErrDryRun) {
+				return err
+			}
+			r.Status, r.Error = "failed", err.Error()
+			failed++
+			continue
+		}
+		r.Status = "updated"
+	}
+
+	if err := writeReport(cmd, cfg, results, failed); err != nil {
+		return err
+	}
+	err = render(results, []output.Column[LinkRewriteResult]{
+		{Header: "TYPE", Value: func(r LinkRewriteResult) string { return r.Type }},
+		{Header: "ID", Value: func(r LinkRewriteResult) string { return r.ID }, Wide: true},
+		{Header: "SLUG", Value: func(r LinkRewriteResult) string { return r.Slug }},
+		{Header: "LINKS", Value: func(r LinkRewriteResult) string { return fmt.Sprint(r.Links) }},
+		{Header: "STATUS", Value: func(r LinkRewriteResult) string { return r.Status }},
+		{Header: "ERROR", Value: func(r LinkRewriteResult) string { return orDash(r.Error) }},
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d posts and pages could not be updated", failed, len(results))
+	}
+	return nil
+}
+
+// loadLinkMap reads a map of old to new URLs from a CSV file or a Ghost
+// redirects.yaml
+func loadLinkMap(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	mapping := map[string]string{}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var redirects map[int]map[string]string
+		if err := yaml.Unmarshal(data, &redirects); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		codes := make([]int, 0, len(redirects))
+		for code := range redirects {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			for from, to := range redirects[code] {
+				// Ghost's redirects are regular expressions; only those
+				// that name a single path can be rewritten
+				from = strings.TrimSuffix(strings.TrimPrefix(from, "^"), "$")
+				if strings.ContainsAny(from, `()[]{}*+?|\`) {
+					fmt.Fprintf(os.Stderr, "Warning: skipping redirect %s: it is a pattern, not a URL\n", from)
+					continue
+				}
+				if _, ok := mapping[from]; !ok {
+					mapping[from] = to
+				}
+			}
+		}
+	default:
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV: %w", err)
+		}
+		for i, rec := range records {
+			if len(rec) < 2 {
+				return nil, fmt.Errorf("%s line %d: need an old and a new URL", path, i+1)
+			}
+			from, to := strings.TrimSpace(rec[0]), strings.TrimSpace(rec[1])
+			if i == 0 && !strings.Contains(from, "/") {
+				// A header row
+				continue
+			}
+			if from == "" || to == "" {
+				continue
+			}
+			mapping[from] = to
+		}
+	}
+	return mapping, nil
+}
+
+// linkRewriter rewrites links to the site according to a map of old to
+// new URLs
+type linkRewriter struct {
+	base  *url.URL
+	hosts map[string]bool
+	// paths maps the old paths, as sitemapPath gives them, to new URLs
+	paths map[string]string
+}
+
+// newLinkRewriter returns a linkRewriter for the site at the given URLs,
+// the first of which new paths are made absolute with when the link was
+func newLinkRewriter(mapping map[string]string, urls ...string) (*linkRewriter, error) {
+	base, err := url.Parse(strings.TrimSuffix(urls[0], "/") + "/")
+	if err != nil {
+		return nil, err
+	}
+	rw := &linkRewriter{base: base, hosts: map[string]bool{}, paths: map[string]string{}}
+	for _, u := range urls {
+		if parsed, err := url.Parse(u); err == nil {
+			rw.hosts[parsed.Host] = true
+		}
+	}
+	for from, to := range mapping {
+		u, err := base.Parse(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL %s: %w", from, err)
+		}
+		if !rw.hosts[u.Host] {
+			return nil, fmt.Errorf("%s isn't on the site", from)
+		}
+		rw.paths[sitemapPath(u.Path)] = to
+	}
+	return rw, nil
+}
+
+// rewrite returns the new URL of a link, if the map has one
+func (rw *linkRewriter) rewrite(link string) (string, bool) {
+	link = strings.TrimSpace(link)
+	if link == "" || strings.HasPrefix(link, "#") {
+		return "", false
+	}
+	u, err := rw.base.Parse(link)
+	if err != nil || !rw.hosts[u.Host] {
+		return "", false
+	}
+	to, ok := rw.paths[sitemapPath(u.Path)]
+	if !ok {
+		return "", false
+	}
+	target, err := rw.base.Parse(to)
+	if err != nil {
+		return "", false
+	}
+	if target.RawQuery == "" {
+		target.RawQuery = u.RawQuery
+	}
+	if target.Fragment == "" {
+		target.Fragment = u.Fragment
+	}
+	// Keep links as they were written: paths stay paths
+	if strings.HasPrefix(link, "/") && rw.hosts[target.Host] {
+		target.Scheme, target.Host = "", ""
+	}
+	return target.String(), true
+}
+
+// markdownLinkRe matches a link's target in markdown
+var markdownLinkRe = regexp.MustCompile(`\]\(([^)\s]+)`)
+
+// rewriteLexical rewrites the links in a Lexical document, and returns it
+// and how many links changed
+func (rw *linkRewriter) rewriteLexical(doc string) (string, int, error) {
+	var root interface{}
+	if err := json.Unmarshal([]byte(doc), &root); err != nil {
+		return "", 0, fmt.Errorf("parsing lexical: %w", err)
+	}
+	n := 0
+	replaceIn := func(s string, re *regexp.Regexp, prefix, suffix string) string {
+		return re.ReplaceAllStringFunc(s, func(m string) string {
+			link := re.FindStringSubmatch(m)[1]
+			if to, ok := rw.rewrite(link); ok {
+				n++
+				return prefix + to + suffix
+			}
+			return m
+		})
+	}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, val := range v {
+				s, ok := val.(string)
+				if !ok {
+					walk(val)
+					continue
+				}
+				switch {
+				case key == "url" || strings.HasSuffix(key, "Url"):
+					if to, ok := rw.rewrite(s); ok {
+						v[key] = to
+						n++
+					}
+				case key == "html":
+					v[key] = replaceIn(s, hrefRe, `href="`, `"`)
+				case key == "markdown":
+					v[key] = replaceIn(s, markdownLinkRe, "](", "")
+				}
+			}
+		case []interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(root)
+	if n == 0 {
+		return doc, 0, nil
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(root); err != nil {
+		return "", 0, err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), n, nil
+}
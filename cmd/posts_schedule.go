@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+)
+
+var postsScheduleCmd = &cobra.Command{
+	Use:   "schedule <file.md>",
+	Short: "Create a post from a markdown file, scheduled to publish later",
+	Long: `Create a post from a markdown file, scheduled to be published at --at.
+
+--at takes a weekday, optionally after "next", or "today" or "tomorrow",
+with an optional time of day, e.g. "next tuesday 9am", "friday at 14:30" or
+"tomorrow 8am"; a time of day alone, e.g. "9pm"; a relative time such as
+"in 2 hours" or "in 3 days"; a date with an optional time, e.g.
+"2026-10-23 09:00"; or an RFC 3339 timestamp. "tuesday" may be today if
+the time is still ahead, while "next tuesday" never is.
+
+Times without an offset are in the timezone of --tz, else the profile's
+timezone setting, else the local one. The time must be in the future. The
+resolved time is printed before the post is created.`,
+	Example: `  specter posts schedule launch.md --at 'next tuesday 9am'
+  specter posts schedule launch.md --at 'friday 14:30' --tz America/New_York
+  specter posts schedule digest.md --at '2026-10-23T09:00:00Z' --newsletter weekly`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPostsSchedule,
+}
+
+var (
+	postsScheduleAt string
+	postsScheduleTZ string
+)
+
+func init() {
+	postsCmd.AddCommand(postsScheduleCmd)
+	postsScheduleCmd.Flags().StringVar(&postsScheduleAt, "at", "", "When to publish, e.g. 'next tuesday 9am' or '2026-10-23 09:00' (required)")
+	postsScheduleCmd.Flags().StringVar(&postsScheduleTZ, "tz", "", "Timezone of --at, e.g. Europe/Berlin (default the profile's timezone, or local)")
+	postsScheduleCmd.Flags().StringVar(&postsNewsletter, "newsletter", "", "Send by email through this newsletter when published (slug)")
+	postsScheduleCmd.Flags().StringVar(&postsEmailSegment, "email-segment", "", "Members to email, e.g. 'status:free' or 'status:-free' (default all)")
+	postsScheduleCmd.Flags().BoolVar(&postsUploadImages, "upload-images", false, "Upload images referenced by local path and use their Ghost URLs")
+	_ = postsScheduleCmd.MarkFlagRequired("at")
+	contentVarsFlags.addFlags(postsScheduleCmd)
+	markdownOptionFlags.addFlags(postsScheduleCmd)
+	writeOpenFlags.addFlags(postsScheduleCmd)
+}
+
+func runPostsSchedule(cmd *cobra.Command, args []string) error {
+	if err := writeOpenFlags.validate(); err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	loc, err := scheduleLocation(cfg, postsScheduleTZ)
+	if err != nil {
+		return err
+	}
+	now := time.Now().In(loc)
+	at, err := parseSchedule(postsScheduleAt, now)
+	if err != nil {
+		return fmt.Errorf("invalid --at: %w", err)
+	}
+	at = at.In(loc)
+	if !at.After(now) {
+		return fmt.Errorf("%s is in the past", at.Format("Mon 2006-01-02 15:04 MST"))
+	}
+	fmt.Fprintf(os.Stderr, "Scheduling for %s (%s)\n", at.Format("Mon 2006-01-02 15:04 MST"), at.UTC().Format("15:04 UTC"))
+
+	client := newClient(cfg)
+	parsed, err := parsePostFile(cfg, client, args[0])
+	if err != nil {
+		return err
+	}
+	return createPost(cfg, client, parsed, "scheduled", at.UTC().Format(time.RFC3339))
+}
+
+// scheduleLocation returns the timezone that schedule times without an
+// offset are in: tz if set, else the profile's timezone, else the local one
+func scheduleLocation(cfg *config.Config, tz string) (*time.Location, error) {
+	if tz == "" {
+		tz = cfg.Timezone
+	}
+	if tz == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil || tz == "Local" {
+		return nil, fmt.Errorf("unknown timezone: %s (expected an IANA name like Europe/Berlin)", tz)
+	}
+	return loc, nil
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var postsSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Find posts by title, slug or content",
+	Long: `Find posts whose title or slug contains every word of the query, best
+matches first. Matching is case-insensitive and done by Ghost, so it is
+fast on big sites.
+
+With --content, the text of every post is fetched and searched as well,
+and results show where in the text the query matched. This reads the
+whole site, so it takes longer.
+
+Titles that match the whole query rank above those that only contain its
+words, and title matches rank above slug and content matches.`,
+	Example: `  specter posts search 'annual report'
+  specter posts search kubernetes --content
+  specter posts search 'annual report' -o json | jq -r '.[0].id'`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPostsSearch,
+}
+
+var (
+	postsSearchContent bool
+	postsSearchLimit   int
+)
+
+func init() {
+	postsCmd.AddCommand(postsSearchCmd)
+	postsSearchCmd.Flags().BoolVar(&postsSearchContent, "content", false, "Also search the text of the posts")
+	postsSearchCmd.Flags().IntVar(&postsSearchLimit, "limit", 20, "Maximum number of results (0 for all)")
+	addExecFlag(postsSearchCmd)
+}
+
+// PostSearchResult is a post that matches a search
+type PostSearchResult struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Slug        string `json:"slug"`
+	Status      string `json:"status"`
+	PublishedAt string `json:"published_at,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Score       int    `json:"score"`
+	// Match lists where the query matched: title, slug or content
+	Match   string `json:"match"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+func runPostsSearch(cmd *cobra.Command, args []string) error {
+	terms := strings.Fields(strings.ToLower(args[0]))
+	if len(terms) == 0 {
+		return fmt.Errorf("empty query")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	opts := &api.ListOptions{Order: "published_at desc"}
+	if postsSearchContent {
+		opts.Formats = []string{"plaintext"}
+	} else {
+		opts.Filter = searchFilter(terms)
+	}
+
+	var results []PostSearchResult
+	for p, err := range client.Posts.All(context.Background(), opts) {
+		if err != nil {
+			return err
+		}
+		if r, ok := scorePost(p, args[0], terms); ok {
+			results = append(results, r)
+		}
+	}
+
+	// Posts.All keeps Ghost's order, newest first, among equal scores
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if postsSearchLimit > 0 && len(results) > postsSearchLimit {
+		results = results[:postsSearchLimit]
+	}
+
+	return render(results, []output.Column[PostSearchResult]{
+		{Header: "ID", Value: func(r PostSearchResult) string { return r.ID }},
+		{Header: "TITLE", Value: func(r PostSearchResult) string { return r.Title }, Width: 50},
+		{Header: "STATUS", Value: func(r PostSearchResult) string { return r.Status }},
+		{Header: "MATCH", Value: func(r PostSearchResult) string { return r.Match }},
+		{Header: "SNIPPET", Value: func(r PostSearchResult) string { return orDash(r.Snippet) }, Width: 60},
+		{Header: "SCORE", Value: func(r PostSearchResult) string { return strconv.Itoa(r.Score) }, Wide: true},
+		{Header: "SLUG", Value: func(r PostSearchResult) string { return r.Slug }, Wide: true},
+	})
+}
+
+// searchFilter returns the NQL filter for posts whose title or slug
+// contains every term
+func searchFilter(terms []string) string {
+	var filters []string
+	for _, t := range terms {
+		q := strings.ReplaceAll(t, "'", "\\'")
+		filters = append(filters, fmt.Sprintf("title:~'%s',slug:~'%s'", q, q))
+	}
+	return joinFilters(filters...)
+}
+
+// scorePost ranks how well p matches the query, and reports whether every
+// term occurs in its title, slug or text
+func scorePost(p Post, query string, terms []string) (PostSearchResult, bool) {
+	title := strings.ToLower(p.Title)
+	text := strings.ToLower(p.Plaintext)
+	r := PostSearchResult{
+		ID:          p.ID,
+		Title:       p.Title,
+		Slug:        p.Slug,
+		Status:      p.Status,
+		PublishedAt: p.PublishedAt,
+		URL:         p.URL,
+	}
+
+	var where []string
+	note := func(place string) {
+		if !slices.Contains(where, place) {
+			where = append(where, place)
+		}
+	}
+
+	phrase := strings.ToLower(strings.Join(strings.Fields(query), " "))
+	switch {
+	case title == phrase:
+		r.Score += 100
+	case strings.Contains(title, phrase):
+		r.Score += 50
+	}
+	for _, t := range terms {
+		found := false
+		if strings.Contains(title, t) {
+			r.Score += 10
+			note("title")
+			found = true
+		}
+		if strings.Contains(p.Slug, t) {
+			r.Score += 5
+			note("slug")
+			found = true
+		}
+		if n := strings.Count(text, t); n > 0 {
+			r.Score += min(n, 10)
+			note("content")
+			found = true
+		}
+		if !found {
+			return r, false
+		}
+	}
+
+	if i := strings.Index(text, terms[0]); i >= 0 {
+		r.Snippet = snippet(p.Plaintext, i, len(terms[0]))
+	}
+	r.Match = strings.Join(where, ",")
+	return r, true
+}
+
+// snippet returns the text around the match at s[i:i+n] on one line,
+// marking cut-off ends with ellipses
+func snippet(s string, i, n int) string {
+	const around = 40
+	// i comes from the lowercased text, which can differ in length
+	i = min(i, len(s))
+	start, end := max(i-around, 0), min(i+n+around, len(s))
+	// Don't cut UTF-8 sequences
+	for start > 0 && !utf8.RuneStart(s[start]) {
+		start--
+	}
+	for end < len(s) && !utf8.RuneStart(s[end]) {
+		end++
+	}
+	out := strings.Join(strings.Fields(s[start:end]), " ")
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(s) {
+		out += "…"
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+)
+
+var postsStatsCmd = &cobra.Command{
+	Use:   "stats <id-or-slug>",
+	Short: "Show email and engagement stats for a post",
+	Long: `Show the email performance of a post (delivered, opened, clicked), reader
+feedback, and the signups and paid conversions attributed to it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPostsStats,
+}
+
+func init() {
+	postsCmd.AddCommand(postsStatsCmd)
+}
+
+// PostStats is the email performance and attribution of a post
+type PostStats struct {
+	ID               string     `json:"id"`
+	Title            string     `json:"title"`
+	Email            *EmailInfo `json:"email,omitempty"`
+	Clicks           int        `json:"clicks"`
+	PositiveFeedback int        `json:"positive_feedback"`
+	NegativeFeedback int        `json:"negative_feedback"`
+	Sentiment        int        `json:"sentiment"`
+	Signups          int        `json:"signups"`
+	PaidConversions  int        `json:"paid_conversions"`
+}
+
+// EmailInfo is the delivery status of a post sent as a newsletter
+type EmailInfo struct {
+	Status         string `json:"status"`
+	SubmittedAt    string `json:"submitted_at,omitempty"`
+	EmailCount     int    `json:"email_count"`
+	DeliveredCount int    `json:"delivered_count"`
+	OpenedCount    int    `json:"opened_count"`
+	FailedCount    int    `json:"failed_count"`
+}
+
+func runPostsStats(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	existing, err := getPost(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	stats, err := getPostStats(client, existing.ID)
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(stats)
+	}
+
+	fmt.Printf("Title:       %s\n", stats.Title)
+	if e := stats.Email; e != nil {
+		fmt.Printf("Email:       %s, sent %s\n", e.Status, e.SubmittedAt)
+		fmt.Printf("  Sent:      %d\n", e.EmailCount)
+		fmt.Printf("  Delivered: %d\n", e.DeliveredCount)
+		fmt.Printf("  Opened:    %d (%s)\n", e.OpenedCount, percent(e.OpenedCount, e.DeliveredCount))
+		fmt.Printf("  Clicked:   %d (%s)\n", stats.Clicks, percent(stats.Clicks, e.DeliveredCount))
+		if e.FailedCount > 0 {
+			fmt.Printf("  Failed:    %d\n", e.FailedCount)
+		}
+	} else {
+		fmt.Println("Email:       not sent")
+		fmt.Printf("Clicks:      %d\n", stats.Clicks)
+	}
+	fmt.Printf("Feedback:    %d positive, %d negative (sentiment %d%%)\n",
+		stats.PositiveFeedback, stats.NegativeFeedback, stats.Sentiment)
+	fmt.Printf("Signups:     %d\n", stats.Signups)
+	fmt.Printf("Paid:        %d\n", stats.PaidConversions)
+	return nil
+}
+
+// getPostStats fetches a post with its email and count relations
+func getPostStats(client *api.Client, id string) (*PostStats, error) {
+	params := url.Values{}
+	params.Set("include", "email,count.clicks,count.signups,count.paid_conversions,count.positive_feedback,count.negative_feedback,sentiment")
+
+	data, err := client.Get(fmt.Sprintf("/posts/%s/", id), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Posts []struct {
+			ID        string     `json:"id"`
+			Title     string     `json:"title"`
+			Email     *EmailInfo `json:"email"`
+			Sentiment int        `json:"sentiment"`
+			Count     struct {
+				Clicks           int `json:"clicks"`
+				Signups          int `json:"signups"`
+				PaidConversions  int `json:"paid_conversions"`
+				PositiveFeedback int `json:"positive_feedback"`
+				NegativeFeedback int `json:"negative_feedback"`
+			} `json:"count"`
+		} `json:"posts"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+	if len(resp.Posts) == 0 {
+		return nil, fmt.Errorf("post not found: %s", id)
+	}
+
+	p := resp.Posts[0]
+	return &PostStats{
+		ID:               p.ID,
+		Title:            p.Title,
+		Email:            p.Email,
+		Clicks:           p.Count.Clicks,
+		PositiveFeedback: p.Count.PositiveFeedback,
+		NegativeFeedback: p.Count.NegativeFeedback,
+		Sentiment:        p.Sentiment,
+		Signups:          p.Count.Signups,
+		PaidConversions:  p.Count.PaidConversions,
+	}, nil
+}
+
+// percent formats n as a percentage of total
+func percent(n, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/content"
+)
+
+var postsNewFromTemplateCmd = &cobra.Command{
+	Use:   "new-from-template <template.md>",
+	Short: "Create the next post of a recurring series from a template",
+	Long: `Create a post from a markdown template, for recurring series such as a
+weekly digest. The file is expanded with --var and --vars (see "Templates"
+in the README), plus publish_date, the date it is scheduled for
+(2006-01-02), or today's date without --schedule.
+
+Unless the frontmatter sets a slug, one is made from the title. If a post
+already has that slug, the publish date is appended, e.g.
+weekly-digest-2026-10-23.
+
+--schedule takes a weekday or "today"/"tomorrow" with an optional time of
+day, e.g. "friday 9am" or "mon 14:30", meaning the next such time in the
+profile's timezone setting, or else the local timezone. A date
+(2006-01-02), "2006-01-02 15:04", an RFC 3339 timestamp and the other forms
+"posts schedule --at" takes work as well. Without --schedule the post is
+created as a draft.`,
+	Example: `  specter posts new-from-template weekly.md --vars vars.yaml --schedule 'friday 9am'
+  specter posts new-from-template weekly.md --var issue=43 --schedule 'friday 9am' --newsletter weekly`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPostsNewFromTemplate,
+}
+
+var postsSchedule string
+
+func init() {
+	postsCmd.AddCommand(postsNewFromTemplateCmd)
+	postsNewFromTemplateCmd.Flags().StringVar(&postsSchedule, "schedule", "", "When to publish, e.g. 'friday 9am' or '2026-10-23 09:00'")
+	postsNewFromTemplateCmd.Flags().StringVar(&postsNewsletter, "newsletter", "", "Send by email through this newsletter when published (slug)")
+	postsNewFromTemplateCmd.Flags().StringVar(&postsEmailSegment, "email-segment", "", "Members to email, e.g. 'status:free' or 'status:-free' (default all)")
+	postsNewFromTemplateCmd.Flags().BoolVar(&postsUploadImages, "upload-images", false, "Upload images referenced by local path and use their Ghost URLs")
+	contentVarsFlags.addFlags(postsNewFromTemplateCmd)
+	markdownOptionFlags.addFlags(postsNewFromTemplateCmd)
+	writeOpenFlags.addFlags(postsNewFromTemplateCmd)
+}
+
+func runPostsNewFromTemplate(cmd *cobra.Command, args []string) error {
+	if err := writeOpenFlags.validate(); err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	loc, err := scheduleLocation(cfg, "")
+	if err != nil {
+		return err
+	}
+	publishDate := time.Now().In(loc)
+	status, publishAt := "draft", ""
+	if postsSchedule != "" {
+		t, err := parseSchedule(postsSchedule, publishDate)
+		if err != nil {
+			return fmt.Errorf("invalid --schedule: %w", err)
+		}
+		publishDate = t
+		status = "scheduled"
+		publishAt = t.UTC().Format(time.RFC3339)
+	}
+
+	contentVarsFlags.defaults = map[string]string{
+		"publish_date": publishDate.Format("2006-01-02"),
+	}
+	parsed, err := parsePostFile(cfg, client, args[0])
+	if err != nil {
+		return err
+	}
+
+	if parsed.Frontmatter.Slug == "" {
+		if parsed.Frontmatter.Title == "" {
+			return fmt.Errorf("the template needs a title to make a slug from")
+		}
+		slug := content.Slugify(parsed.Frontmatter.Title)
+		if _, err := getPost(client, slug); err == nil {
+			slug += "-" + publishDate.Format("2006-01-02")
+		}
+		parsed.Frontmatter.Slug = slug
+	}
+
+	return createPost(cfg, client, parsed, status, publishAt)
+}
+
+// scheduleTimeRe matches a time of day such as 9am, 9:30pm or 14:00
+var scheduleTimeRe = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+
+// scheduleInRe matches a time relative to now, such as "in 2 hours"
+var scheduleInRe = regexp.MustCompile(`^in (\d+) ?(m|mins?|minutes?|h|hrs?|hours?|d|days?|w|weeks?)$`)
+
+// parseSchedule parses a publish time for --schedule and --at: a weekday,
+// optionally after "next", or "today" or "tomorrow", followed by an
+// optional time of day (midnight if omitted); a time of day alone; "in"
+// and a number of minutes, hours, days or weeks; a date with an optional
+// time; or an RFC 3339 timestamp. Times without an offset are in now's
+// location. A weekday or time of day alone means the next such time after
+// now, which may be today; "next" skips today.
+func parseSchedule(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, strings.ToUpper(s)); err == nil {
+		return t, nil
+	}
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	loc := now.Location()
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02t15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	if m := scheduleInRe.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2][0] {
+		case 'm':
+			return now.Add(time.Duration(n) * time.Minute), nil
+		case 'h':
+			return now.Add(time.Duration(n) * time.Hour), nil
+		case 'd':
+			return now.AddDate(0, 0, n), nil
+		}
+		return now.AddDate(0, 0, 7*n), nil
+	}
+
+	var words []string
+	for _, w := range strings.Fields(s) {
+		switch {
+		case w == "at" || w == "on":
+		case (w == "am" || w == "pm") && len(words) > 0:
+			// "9 am"
+			words[len(words)-1] += w
+		default:
+			words = append(words, w)
+		}
+	}
+	next := len(words) > 0 && words[0] == "next"
+	if next {
+		words = words[1:]
+	}
+	var day, clock string
+	switch len(words) {
+	case 1:
+		if _, _, err := parseClock(words[0]); err == nil && !next {
+			clock = words[0]
+		} else {
+			day = words[0]
+		}
+	case 2:
+		day, clock = words[0], words[1]
+	default:
+		return time.Time{}, fmt.Errorf("%q is not a weekday, time of day, date (2006-01-02) or timestamp (RFC 3339)", s)
+	}
+
+	hour, minute := 0, 0
+	if clock != "" {
+		var err error
+		if hour, minute, err = parseClock(clock); err != nil {
+			return time.Time{}, err
+		}
+	}
+	at := func(d time.Time) time.Time {
+		return time.Date(d.Year(), d.Month(), d.Day(), hour, minute, 0, 0, loc)
+	}
+
+	if date, err := time.ParseInLocation("2006-01-02", day, loc); err == nil && !next {
+		return at(date), nil
+	}
+	switch day {
+	case "":
+		t := at(now)
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	case "today", "tomorrow":
+		if next {
+			return time.Time{}, fmt.Errorf("%q: use \"next\" with a weekday", s)
+		}
+		if day == "tomorrow" {
+			return at(now.AddDate(0, 0, 1)), nil
+		}
+		return at(now), nil
+	}
+	weekday, ok := parseWeekday(day)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%q is not a weekday, time of day, date (2006-01-02) or timestamp (RFC 3339)", s)
+	}
+	days := (int(weekday) - int(now.Weekday()) + 7) % 7
+	if days == 0 && next {
+		days = 7
+	}
+	t := at(now.AddDate(0, 0, days))
+	if !t.After(now) {
+		t = t.AddDate(0, 0, 7)
+	}
+	return t, nil
+}
+
+// parseClock parses a time of day such as 9am, 9:30pm or 14:00
+func parseClock(s string) (hour, minute int, err error) {
+	m := scheduleTimeRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, fmt.Errorf("%q is not a time of day (e.g. 9am, 9:30pm or 14:00)", s)
+	}
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("%q is not a time of day", s)
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, fmt.Errorf("%q is not a time of day", s)
+	}
+	return hour, minute, nil
+}
+
+// parseWeekday parses a weekday name, full or abbreviated to three letters
+func parseWeekday(s string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/content"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var postsVerifyCmd = &cobra.Command{
+	Use:   "verify <dir>",
+	Short: "Check that local markdown files match the posts on the site",
+	Long: `Render every markdown file under a directory as "posts create" would and
+compare it with the post on the site, to confirm that a content repository
+and the site are in sync.
+
+Files are matched to posts by the slug in their frontmatter, or else by the
+slug of their title. Content is compared by a hash of its text and
+markup, as markdown, so that differences in how Ghost stores the HTML, e.g.
+entities or attribute order, don't count. Local images are resolved through the cache of earlier
+--upload-images runs, without uploading anything.
+
+Exits with an error if any file differs or has no post.`,
+	Example: `  specter posts verify content/posts
+  specter posts verify . -o json | jq '.[] | select(.status != "in sync")'`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPostsVerify,
+}
+
+func init() {
+	postsCmd.AddCommand(postsVerifyCmd)
+	markdownOptionFlags.addFlags(postsVerifyCmd)
+	addReportFlag(postsVerifyCmd)
+}
+
+// PostVerifyResult is the comparison of a local file with its post
+type PostVerifyResult struct {
+	File       string `json:"file"`
+	Slug       string `json:"slug,omitempty"`
+	Status     string `json:"status"`
+	LocalHash  string `json:"local_hash,omitempty"`
+	RemoteHash string `json:"remote_hash,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func runPostsVerify(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	files, err := markdownFiles(args[0])
+	if err != nil {
+		return err
+	}
+
+	posts := map[string]Post{}
+	opts := &api.ListOptions{ReadOptions: api.ReadOptions{Formats: []string{"html"}}}
+	for p, err := range client.Posts.All(context.Background(), opts) {
+		if err != nil {
+			return err
+		}
+		posts[p.Slug] = p
+	}
+
+	mdOpts, err := markdownOptions(cfg, client)
+	if err != nil {
+		return err
+	}
+	cache := loadImageCache()
+
+	var results []PostVerifyResult
+	drifted := 0
+	for _, path := range files {
+		result := verifyPostFile(cfg, path, mdOpts, cache, posts)
+		if result.Status != "in sync" {
+			drifted++
+		}
+		results = append(results, result)
+	}
+
+	if err := writeReport(cmd, cfg, results, drifted); err != nil {
+		return err
+	}
+	err = render(results, []output.Column[PostVerifyResult]{
+		{Header: "FILE", Value: func(r PostVerifyResult) string { return r.File }},
+		{Header: "SLUG", Value: func(r PostVerifyResult) string { return orDash(r.Slug) }},
+		{Header: "STATUS", Value: func(r PostVerifyResult) string {
+			if r.Error != "" {
+				return r.Error
+			}
+			return r.Status
+		}},
+		{Header: "LOCAL", Value: func(r PostVerifyResult) string { return orDash(shortHash(r.LocalHash)) }, Wide: true},
+		{Header: "REMOTE", Value: func(r PostVerifyResult) string { return orDash(shortHash(r.RemoteHash)) }, Wide: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	if drifted > 0 {
+		return fmt.Errorf("%d of %d files are out of sync", drifted, len(results))
+	}
+	return nil
+}
+
+// verifyPostFile renders a markdown file and compares it with the post of
+// the same slug in posts
+func verifyPostFile(cfg *config.Config, path string, opts content.Options, cache *imageCache, posts map[string]Post) PostVerifyResult {
+	result := PostVerifyResult{File: path}
+
+	opts.ResolveImage = localImageUploader(cfg, nil, filepath.Dir(path), cache)
+	parsed, err := content.ParseFile(path, opts)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Slug = fileSlug(parsed)
+	result.LocalHash = content.ContentHash(parsed.HTML)
+
+	post, ok := posts[result.Slug]
+	switch {
+	case !ok:
+		result.Status = "not on site"
+	case content.ContentHash(post.HTML) != result.LocalHash:
+		result.RemoteHash = content.ContentHash(post.HTML)
+		result.Status = "content differs"
+	case parsed.Frontmatter.Title != "" && parsed.Frontmatter.Title != post.Title:
+		result.RemoteHash = result.LocalHash
+		result.Status = "title differs"
+	default:
+		result.RemoteHash = result.LocalHash
+		result.Status = "in sync"
+	}
+	return result
+}
+
+// markdownFiles returns the markdown files under dir, skipping hidden
+// directories
+func markdownFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if ext := strings.ToLower(filepath.Ext(path)); !d.IsDir() && (ext == ".md" || ext == ".markdown") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no markdown files in %s", dir)
+	}
+	return files, nil
+}
+
+// fileSlug returns the slug of the post a file is for: the one in its
+// frontmatter, or else the slug of its title
+func fileSlug(parsed *content.ParsedContent) string {
+	if parsed.Frontmatter.Slug != "" {
+		return parsed.Frontmatter.Slug
+	}
+	return content.Slugify(parsed.Frontmatter.Title)
+}
+
+// shortHash abbreviates a hex hash for tables
+func shortHash(h string) string {
+	if len(h) > 12 {
+		return h[:12]
+	}
+	return h
+}
@@ -1,24 +1,60 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
 )
 
 var profilesCmd = &cobra.Command{
 	Use:     "profiles",
 	Aliases: []string{"profile"},
-	Short:   "List configured profiles",
+	Short:   "List and manage configured profiles",
+	Args:    cobra.NoArgs,
 	RunE:    runProfilesList,
 }
 
+var profilesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured profiles",
+	Args:  cobra.NoArgs,
+	RunE:  runProfilesList,
+}
+
+var profilesRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a profile and its stored admin key",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runProfilesRemove,
+}
+
+var profilesRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a profile",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runProfilesRename,
+}
+
+var profilesSetDefaultCmd = &cobra.Command{
+	Use:   "set-default <name>",
+	Short: "Use a profile when no --profile is given",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfilesSetDefault,
+}
+
+var profilesForce bool
+
 func init() {
 	rootCmd.AddCommand(profilesCmd)
+	profilesCmd.AddCommand(profilesListCmd)
+	profilesCmd.AddCommand(profilesRemoveCmd)
+	profilesCmd.AddCommand(profilesRenameCmd)
+	profilesCmd.AddCommand(profilesSetDefaultCmd)
+
+	profilesRemoveCmd.Flags().BoolVar(&profilesForce, "force", false, "Remove without asking for confirmation")
 }
 
 func runProfilesList(cmd *cobra.Command, args []string) error {
@@ -37,19 +73,53 @@ func runProfilesList(cmd *cobra.Command, args []string) error {
 			"profiles": names,
 			"default":  defaultName,
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(result)
+		return printJSON(result)
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "PROFILE\tDEFAULT")
-	for _, name := range names {
-		isDefault := ""
-		if name == defaultName {
-			isDefault = "*"
-		}
-		fmt.Fprintf(w, "%s\t%s\n", name, isDefault)
+	return render(names, []output.Column[string]{
+		{Header: "PROFILE", Value: func(name string) string { return name }},
+		{Header: "DEFAULT", Value: func(name string) string {
+			if name == defaultName {
+				return "*"
+			}
+			return ""
+		}},
+	})
+}
+
+func runProfilesRemove(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if _, ok := config.GetInstance(name); !ok {
+		return fmt.Errorf("profile not found: %s", name)
+	}
+	// --dry-run skips the prompt, but this isn't an API request it would stop
+	if config.FlagDryRun {
+		fmt.Printf("Would remove profile '%s'\n", name)
+		return nil
+	}
+	if err := confirmOrAbort(fmt.Sprintf("Remove profile '%s' and its admin key?", name), profilesForce); err != nil {
+		return err
+	}
+
+	if err := config.RemoveInstance(name); err != nil {
+		return err
+	}
+	fmt.Printf("Removed profile '%s'\n", name)
+	return nil
+}
+
+func runProfilesRename(cmd *cobra.Command, args []string) error {
+	if err := config.RenameInstance(args[0], args[1]); err != nil {
+		return err
+	}
+	fmt.Printf("Renamed profile '%s' to '%s'\n", args[0], args[1])
+	return nil
+}
+
+func runProfilesSetDefault(cmd *cobra.Command, args []string) error {
+	if err := config.SetDefault(args[0]); err != nil {
+		return err
 	}
-	return w.Flush()
+	fmt.Printf("Default profile is now '%s'\n", args[0])
+	return nil
 }
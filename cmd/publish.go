@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+)
+
+var publishCmd = &cobra.Command{
+	Use:   "publish <file.md>",
+	Short: "Create or update a post from a markdown file and publish it",
+	Long: `Publish a markdown file as a post in one step: the post is created if the
+site has none for the file yet, or else updated from it, and published.
+
+Files are matched to posts as by "posts verify": by the slug in their
+frontmatter, or else by the slug of their title. Use '-' to read from stdin.
+
+Use --newsletter to also send the post by email, or --email-only to send it
+without publishing it on the site; the file's 'newsletter' and
+'email_segment' frontmatter keys are used unless overridden. A post that is
+already published is only updated, as Ghost emails a post once.
+
+This is a shortcut for "posts create --status published" and "posts publish
+<slug> <file.md>".`,
+	Example: `  specter publish hello-world.md
+  specter publish hello-world.md --newsletter weekly --email-segment status:-free`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPublish,
+}
+
+func init() {
+	rootCmd.AddCommand(publishCmd)
+	publishCmd.Flags().StringVar(&postsNewsletter, "newsletter", "", "Send by email through this newsletter (slug)")
+	publishCmd.Flags().StringVar(&postsEmailSegment, "email-segment", "", "Members to email, e.g. 'status:free' or 'status:-free' (default all)")
+	publishCmd.Flags().BoolVar(&postsEmailOnly, "email-only", false, "Send as email only, without publishing on the site (requires a newsletter)")
+	publishCmd.Flags().BoolVar(&postsUploadImages, "upload-images", false, "Upload images referenced by local path and use their Ghost URLs")
+	contentVarsFlags.addFlags(publishCmd)
+	markdownOptionFlags.addFlags(publishCmd)
+}
+
+func runPublish(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	parsed, err := parsePostFile(cfg, client, args[0])
+	if err != nil {
+		return err
+	}
+	slug := fileSlug(parsed)
+	if slug == "" {
+		return fmt.Errorf("%s has no title or slug to find its post by", args[0])
+	}
+
+	list, err := client.Posts.List(context.Background(), &api.ListOptions{
+		Filter: fmt.Sprintf("slug:'%s'", strings.ReplaceAll(slug, "'", "\\'")),
+		Limit:  1,
+	})
+	if err != nil {
+		return err
+	}
+	if len(list.Items) == 0 {
+		return createPost(cfg, client, parsed, "published", "")
+	}
+	return publishPost(cfg, client, &list.Items[0], parsed)
+}
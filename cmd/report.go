@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/dest"
+)
+
+// reportPath is where --report writes its JSON report, if set
+var reportPath string
+
+// addReportFlag adds --report to a command that changes many items
+func addReportFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&reportPath, "report", "", "Also write the result for each item to this JSON file or s3:// or gs:// URL, e.g. for CI to archive")
+}
+
+// Report is the file --report writes: what a bulk command did with each
+// item
+type Report struct {
+	Command   string      `json:"command"`
+	Profile   string      `json:"profile,omitempty"`
+	Site      string      `json:"site"`
+	DryRun    bool        `json:"dry_run,omitempty"`
+	CreatedAt string      `json:"created_at"`
+	Total     int         `json:"total"`
+	Failed    int         `json:"failed"`
+	Items     interface{} `json:"items"`
+}
+
+// writeReport writes the --report file, if one was asked for, with the
+// results for items of which failed failed. It is written before the
+// results are printed, so that it exists even if the command fails.
+func writeReport[T any](cmd *cobra.Command, cfg *config.Config, items []T, failed int) error {
+	if reportPath == "" {
+		return nil
+	}
+	if items == nil {
+		items = []T{}
+	}
+	report := Report{
+		Command:   cmd.CommandPath(),
+		Profile:   cfg.Name,
+		Site:      cfg.URL,
+		DryRun:    config.FlagDryRun,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Total:     len(items),
+		Failed:    failed,
+		Items:     items,
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = dest.WriteTo(context.Background(), broadcastReportPath(reportPath, cfg.Name), func(w io.Writer) error {
+		_, err := w.Write(append(data, '\n'))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	return nil
+}
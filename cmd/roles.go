@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var rolesCmd = &cobra.Command{
+	Use:   "roles",
+	Short: "Staff roles",
+}
+
+var rolesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List staff roles",
+	RunE:  runRolesList,
+}
+
+var rolesAssignable bool
+
+func init() {
+	rootCmd.AddCommand(rolesCmd)
+	rolesCmd.AddCommand(rolesListCmd)
+
+	rolesListCmd.Flags().BoolVar(&rolesAssignable, "assignable", false, "Only list roles this integration can assign")
+	addExecFlag(rolesListCmd)
+}
+
+type rolesResponse struct {
+	Roles []Role `json:"roles"`
+}
+
+func runRolesList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	roles, err := listRoles(client, rolesAssignable)
+	if err != nil {
+		return err
+	}
+
+	return render(roles, []output.Column[Role]{
+		{Header: "ID", Value: func(r Role) string { return r.ID }},
+		{Header: "NAME", Value: func(r Role) string { return r.Name }},
+		{Header: "DESCRIPTION", Value: func(r Role) string { return r.Description }},
+	})
+}
+
+// listRoles returns all staff roles, or only those the current integration
+// is allowed to assign
+func listRoles(client *api.Client, assignable bool) ([]Role, error) {
+	params := url.Values{}
+	if assignable {
+		params.Set("permissions", "assign")
+	}
+
+	var resp rolesResponse
+	if err := client.GetJSON("/roles/", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Roles, nil
+}
+
+// findRole looks up an assignable role by name (case-insensitive) or ID
+func findRole(client *api.Client, nameOrID string) (*Role, error) {
+	roles, err := listRoles(client, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for i, r := range roles {
+		if strings.EqualFold(r.Name, nameOrID) || r.ID == nameOrID {
+			return &roles[i], nil
+		}
+		names = append(names, r.Name)
+	}
+	return nil, fmt.Errorf("unknown role: %s (available: %s)", nameOrID, strings.Join(names, ", "))
+}
@@ -1,11 +1,13 @@
 package cmd
 
 import (
-	"fmt"
+	"errors"
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
 	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
 )
 
 var rootCmd = &cobra.Command{
@@ -20,11 +22,25 @@ Configure with environment variables:
 Or use a config file at ~/.config/specter/config.yaml or ~/.specter.yaml:
   url: https://myblog.com
   key: "64xxxxx:xxxxxxxxxxxxxx"`,
+	// Errors are printed by Execute, in JSON if requested
+	SilenceErrors: true,
+	// Reject a bad --output before a command changes anything
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// A dry run ends with ErrDryRun, which isn't a usage mistake
+		if config.FlagDryRun {
+			cmd.SilenceUsage = true
+		}
+		return output.CheckFormat(config.OutputFormat())
+	},
 }
 
 func Execute() {
+	enableBroadcast(rootCmd)
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		if errors.Is(err, api.ErrDryRun) {
+			return
+		}
+		output.Error(os.Stderr, err, outputOptions())
 		os.Exit(1)
 	}
 }
@@ -32,6 +48,15 @@ func Execute() {
 func init() {
 	rootCmd.PersistentFlags().StringVar(&config.FlagURL, "url", "", "Ghost site URL")
 	rootCmd.PersistentFlags().StringVar(&config.FlagKey, "key", "", "Ghost Admin API key")
-	rootCmd.PersistentFlags().StringVarP(&config.FlagOutput, "output", "o", "text", "Output format: text or json")
+	rootCmd.PersistentFlags().StringVarP(&config.FlagOutput, "output", "o", "", "Output format: text, json, ndjson, csv, or template=<go template> (default text, or the profile's output)")
+	rootCmd.PersistentFlags().BoolVar(&config.FlagNoHeaders, "no-headers", false, "Omit table and CSV headers")
+	rootCmd.PersistentFlags().BoolVar(&config.FlagWide, "wide", false, "Show all table columns without truncating")
 	rootCmd.PersistentFlags().StringVarP(&config.FlagProfile, "profile", "p", "", "Config profile to use")
+	rootCmd.PersistentFlags().StringSliceVar(&config.FlagProfiles, "profiles", nil, "Run the command once for each of these profiles, e.g. eu,us")
+	rootCmd.PersistentFlags().BoolVar(&config.FlagAllProfiles, "all-profiles", false, "Run the command once for each configured profile")
+	rootCmd.PersistentFlags().BoolVarP(&config.FlagYes, "yes", "y", false, "Don't ask for confirmation before destructive changes")
+	rootCmd.PersistentFlags().BoolVar(&config.FlagDebug, "debug", false, "Log API requests to stderr")
+	rootCmd.PersistentFlags().BoolVar(&config.FlagOverrideFreeze, "override-freeze", false, "Allow changes while the profile is frozen")
+	rootCmd.PersistentFlags().BoolVar(&config.FlagDryRun, "dry-run", false, "Change nothing: print the requests that would change the site, or list what would change")
+	rootCmd.PersistentFlags().BoolVar(&config.FlagContentAPI, "content-api", false, "Read published content through the Content API with the profile's content_key, without admin credentials")
 }
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+var routesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: "Manage custom routes (routes.yaml)",
+}
+
+var routesGetCmd = &cobra.Command{
+	Use:     "get",
+	Short:   "Download the site's routes.yaml",
+	Example: `  specter routes get > routes.yaml`,
+	RunE:    runRoutesGet,
+}
+
+var routesSetCmd = &cobra.Command{
+	Use:   "set <routes.yaml>",
+	Short: "Upload a routes.yaml, replacing the current one",
+	Long:  `Upload a routes.yaml, replacing the current one. Use - to read from stdin.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRoutesSet,
+}
+
+func init() {
+	rootCmd.AddCommand(routesCmd)
+	routesCmd.AddCommand(routesGetCmd)
+	routesCmd.AddCommand(routesSetCmd)
+}
+
+func runRoutesGet(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	data, err := client.Get("/settings/routes/yaml/", nil)
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(map[string]string{
+			"routes": string(data),
+		})
+	}
+
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+func runRoutesSet(cmd *cobra.Command, args []string) error {
+	routes, err := readFileOrStdin(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Catch syntax errors here rather than relying on Ghost's less specific
+	// validation message
+	var parsed map[string]interface{}
+	if err := yaml.Unmarshal([]byte(routes), &parsed); err != nil {
+		return fmt.Errorf("parsing %s: %w", args[0], err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	if err := client.UploadRoutes(strings.NewReader(routes)); err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(map[string]bool{
+			"updated": true,
+		})
+	}
+
+	fmt.Println("Updated routes.yaml")
+	return nil
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/content"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print JSON Schemas for specter's input files",
+}
+
+var schemaFrontmatterCmd = &cobra.Command{
+	Use:   "frontmatter",
+	Short: "Print a JSON Schema for post and page frontmatter",
+	Long: `Print a JSON Schema for the frontmatter of markdown files, for completion
+and validation in editors. With the VS Code YAML extension, save the schema
+and reference it at the top of the frontmatter:
+
+  # yaml-language-server: $schema=./frontmatter.schema.json`,
+	Example: `  specter schema frontmatter > frontmatter.schema.json`,
+	Args:    cobra.NoArgs,
+	RunE:    runSchemaFrontmatter,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	schemaCmd.AddCommand(schemaFrontmatterCmd)
+}
+
+func runSchemaFrontmatter(cmd *cobra.Command, args []string) error {
+	return printJSON(content.FrontmatterSchema())
+}
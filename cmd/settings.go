@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	// Embed the timezone database so timezone names can be validated on
+	// systems without one installed
+	_ "time/tzdata"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+)
+
+var settingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Manage site settings",
+}
+
+var codeInjectionCmd = &cobra.Command{
+	Use:   "codeinjection",
+	Short: "Manage site-wide code injection",
+}
+
+var codeInjectionGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Show code injection for the site header and footer",
+	Long:  "Show code injection. Use --head or --foot to print only that snippet, unmodified, e.g. to save it to a file.",
+	Args:  cobra.NoArgs,
+	RunE:  runCodeInjectionGet,
+}
+
+var codeInjectionSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set code injection for the site header and/or footer",
+	Long:  "Set code injection from files. Use '-' to read from stdin.",
+	Args:  cobra.NoArgs,
+	RunE:  runCodeInjectionSet,
+}
+
+var settingsSetTimezoneCmd = &cobra.Command{
+	Use:   "set-timezone <timezone>",
+	Short: "Set the site timezone",
+	Long:  "Set the site timezone, used for scheduling and dates. Must be an IANA name such as Europe/Berlin or America/New_York.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSettingsSetTimezone,
+}
+
+var settingsSetLocaleCmd = &cobra.Command{
+	Use:   "set-locale <locale>",
+	Short: "Set the site locale",
+	Long:  "Set the site locale, e.g. en, de or pt-BR. Only locales Ghost ships translations for are accepted unless --force is given.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSettingsSetLocale,
+}
+
+// ghostLocales are the locales Ghost ships translations for
+var ghostLocales = []string{
+	"af", "ar", "bg", "bn", "bs", "ca", "cs", "da", "de", "de-CH", "el", "en",
+	"eo", "es", "et", "fa", "fi", "fr", "gd", "he", "hi", "hr", "hu", "id",
+	"is", "it", "ja", "kk", "ko", "lt", "lv", "mk", "mn", "ms", "nb", "ne",
+	"nl", "nn", "pa", "pl", "pt", "pt-BR", "ro", "ru", "si", "sk", "sl", "sq",
+	"sr", "sr-Cyrl", "sv", "sw", "ta", "th", "tr", "uk", "ur", "uz", "vi",
+	"zh", "zh-Hant",
+}
+
+var (
+	codeInjectionHead     string
+	codeInjectionFoot     string
+	codeInjectionHeadOnly bool
+	codeInjectionFootOnly bool
+	settingsForce         bool
+)
+
+func init() {
+	rootCmd.AddCommand(settingsCmd)
+	settingsCmd.AddCommand(codeInjectionCmd)
+	codeInjectionCmd.AddCommand(codeInjectionGetCmd)
+	codeInjectionCmd.AddCommand(codeInjectionSetCmd)
+	settingsCmd.AddCommand(settingsSetTimezoneCmd)
+	settingsCmd.AddCommand(settingsSetLocaleCmd)
+
+	settingsSetLocaleCmd.Flags().BoolVar(&settingsForce, "force", false, "Accept a locale Ghost has no translations for")
+
+	codeInjectionGetCmd.Flags().BoolVar(&codeInjectionHeadOnly, "head", false, "Print only the header snippet")
+	codeInjectionGetCmd.Flags().BoolVar(&codeInjectionFootOnly, "foot", false, "Print only the footer snippet")
+
+	codeInjectionSetCmd.Flags().StringVar(&codeInjectionHead, "head", "", "File with header code injection ('-' for stdin)")
+	codeInjectionSetCmd.Flags().StringVar(&codeInjectionFoot, "foot", "", "File with footer code injection ('-' for stdin)")
+}
+
+// Setting is a single Ghost site setting
+type Setting struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+type settingsResponse struct {
+	Settings []Setting `json:"settings"`
+}
+
+func runCodeInjectionGet(cmd *cobra.Command, args []string) error {
+	if codeInjectionHeadOnly && codeInjectionFootOnly {
+		return fmt.Errorf("--head and --foot are mutually exclusive")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	settings, err := getSettings(client)
+	if err != nil {
+		return err
+	}
+
+	head := settingString(settings, "codeinjection_head")
+	foot := settingString(settings, "codeinjection_foot")
+
+	if codeInjectionHeadOnly {
+		fmt.Print(head)
+		return nil
+	}
+	if codeInjectionFootOnly {
+		fmt.Print(foot)
+		return nil
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(map[string]string{
+			"codeinjection_head": head,
+			"codeinjection_foot": foot,
+		})
+	}
+
+	fmt.Println("Head:")
+	fmt.Println(head)
+	fmt.Println()
+	fmt.Println("Foot:")
+	fmt.Println(foot)
+	return nil
+}
+
+func runCodeInjectionSet(cmd *cobra.Command, args []string) error {
+	if codeInjectionHead == "" && codeInjectionFoot == "" {
+		return fmt.Errorf("no updates specified (use --head and/or --foot)")
+	}
+	if codeInjectionHead == "-" && codeInjectionFoot == "-" {
+		return fmt.Errorf("only one of --head and --foot can read from stdin")
+	}
+
+	var updates []Setting
+	if codeInjectionHead != "" {
+		head, err := readFileOrStdin(codeInjectionHead)
+		if err != nil {
+			return err
+		}
+		updates = append(updates, Setting{Key: "codeinjection_head", Value: head})
+	}
+	if codeInjectionFoot != "" {
+		foot, err := readFileOrStdin(codeInjectionFoot)
+		if err != nil {
+			return err
+		}
+		updates = append(updates, Setting{Key: "codeinjection_foot", Value: foot})
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	settings, err := updateSettings(client, updates)
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(map[string]string{
+			"codeinjection_head": settingString(settings, "codeinjection_head"),
+			"codeinjection_foot": settingString(settings, "codeinjection_foot"),
+		})
+	}
+
+	for _, u := range updates {
+		fmt.Printf("Updated %s (%d bytes)\n", u.Key, len(u.Value.(string)))
+	}
+	return nil
+}
+
+func runSettingsSetTimezone(cmd *cobra.Command, args []string) error {
+	tz := args[0]
+	// time.LoadLocation also accepts "Local", which means nothing to Ghost
+	if _, err := time.LoadLocation(tz); err != nil || tz == "Local" {
+		return fmt.Errorf("unknown timezone: %s (expected an IANA name like Europe/Berlin)", tz)
+	}
+	return setSetting("timezone", tz)
+}
+
+func runSettingsSetLocale(cmd *cobra.Command, args []string) error {
+	locale := args[0]
+	if !settingsForce {
+		for _, l := range ghostLocales {
+			if strings.EqualFold(l, locale) {
+				return setSetting("locale", l)
+			}
+		}
+		return fmt.Errorf("unknown locale: %s (known: %s; use --force to set anyway)", locale, strings.Join(ghostLocales, ", "))
+	}
+	return setSetting("locale", locale)
+}
+
+// setSetting updates a single string setting and reports the change
+func setSetting(key, value string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	current, err := getSettings(client)
+	if err != nil {
+		return err
+	}
+	old := settingString(current, key)
+
+	settings, err := updateSettings(client, []Setting{{Key: key, Value: value}})
+	if err != nil {
+		return err
+	}
+	updated := settingString(settings, key)
+
+	if config.OutputFormat() == "json" {
+		return printJSON(map[string]string{
+			"key":      key,
+			"previous": old,
+			"value":    updated,
+		})
+	}
+
+	fmt.Printf("Updated %s: %s -> %s\n", key, old, updated)
+	return nil
+}
+
+func getSettings(client *api.Client) ([]Setting, error) {
+	var resp settingsResponse
+	if err := client.GetJSON("/settings/", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Settings, nil
+}
+
+func updateSettings(client *api.Client, updates []Setting) ([]Setting, error) {
+	body := map[string]interface{}{
+		"settings": updates,
+	}
+
+	data, err := client.Put("/settings/", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp settingsResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+	return resp.Settings, nil
+}
+
+// settingString returns the string value of a setting, or "" if it is unset
+func settingString(settings []Setting, key string) string {
+	for _, s := range settings {
+		if s.Key == key {
+			if v, ok := s.Value.(string); ok {
+				return v
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// settingBool returns the boolean value of a setting, or false if it is unset
+func settingBool(settings []Setting, key string) bool {
+	for _, s := range settings {
+		if s.Key == key {
+			v, _ := s.Value.(bool)
+			return v
+		}
+	}
+	return false
+}
+
+func readFileOrStdin(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", path, err)
+	}
+	return string(data), nil
+}
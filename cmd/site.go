@@ -1,12 +1,9 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
-	"github.com/teal-bauer/specter/api"
 	"github.com/teal-bauer/specter/internal/config"
 )
 
@@ -21,9 +18,17 @@ var siteInfoCmd = &cobra.Command{
 	RunE:  runSiteInfo,
 }
 
+var siteConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show site information combined with public settings",
+	Long:  "Show site information together with the settings scripts typically need to adapt to a site: locale, timezone, and whether (paid) members are enabled.",
+	RunE:  runSiteConfig,
+}
+
 func init() {
 	rootCmd.AddCommand(siteCmd)
 	siteCmd.AddCommand(siteInfoCmd)
+	siteCmd.AddCommand(siteConfigCmd)
 }
 
 type Site struct {
@@ -35,6 +40,17 @@ type Site struct {
 	Version     string `json:"version"`
 }
 
+// SiteConfig combines site information with public settings
+type SiteConfig struct {
+	Site
+	Locale              string `json:"locale"`
+	Timezone            string `json:"timezone"`
+	Private             bool   `json:"is_private"`
+	MembersEnabled      bool   `json:"members_enabled"`
+	PaidMembersEnabled  bool   `json:"paid_members_enabled"`
+	MembersSignupAccess string `json:"members_signup_access,omitempty"`
+}
+
 type siteResponse struct {
 	Site Site `json:"site"`
 }
@@ -44,22 +60,15 @@ func runSiteInfo(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
-
-	data, err := client.Get("/site/", nil)
-	if err != nil {
-		return err
-	}
+	client := newClient(cfg)
 
 	var resp siteResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
+	if err := client.GetJSON("/site/", nil, &resp); err != nil {
+		return err
 	}
 
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(resp.Site)
+		return printJSON(resp.Site)
 	}
 
 	fmt.Printf("Title:       %s\n", resp.Site.Title)
@@ -74,3 +83,48 @@ func runSiteInfo(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+func runSiteConfig(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	var resp siteResponse
+	if err := client.GetJSON("/site/", nil, &resp); err != nil {
+		return err
+	}
+
+	settings, err := getSettings(client)
+	if err != nil {
+		return err
+	}
+
+	sc := SiteConfig{
+		Site:                resp.Site,
+		Locale:              settingString(settings, "locale"),
+		Timezone:            settingString(settings, "timezone"),
+		Private:             settingBool(settings, "is_private"),
+		MembersEnabled:      settingBool(settings, "members_enabled"),
+		PaidMembersEnabled:  settingBool(settings, "paid_members_enabled"),
+		MembersSignupAccess: settingString(settings, "members_signup_access"),
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(sc)
+	}
+
+	fmt.Printf("Title:          %s\n", sc.Title)
+	fmt.Printf("URL:            %s\n", sc.URL)
+	fmt.Printf("Version:        %s\n", sc.Version)
+	fmt.Printf("Locale:         %s\n", sc.Locale)
+	fmt.Printf("Timezone:       %s\n", sc.Timezone)
+	fmt.Printf("Private:        %v\n", sc.Private)
+	fmt.Printf("Members:        %v\n", sc.MembersEnabled)
+	fmt.Printf("Paid members:   %v\n", sc.PaidMembersEnabled)
+	if sc.MembersSignupAccess != "" {
+		fmt.Printf("Signup access:  %s\n", sc.MembersSignupAccess)
+	}
+	return nil
+}
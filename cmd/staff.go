@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+var staffCmd = &cobra.Command{
+	Use:   "staff",
+	Short: "Manage staff access declaratively",
+}
+
+var staffApplyCmd = &cobra.Command{
+	Use:   "apply <team.yaml>",
+	Short: "Make staff users match a team manifest",
+	Long: `Make staff users match a team manifest: users with the wrong role are
+updated, missing users are invited, and users not in the manifest are
+reported (but not removed).
+
+Manifest format:
+
+  staff:
+    - email: alice@example.com
+      role: Administrator
+    - email: bob@example.com
+      role: Editor`,
+	Args: cobra.ExactArgs(1),
+	RunE: runStaffApply,
+}
+
+func init() {
+	rootCmd.AddCommand(staffCmd)
+	staffCmd.AddCommand(staffApplyCmd)
+
+	addReportFlag(staffApplyCmd)
+}
+
+// staffManifest is the declared set of staff users
+type staffManifest struct {
+	Staff []struct {
+		Email string `yaml:"email"`
+		Role  string `yaml:"role"`
+	} `yaml:"staff"`
+}
+
+type staffChange struct {
+	Email  string `json:"email"`
+	Action string `json:"action"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func runStaffApply(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", args[0], err)
+	}
+	var manifest staffManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("parsing %s: %w", args[0], err)
+	}
+
+	declared := map[string]string{}
+	for _, s := range manifest.Staff {
+		email := strings.ToLower(strings.TrimSpace(s.Email))
+		if email == "" || s.Role == "" {
+			return fmt.Errorf("every staff entry needs an email and a role")
+		}
+		if _, dup := declared[email]; dup {
+			return fmt.Errorf("duplicate staff entry: %s", email)
+		}
+		declared[email] = s.Role
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	users, err := listAllUsers(client)
+	if err != nil {
+		return err
+	}
+	invites, err := listInvites(client)
+	if err != nil {
+		return err
+	}
+
+	var changes []staffChange
+	failed := 0
+	record := func(email, action, detail string, err error) {
+		if err != nil {
+			action, detail = "failed", err.Error()
+			failed++
+		}
+		changes = append(changes, staffChange{Email: email, Action: action, Detail: detail})
+	}
+
+	existing := map[string]bool{}
+	for _, u := range users {
+		email := strings.ToLower(u.Email)
+		existing[email] = true
+
+		want, ok := declared[email]
+		if !ok {
+			record(u.Email, "extra", "not in manifest", nil)
+			continue
+		}
+
+		current := ""
+		if len(u.Roles) > 0 {
+			current = u.Roles[0].Name
+		}
+		switch {
+		case strings.EqualFold(current, want):
+			record(u.Email, "ok", current, nil)
+		case current == "Owner":
+			record(u.Email, "skipped", "owner's role can't be changed; use 'users set-role' to transfer ownership", nil)
+		case config.FlagDryRun:
+			record(u.Email, "would update", current+" -> "+want, nil)
+		default:
+			role, err := findRole(client, want)
+			if err == nil {
+				_, err = updateUser(client, u.ID, map[string]interface{}{
+					"roles": []map[string]string{{"id": role.ID}},
+				})
+			}
+			record(u.Email, "updated", current+" -> "+want, err)
+		}
+	}
+
+	pending := map[string]bool{}
+	for _, inv := range invites {
+		pending[strings.ToLower(inv.Email)] = true
+	}
+
+	for _, s := range manifest.Staff {
+		email := strings.ToLower(strings.TrimSpace(s.Email))
+		if existing[email] {
+			continue
+		}
+		switch {
+		case pending[email]:
+			record(email, "ok", "invite pending", nil)
+		case config.FlagDryRun:
+			record(email, "would invite", s.Role, nil)
+		default:
+			role, err := findRole(client, s.Role)
+			if err == nil {
+				_, err = client.Post("/invites/", map[string]interface{}{
+					"invites": []interface{}{
+						map[string]string{"email": email, "role_id": role.ID},
+					},
+				})
+			}
+			record(email, "invited", s.Role, err)
+		}
+	}
+
+	if err := writeReport(cmd, cfg, changes, failed); err != nil {
+		return err
+	}
+	err = render(changes, []output.Column[staffChange]{
+		{Header: "EMAIL", Value: func(c staffChange) string { return c.Email }},
+		{Header: "ACTION", Value: func(c staffChange) string { return c.Action }},
+		{Header: "DETAIL", Value: func(c staffChange) string { return c.Detail }},
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d staff changes failed", failed)
+	}
+	return nil
+}
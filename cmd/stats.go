@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show site statistics",
+	Long: `Show site statistics.
+
+Besides text and json, stats commands accept -o csv for spreadsheets and
+reporting scripts.`,
+}
+
+var statsMembersCmd = &cobra.Command{
+	Use:   "members",
+	Short: "Show member counts over time",
+	RunE:  runStatsMembers,
+}
+
+var statsMRRCmd = &cobra.Command{
+	Use:   "mrr",
+	Short: "Show monthly recurring revenue over time",
+	RunE:  runStatsMRR,
+}
+
+var statsDays int
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.AddCommand(statsMembersCmd)
+	statsCmd.AddCommand(statsMRRCmd)
+
+	statsCmd.PersistentFlags().IntVar(&statsDays, "days", 30, "Number of most recent days to show (0 for all)")
+}
+
+// MemberCount is the number of members on a day, by status
+type MemberCount struct {
+	Date   string `json:"date"`
+	Free   int    `json:"free"`
+	Paid   int    `json:"paid"`
+	Comped int    `json:"comped"`
+	Total  int    `json:"total"`
+	Delta  int    `json:"delta"`
+}
+
+// MRRPoint is the monthly recurring revenue on a day, in the currency's
+// smallest unit (e.g. cents)
+type MRRPoint struct {
+	Date     string `json:"date"`
+	Currency string `json:"currency"`
+	MRR      int    `json:"mrr"`
+	Delta    int    `json:"delta"`
+}
+
+func runStatsMembers(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	data, err := client.Get("/members/stats/count/", nil)
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Data []struct {
+			Date   string `json:"date"`
+			Free   int    `json:"free"`
+			Paid   int    `json:"paid"`
+			Comped int    `json:"comped"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+
+	var counts []MemberCount
+	for i, d := range resp.Data {
+		c := MemberCount{Date: d.Date, Free: d.Free, Paid: d.Paid, Comped: d.Comped}
+		c.Total = c.Free + c.Paid + c.Comped
+		if i > 0 {
+			c.Delta = c.Total - counts[i-1].Total
+		}
+		counts = append(counts, c)
+	}
+	counts = lastDays(counts, statsDays)
+
+	err = render(counts, []output.Column[MemberCount]{
+		{Header: "DATE", Value: func(c MemberCount) string { return c.Date }},
+		{Header: "FREE", Value: func(c MemberCount) string { return strconv.Itoa(c.Free) }},
+		{Header: "PAID", Value: func(c MemberCount) string { return strconv.Itoa(c.Paid) }},
+		{Header: "COMPED", Value: func(c MemberCount) string { return strconv.Itoa(c.Comped) }},
+		{Header: "TOTAL", Value: func(c MemberCount) string { return strconv.Itoa(c.Total) }},
+		{Header: "DELTA", Value: func(c MemberCount) string { return fmt.Sprintf("%+d", c.Delta) }},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Summarize the period below the table
+	if len(counts) > 1 && config.OutputFormat() == "text" {
+		first, last := counts[0], counts[len(counts)-1]
+		fmt.Printf("\nChange: free %+d, paid %+d, comped %+d, total %+d\n",
+			last.Free-first.Free, last.Paid-first.Paid, last.Comped-first.Comped, last.Total-first.Total)
+	}
+	return nil
+}
+
+func runStatsMRR(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	data, err := client.Get("/members/stats/mrr/", nil)
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Data []struct {
+			Currency string `json:"currency"`
+			Data     []struct {
+				Date string `json:"date"`
+				MRR  int    `json:"value"`
+			} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+
+	var points []MRRPoint
+	for _, series := range resp.Data {
+		var ps []MRRPoint
+		for i, d := range series.Data {
+			p := MRRPoint{Date: d.Date, Currency: series.Currency, MRR: d.MRR}
+			if i > 0 {
+				p.Delta = p.MRR - ps[i-1].MRR
+			}
+			ps = append(ps, p)
+		}
+		points = append(points, lastDays(ps, statsDays)...)
+	}
+
+	return render(points, []output.Column[MRRPoint]{
+		{Header: "DATE", Value: func(p MRRPoint) string { return p.Date }},
+		{Header: "CURRENCY", Value: func(p MRRPoint) string { return strings.ToUpper(p.Currency) }, CSV: func(p MRRPoint) string { return p.Currency }},
+		{Header: "MRR", Value: func(p MRRPoint) string { return formatAmount(p.MRR) }, CSV: func(p MRRPoint) string { return strconv.Itoa(p.MRR) }},
+		{Header: "DELTA", Value: func(p MRRPoint) string { return formatDelta(p.Delta) }, CSV: func(p MRRPoint) string { return strconv.Itoa(p.Delta) }},
+	})
+}
+
+// lastDays returns the last n entries of a daily series, or all if n <= 0
+func lastDays[T any](series []T, n int) []T {
+	if n <= 0 || len(series) <= n {
+		return series
+	}
+	return series[len(series)-n:]
+}
+
+// formatAmount formats an amount in a currency's smallest unit, e.g.
+// 123456 as 1234.56
+func formatAmount(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+func formatDelta(cents int) string {
+	if cents >= 0 {
+		return "+" + formatAmount(cents)
+	}
+	return formatAmount(cents)
+}
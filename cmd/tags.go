@@ -1,15 +1,20 @@
 package cmd
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
+	"encoding/csv"
 	"fmt"
-	"net/url"
 	"os"
-	"text/tabwriter"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/teal-bauer/specter/api"
 	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+	"gopkg.in/yaml.v3"
 )
 
 var tagsCmd = &cobra.Command{
@@ -51,6 +56,28 @@ var tagsDeleteCmd = &cobra.Command{
 	RunE:  runTagsDelete,
 }
 
+var tagsApplyCmd = &cobra.Command{
+	Use:   "apply <tags.yaml|tags.csv>",
+	Short: "Update tag descriptions and metadata from a file",
+	Long: `Update many tags at once from a YAML or CSV file keyed by tag slug.
+
+YAML maps each slug to the fields to set:
+
+  technology:
+    description: Articles about technology
+    meta_title: Technology | My Blog
+    meta_description: Everything we've written about technology.
+
+CSV files need a 'slug' column plus a column per field; empty cells leave the
+field unchanged. Supported fields: name, description, meta_title,
+meta_description, feature_image, visibility.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTagsApply,
+}
+
+// tagApplyFields are the tag fields 'tags apply' may set
+var tagApplyFields = []string{"name", "description", "meta_title", "meta_description", "feature_image", "visibility"}
+
 var (
 	tagsLimit       int
 	tagsAll         bool
@@ -60,6 +87,8 @@ var (
 	tagVisibility   string
 	tagMetaTitle    string
 	tagMetaDesc     string
+	tagsQuery       listQuery
+	tagsForce       bool
 )
 
 func init() {
@@ -69,9 +98,14 @@ func init() {
 	tagsCmd.AddCommand(tagsCreateCmd)
 	tagsCmd.AddCommand(tagsUpdateCmd)
 	tagsCmd.AddCommand(tagsDeleteCmd)
+	tagsCmd.AddCommand(tagsApplyCmd)
+
+	tagsDeleteCmd.Flags().BoolVar(&tagsForce, "force", false, "Delete without asking for confirmation")
 
 	tagsListCmd.Flags().IntVar(&tagsLimit, "limit", 15, "Number of tags to return")
 	tagsListCmd.Flags().BoolVar(&tagsAll, "all", false, "Fetch all tags")
+	tagsQuery.addFlags(tagsListCmd, "visibility:public")
+	addExecFlag(tagsListCmd)
 
 	tagsCreateCmd.Flags().StringVar(&tagSlug, "slug", "", "Tag slug")
 	tagsCreateCmd.Flags().StringVar(&tagDescription, "description", "", "Tag description")
@@ -84,32 +118,8 @@ func init() {
 	tagsUpdateCmd.Flags().StringVar(&tagVisibility, "visibility", "", "Update visibility")
 	tagsUpdateCmd.Flags().StringVar(&tagMetaTitle, "meta-title", "", "Update meta title")
 	tagsUpdateCmd.Flags().StringVar(&tagMetaDesc, "meta-description", "", "Update meta description")
-}
-
-type Tag struct {
-	ID           string `json:"id"`
-	Name         string `json:"name"`
-	Slug         string `json:"slug"`
-	Description  string `json:"description,omitempty"`
-	FeatureImage string `json:"feature_image,omitempty"`
-	Visibility   string `json:"visibility"`
-	MetaTitle    string `json:"meta_title,omitempty"`
-	MetaDesc     string `json:"meta_description,omitempty"`
-	URL          string `json:"url,omitempty"`
-	PostCount    int    `json:"count,omitempty"`
-}
 
-type tagsResponse struct {
-	Tags []Tag `json:"tags"`
-	Meta struct {
-		Pagination struct {
-			Page  int `json:"page"`
-			Limit int `json:"limit"`
-			Pages int `json:"pages"`
-			Total int `json:"total"`
-			Next  int `json:"next"`
-		} `json:"pagination"`
-	} `json:"meta"`
+	addReportFlag(tagsApplyCmd)
 }
 
 func runTagsList(cmd *cobra.Command, args []string) error {
@@ -117,62 +127,44 @@ func runTagsList(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
+	opts := tagsQuery.options("")
 
 	var allTags []Tag
+	var pagination *Pagination
 
 	if tagsAll {
-		page := 1
-		for {
-			params := url.Values{}
-			params.Set("limit", "100")
-			params.Set("page", fmt.Sprintf("%d", page))
-
-			data, err := client.Get("/tags/", params)
+		for t, err := range client.Tags.All(context.Background(), opts) {
 			if err != nil {
 				return err
 			}
-
-			var resp tagsResponse
-			if err := json.Unmarshal(data, &resp); err != nil {
-				return fmt.Errorf("parsing response: %w", err)
+			if streaming() {
+				if err := streamItems([]Tag{t}); err != nil {
+					return err
+				}
+			} else {
+				allTags = append(allTags, t)
 			}
-
-			allTags = append(allTags, resp.Tags...)
-
-			if resp.Meta.Pagination.Next == 0 {
-				break
-			}
-			page = resp.Meta.Pagination.Next
 		}
 	} else {
-		params := url.Values{}
-		params.Set("limit", fmt.Sprintf("%d", tagsLimit))
-
-		data, err := client.Get("/tags/", params)
+		opts.Limit = tagsLimit
+		list, err := client.Tags.List(context.Background(), opts)
 		if err != nil {
 			return err
 		}
-
-		var resp tagsResponse
-		if err := json.Unmarshal(data, &resp); err != nil {
-			return fmt.Errorf("parsing response: %w", err)
-		}
-		allTags = resp.Tags
-	}
-
-	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(allTags)
+		allTags = list.Items
+		pagination = &list.Pagination
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tNAME\tSLUG\tVISIBILITY")
-	for _, t := range allTags {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.ID, t.Name, t.Slug, t.Visibility)
+	if pagination != nil && config.OutputFormat() == "json" {
+		return encodePage("tags", allTags, *pagination)
 	}
-	return w.Flush()
+	return render(allTags, []output.Column[Tag]{
+		{Header: "ID", Value: func(t Tag) string { return t.ID }},
+		{Header: "NAME", Value: func(t Tag) string { return t.Name }},
+		{Header: "SLUG", Value: func(t Tag) string { return t.Slug }},
+		{Header: "VISIBILITY", Value: func(t Tag) string { return t.Visibility }},
+	})
 }
 
 func runTagsGet(cmd *cobra.Command, args []string) error {
@@ -180,7 +172,7 @@ func runTagsGet(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	tag, err := getTag(client, args[0])
 	if err != nil {
@@ -188,9 +180,7 @@ func runTagsGet(cmd *cobra.Command, args []string) error {
 	}
 
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(tag)
+		return printJSON(tag)
 	}
 
 	fmt.Printf("ID:          %s\n", tag.ID)
@@ -211,7 +201,7 @@ func runTagsCreate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	tag := map[string]interface{}{
 		"name": args[0],
@@ -230,30 +220,13 @@ func runTagsCreate(cmd *cobra.Command, args []string) error {
 		tag["visibility"] = tagVisibility
 	}
 
-	body := map[string]interface{}{
-		"tags": []interface{}{tag},
-	}
-
-	data, err := client.Post("/tags/", body)
+	created, err := client.Tags.Create(context.Background(), tag, nil)
 	if err != nil {
 		return err
 	}
 
-	var resp tagsResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
-
-	if len(resp.Tags) == 0 {
-		return fmt.Errorf("no tag in response")
-	}
-
-	created := resp.Tags[0]
-
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(created)
+		return printJSON(created)
 	}
 
 	fmt.Printf("Created tag: %s\n", created.Name)
@@ -267,7 +240,7 @@ func runTagsUpdate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	existing, err := getTag(client, args[0])
 	if err != nil {
@@ -299,30 +272,13 @@ func runTagsUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no updates specified")
 	}
 
-	body := map[string]interface{}{
-		"tags": []interface{}{tag},
-	}
-
-	data, err := client.Put(fmt.Sprintf("/tags/%s/", existing.ID), body)
+	updated, err := client.Tags.Update(context.Background(), existing.ID, tag, nil)
 	if err != nil {
 		return err
 	}
 
-	var resp tagsResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
-
-	if len(resp.Tags) == 0 {
-		return fmt.Errorf("no tag in response")
-	}
-
-	updated := resp.Tags[0]
-
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(updated)
+		return printJSON(updated)
 	}
 
 	fmt.Printf("Updated tag: %s\n", updated.Name)
@@ -336,20 +292,32 @@ func runTagsDelete(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	existing, err := getTag(client, args[0])
 	if err != nil {
 		return err
 	}
 
-	_, err = client.Delete(fmt.Sprintf("/tags/%s/", existing.ID))
-	if err != nil {
+	prompt := fmt.Sprintf("Delete tag '%s' (%s)?", existing.Name, existing.ID)
+	if confirming(tagsForce) {
+		posts, pages, err := tagUsage(client, existing.Slug)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: can't count posts with this tag: %v\n", err)
+		} else if posts+pages > 0 {
+			prompt = fmt.Sprintf("Delete tag '%s' (%s)? %s will lose it.", existing.Name, existing.ID, countPostsAndPages(posts, pages))
+		}
+	}
+	if err := confirmOrAbort(prompt, tagsForce); err != nil {
+		return err
+	}
+
+	if err := client.Tags.Delete(context.Background(), existing.ID); err != nil {
 		return err
 	}
 
 	if config.OutputFormat() == "json" {
-		return json.NewEncoder(os.Stdout).Encode(map[string]string{
+		return printJSON(map[string]string{
 			"deleted": existing.ID,
 			"name":    existing.Name,
 		})
@@ -359,30 +327,197 @@ func runTagsDelete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func getTag(client *api.Client, idOrSlug string) (*Tag, error) {
-	data, err := client.Get(fmt.Sprintf("/tags/%s/", idOrSlug), nil)
-	if err == nil {
-		var resp tagsResponse
-		if err := json.Unmarshal(data, &resp); err == nil && len(resp.Tags) > 0 {
-			return &resp.Tags[0], nil
+func runTagsApply(cmd *cobra.Command, args []string) error {
+	edits, err := readTagEdits(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	var slugs []string
+	for slug := range edits {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	type result struct {
+		Slug    string   `json:"slug"`
+		Status  string   `json:"status"`
+		Changed []string `json:"changed,omitempty"`
+		Error   string   `json:"error,omitempty"`
+	}
+	var results []result
+	failed := 0
+
+	for _, slug := range slugs {
+		existing, err := getTag(client, slug)
+		if err != nil {
+			results = append(results, result{Slug: slug, Status: "not found"})
+			failed++
+			continue
+		}
+
+		current := map[string]string{
+			"name":             existing.Name,
+			"description":      existing.Description,
+			"meta_title":       existing.MetaTitle,
+			"meta_description": existing.MetaDesc,
+			"feature_image":    existing.FeatureImage,
+			"visibility":       existing.Visibility,
+		}
+		tag := map[string]interface{}{}
+		var changed []string
+		for _, field := range tagApplyFields {
+			value, ok := edits[slug][field]
+			if ok && value != current[field] {
+				tag[field] = value
+				changed = append(changed, field)
+			}
+		}
+
+		if len(changed) == 0 {
+			results = append(results, result{Slug: slug, Status: "unchanged"})
+			continue
+		}
+		if config.FlagDryRun {
+			results = append(results, result{Slug: slug, Status: "would update", Changed: changed})
+			continue
 		}
+
+		if _, err := client.Tags.Update(context.Background(), existing.ID, tag, nil); err != nil {
+			results = append(results, result{Slug: slug, Status: "failed", Changed: changed, Error: err.Error()})
+			failed++
+			continue
+		}
+		results = append(results, result{Slug: slug, Status: "updated", Changed: changed})
 	}
 
-	params := url.Values{}
-	params.Set("filter", fmt.Sprintf("slug:%s", idOrSlug))
-	data, err = client.Get("/tags/", params)
+	if err := writeReport(cmd, cfg, results, failed); err != nil {
+		return err
+	}
+	err = render(results, []output.Column[result]{
+		{Header: "SLUG", Value: func(r result) string { return r.Slug }},
+		{Header: "STATUS", Value: func(r result) string { return r.Status }},
+		{Header: "FIELDS", Value: func(r result) string {
+			if r.Error != "" {
+				return r.Error
+			}
+			return orDash(strings.Join(r.Changed, ", "))
+		}},
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var resp tagsResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return nil, fmt.Errorf("parsing response: %w", err)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tags could not be updated", failed, len(slugs))
 	}
+	return nil
+}
 
-	if len(resp.Tags) == 0 {
-		return nil, fmt.Errorf("tag not found: %s", idOrSlug)
+// readTagEdits reads slug -> field -> value edits from a YAML or CSV file
+func readTagEdits(path string) (map[string]map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
 	}
 
-	return &resp.Tags[0], nil
+	edits := map[string]map[string]string{}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV: %w", err)
+		}
+		if len(records) == 0 {
+			return edits, nil
+		}
+
+		header := records[0]
+		slugCol := -1
+		for i, h := range header {
+			header[i] = strings.ToLower(strings.TrimSpace(h))
+			if header[i] == "slug" {
+				slugCol = i
+			}
+		}
+		if slugCol < 0 {
+			return nil, fmt.Errorf("CSV must have a 'slug' column")
+		}
+		for _, rec := range records[1:] {
+			slug := strings.TrimSpace(rec[slugCol])
+			if slug == "" {
+				continue
+			}
+			fields := map[string]string{}
+			for i, h := range header {
+				if i != slugCol && rec[i] != "" {
+					fields[h] = rec[i]
+				}
+			}
+			edits[slug] = fields
+		}
+	} else if err := yaml.Unmarshal(data, &edits); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	allowed := map[string]bool{}
+	for _, f := range tagApplyFields {
+		allowed[f] = true
+	}
+	for slug, fields := range edits {
+		for field := range fields {
+			if !allowed[field] {
+				return nil, fmt.Errorf("%s: unknown field %q (supported: %s)", slug, field, strings.Join(tagApplyFields, ", "))
+			}
+		}
+	}
+	return edits, nil
+}
+
+// tagUsage returns how many posts and pages have the tag
+func tagUsage(client *api.Client, slug string) (posts, pages int, err error) {
+	opts := &api.ListOptions{
+		ReadOptions: api.ReadOptions{Fields: []string{"id"}},
+		Filter:      fmt.Sprintf("tags:'%s'", slug),
+		Limit:       1,
+	}
+	postList, err := client.Posts.List(context.Background(), opts)
+	if err != nil {
+		return 0, 0, err
+	}
+	pageList, err := client.Pages.List(context.Background(), opts)
+	if err != nil {
+		return 0, 0, err
+	}
+	return postList.Pagination.Total, pageList.Pagination.Total, nil
+}
+
+// plural returns n and noun, adding an s unless n is 1
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// countPostsAndPages describes a number of posts and pages, e.g. "3 posts
+// and 1 page", leaving out zero counts
+func countPostsAndPages(posts, pages int) string {
+	var parts []string
+	if posts > 0 {
+		parts = append(parts, plural(posts, "post"))
+	}
+	if pages > 0 {
+		parts = append(parts, plural(pages, "page"))
+	}
+	return strings.Join(parts, " and ")
+}
+
+func getTag(client *api.Client, idOrSlug string) (*Tag, error) {
+	return client.Tags.Lookup(context.Background(), idOrSlug, nil)
 }
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var tagsMergeCmd = &cobra.Command{
+	Use:   "merge <from> <into>",
+	Short: "Move a tag's posts and pages to another tag and delete it",
+	Long: `Give every post and page tagged <from> the tag <into> instead, then delete
+<from>, e.g. to clean up duplicates such as "JS" and "javascript". Tags are
+given by ID or slug.
+
+<into> takes the place of <from> in each item's tags, so an item whose
+primary tag was <from> gets <into> as its primary tag. <from> is only
+deleted if every item was updated; with --keep it isn't deleted at all.`,
+	Example: `  specter tags merge js javascript
+  specter tags merge old-news news --keep --yes`,
+	Args: cobra.ExactArgs(2),
+	RunE: runTagsMerge,
+}
+
+var tagsMergeKeep bool
+
+func init() {
+	tagsCmd.AddCommand(tagsMergeCmd)
+	tagsMergeCmd.Flags().BoolVar(&tagsMergeKeep, "keep", false, "Keep the <from> tag after moving its posts and pages")
+	addReportFlag(tagsMergeCmd)
+}
+
+// RetagResult is the outcome of changing one post's or page's tags
+type RetagResult struct {
+	Type   string `json:"type"`
+	ID     string `json:"id"`
+	Slug   string `json:"slug"`
+	Tags   string `json:"tags,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// taggedItem is a post or page with its tags
+type taggedItem struct {
+	Type      string
+	ID        string
+	Slug      string
+	UpdatedAt string
+	Tags      []Tag
+}
+
+func runTagsMerge(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	from, err := getTag(client, args[0])
+	if err != nil {
+		return err
+	}
+	into, err := getTag(client, args[1])
+	if err != nil {
+		return err
+	}
+	if from.ID == into.ID {
+		return fmt.Errorf("can't merge tag '%s' into itself", from.Name)
+	}
+
+	filter := fmt.Sprintf("tags:'%s'", from.Slug)
+	items, err := taggedItems(client, filter, true)
+	if err != nil {
+		return err
+	}
+
+	prompt := fmt.Sprintf("Move %s from tag '%s' to '%s' and delete '%s'?", countPostsAndPages(countTagged(items, "post"), countTagged(items, "page")), from.Name, into.Name, from.Name)
+	switch {
+	case len(items) == 0 && tagsMergeKeep:
+		return fmt.Errorf("tag '%s' has no posts or pages", from.Name)
+	case len(items) == 0:
+		prompt = fmt.Sprintf("Tag '%s' has no posts or pages. Delete it?", from.Name)
+	case tagsMergeKeep:
+		prompt = fmt.Sprintf("Move %s from tag '%s' to '%s'?", countPostsAndPages(countTagged(items, "post"), countTagged(items, "page")), from.Name, into.Name)
+	}
+	if err := confirmOrAbort(prompt, false); err != nil {
+		return err
+	}
+
+	results, failed, err := retagItems(client, items, func(tags []Tag) []Tag {
+		return replaceTag(tags, from, into)
+	})
+	if err != nil {
+		return err
+	}
+
+	deleted := false
+	if failed == 0 && !tagsMergeKeep {
+		if err := client.Tags.Delete(context.Background(), from.ID); err != nil {
+			if errors.Is(err, api.ErrDryRun) {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Warning: deleting tag '%s': %v\n", from.Name, err)
+		} else {
+			deleted = true
+		}
+	}
+
+	if err := writeReport(cmd, cfg, results, failed); err != nil {
+		return err
+	}
+	if err := renderRetagResults(results); err != nil {
+		return err
+	}
+	if deleted && config.OutputFormat() != "json" {
+		fmt.Fprintf(os.Stderr, "Deleted tag: %s (%s)\n", from.Name, from.ID)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d posts and pages could not be updated; tag '%s' was kept", failed, len(results), from.Name)
+	}
+	return nil
+}
+
+// taggedItems returns the posts, and pages too if withPages is set,
+// matching filter, with their tags
+func taggedItems(client *api.Client, filter string, withPages bool) ([]taggedItem, error) {
+	opts := &api.ListOptions{Filter: filter, ReadOptions: api.ReadOptions{Include: []string{"tags"}, Fields: []string{"id", "slug", "updated_at"}}}
+	var items []taggedItem
+	for p, err := range client.Posts.All(context.Background(), opts) {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, taggedItem{Type: "post", ID: p.ID, Slug: p.Slug, UpdatedAt: p.UpdatedAt, Tags: p.Tags})
+	}
+	if !withPages {
+		return items, nil
+	}
+	for p, err := range client.Pages.All(context.Background(), opts) {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, taggedItem{Type: "page", ID: p.ID, Slug: p.Slug, UpdatedAt: p.UpdatedAt, Tags: p.Tags})
+	}
+	return items, nil
+}
+
+// countTagged counts the items of one type
+func countTagged(items []taggedItem, typ string) int {
+	n := 0
+	for _, it := range items {
+		if it.Type == typ {
+			n++
+		}
+	}
+	return n
+}
+
+// replaceTag returns tags with from replaced by into, in from's place so
+// that a primary tag stays primary
+func replaceTag(tags []Tag, from, into *Tag) []Tag {
+	var out []Tag
+	for _, t := range tags {
+		switch t.ID {
+		case from.ID:
+			if !hasTag(tags, into) {
+				out = append(out, *into)
+			}
+		default:
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// hasTag reports whether tags include t, by ID, or by name for tags that
+// don't exist yet
+func hasTag(tags []Tag, t *Tag) bool {
+	for _, have := range tags {
+		if t.ID != "" && have.ID == t.ID || t.ID == "" && strings.EqualFold(have.Name, t.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// retagItems sets the tags of each item to what change returns for its
+// current tags, skipping items whose tags stay the same
+func retagItems(client *api.Client, items []taggedItem, change func([]Tag) []Tag) ([]RetagResult, int, error) {
+	results := make([]RetagResult, len(items))
+	failed := 0
+	for i, it := range items {
+		tags := change(it.Tags)
+		r := RetagResult{Type: it.Type, ID: it.ID, Slug: it.Slug, Tags: tagNameList(tags), Status: "updated"}
+		if sameTags(it.Tags, tags) {
+			r.Status = "unchanged"
+			results[i] = r
+			continue
+		}
+
+		update := map[string]interface{}{"tags": tagRefs(tags), "updated_at": it.UpdatedAt}
+		var err error
+		if it.Type == "post" {
+			_, err = client.Posts.Update(context.Background(), it.ID, update, nil)
+		} else {
+			_, err = client.Pages.Update(context.Background(), it.ID, update, nil)
+		}
+		if err != nil {
+			if errors.Is(err, api.ErrDryRun) {
+				return nil, 0, err
+			}
+			r.Status, r.Error = "failed", err.Error()
+			failed++
+		}
+		results[i] = r
+	}
+	return results, failed, nil
+}
+
+// tagRefs returns tags as a post or page update sends them: by ID, or by
+// name for tags that Ghost is to create
+func tagRefs(tags []Tag) []map[string]string {
+	refs := make([]map[string]string, 0, len(tags))
+	for _, t := range tags {
+		if t.ID != "" {
+			refs = append(refs, map[string]string{"id": t.ID})
+		} else {
+			refs = append(refs, map[string]string{"name": t.Name})
+		}
+	}
+	return refs
+}
+
+// sameTags reports whether two tag lists are the same, in the same order
+func sameTags(a, b []Tag) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID || a[i].ID == "" && a[i].Name != b[i].Name {
+			return false
+		}
+	}
+	return true
+}
+
+// tagNameList joins the names of tags for display
+func tagNameList(tags []Tag) string {
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func renderRetagResults(results []RetagResult) error {
+	return render(results, []output.Column[RetagResult]{
+		{Header: "TYPE", Value: func(r RetagResult) string { return r.Type }},
+		{Header: "ID", Value: func(r RetagResult) string { return r.ID }, Wide: true},
+		{Header: "SLUG", Value: func(r RetagResult) string { return r.Slug }},
+		{Header: "TAGS", Value: func(r RetagResult) string { return orDash(r.Tags) }, Width: 50},
+		{Header: "STATUS", Value: func(r RetagResult) string { return r.Status }},
+		{Header: "ERROR", Value: func(r RetagResult) string { return orDash(r.Error) }},
+	})
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var tailCmd = &cobra.Command{
+	Use:   "tail <posts|pages|members>",
+	Short: "Print new and changed resources as they appear",
+	Long: `Poll the site for posts, pages or members that are created or changed and
+print each one as it appears, like tail -f, e.g. to watch signups during a
+launch. Stop with Ctrl-C.
+
+Changes are found by updated_at, starting from the most recent change on
+the site (or --since), so the local clock doesn't matter. With -o json or
+ndjson, each change is printed as a JSON line.`,
+	Example: `  specter tail members
+  specter tail posts --interval 10s --since 1h`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"posts", "pages", "members"},
+	RunE:      runTail,
+}
+
+var (
+	tailInterval time.Duration
+	tailSince    string
+)
+
+func init() {
+	rootCmd.AddCommand(tailCmd)
+	tailCmd.Flags().DurationVar(&tailInterval, "interval", 30*time.Second, "How often to poll")
+	tailCmd.Flags().StringVar(&tailSince, "since", "", "Also print changes since this date or duration ago (e.g. 1h)")
+}
+
+// TailEvent is a resource that was created or changed
+type TailEvent struct {
+	Time     string `json:"time"`
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Status   string `json:"status,omitempty"`
+}
+
+// tailItem holds the fields of posts, pages and members that tail shows
+type tailItem struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Email     string `json:"email"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func runTail(cmd *cobra.Command, args []string) error {
+	resource := args[0]
+	switch resource {
+	case "posts", "pages", "members":
+	default:
+		return fmt.Errorf("can't tail %s: expected posts, pages or members", resource)
+	}
+	if tailInterval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var cursor time.Time
+	if tailSince != "" {
+		if cursor, _, err = parseTimeArg(tailSince, time.Now()); err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+	} else if cursor, err = latestUpdate(client, resource); err != nil {
+		return err
+	}
+	if !streaming() && config.OutputFormat() != "json" {
+		fmt.Fprintf(os.Stderr, "Watching %s changed after %s (Ctrl-C to stop)\n", resource, cursor.Local().Format(time.DateTime))
+	}
+
+	// Ghost's timestamps have second precision, so each poll includes the
+	// cursor's second and skips what was printed for it already. Without
+	// --since, the changes that set the starting point are old news.
+	seen := map[string]bool{}
+	skipCursor := tailSince == ""
+	ticker := time.NewTicker(tailInterval)
+	defer ticker.Stop()
+	for {
+		items, err := changedSince(client, resource, cursor)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+
+		for _, item := range items {
+			key := item.ID + " " + item.UpdatedAt
+			if seen[key] {
+				continue
+			}
+			updated, err := time.Parse(time.RFC3339, item.UpdatedAt)
+			if err != nil {
+				continue
+			}
+			if skipCursor && !updated.After(cursor) {
+				seen[key] = true
+				continue
+			}
+			if updated.After(cursor) {
+				cursor = updated
+				seen = map[string]bool{}
+			}
+			seen[key] = true
+
+			if err := printTailEvent(resource, item); err != nil {
+				return err
+			}
+		}
+		skipCursor = false
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// latestUpdate returns the updated_at of the most recently changed resource,
+// or now if there is none
+func latestUpdate(client *api.Client, resource string) (time.Time, error) {
+	params := url.Values{}
+	params.Set("order", "updated_at desc")
+	params.Set("limit", "1")
+	params.Set("fields", "id,updated_at")
+
+	var resp map[string][]tailItem
+	if err := client.GetJSON("/"+resource+"/", params, &resp); err != nil {
+		return time.Time{}, err
+	}
+	if items := resp[resource]; len(items) > 0 {
+		if t, err := time.Parse(time.RFC3339, items[0].UpdatedAt); err == nil {
+			return t, nil
+		}
+	}
+	return time.Now(), nil
+}
+
+// changedSince returns the resources updated at or after t, oldest first
+func changedSince(client *api.Client, resource string, t time.Time) ([]tailItem, error) {
+	params := url.Values{}
+	params.Set("filter", fmt.Sprintf("updated_at:>='%s'", t.UTC().Format(nqlTimeFormat)))
+	params.Set("order", "updated_at asc")
+
+	var items []tailItem
+	for data, err := range client.Paginate("/"+resource+"/", params) {
+		if err != nil {
+			return items, err
+		}
+		var resp map[string]json.RawMessage
+		var page []tailItem
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return items, fmt.Errorf("parsing response: %w", err)
+		}
+		if err := json.Unmarshal(resp[resource], &page); err != nil {
+			return items, fmt.Errorf("parsing response: %w", err)
+		}
+		items = append(items, page...)
+	}
+	return items, nil
+}
+
+func printTailEvent(resource string, item tailItem) error {
+	event := TailEvent{
+		Time:     item.UpdatedAt,
+		Resource: resource,
+		Action:   "updated",
+		ID:       item.ID,
+		Name:     item.Title,
+		Status:   item.Status,
+	}
+	if resource == "members" {
+		event.Name = item.Email
+	}
+	// Ghost sets both timestamps on creation
+	if item.CreatedAt == item.UpdatedAt {
+		event.Action = "created"
+	}
+
+	switch config.OutputFormat() {
+	case "json", "ndjson":
+		return output.NDJSON(os.Stdout, []TailEvent{event})
+	}
+	t, err := time.Parse(time.RFC3339, event.Time)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s  %-7s  %s  %s\n", t.Local().Format(time.DateTime), event.Action, event.Name, orDash(event.Status))
+	return nil
+}
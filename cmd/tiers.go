@@ -1,15 +1,15 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
 	"os"
-	"text/tabwriter"
+	"strconv"
 
 	"github.com/spf13/cobra"
 	"github.com/teal-bauer/specter/api"
 	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
 )
 
 var tiersCmd = &cobra.Command{
@@ -44,6 +44,21 @@ var tiersUpdateCmd = &cobra.Command{
 	RunE:  runTiersUpdate,
 }
 
+var tiersURLCmd = &cobra.Command{
+	Use:   "url <id-or-slug>",
+	Short: "Print the link that opens signup for a tier",
+	Long: `Print the link that opens the portal's signup for a tier, monthly unless
+--yearly is given (#/portal/signup/<id>/monthly). -o json also prints the
+value for a data-portal attribute, which opens the portal in place on the
+site itself.`,
+	Example: `  specter tiers url gold
+  specter tiers url gold --yearly`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTiersURL,
+}
+
+var tiersURLYearly bool
+
 var (
 	tierSlug          string
 	tierDescription   string
@@ -56,12 +71,20 @@ var (
 	tierTrialDays     int
 )
 
+var tiersQuery listQuery
+
 func init() {
 	rootCmd.AddCommand(tiersCmd)
 	tiersCmd.AddCommand(tiersListCmd)
 	tiersCmd.AddCommand(tiersGetCmd)
 	tiersCmd.AddCommand(tiersCreateCmd)
 	tiersCmd.AddCommand(tiersUpdateCmd)
+	tiersCmd.AddCommand(tiersURLCmd)
+
+	tiersQuery.addFlags(tiersListCmd, "type:paid")
+	addExecFlag(tiersListCmd)
+
+	tiersURLCmd.Flags().BoolVar(&tiersURLYearly, "yearly", false, "Link to the yearly price instead of the monthly one")
 
 	tiersCreateCmd.Flags().StringVar(&tierSlug, "slug", "", "Tier slug")
 	tiersCreateCmd.Flags().StringVar(&tierDescription, "description", "", "Tier description")
@@ -81,65 +104,28 @@ func init() {
 	tiersUpdateCmd.Flags().IntVar(&tierTrialDays, "trial-days", 0, "Update trial period")
 }
 
-type Tier struct {
-	ID               string `json:"id"`
-	Name             string `json:"name"`
-	Slug             string `json:"slug"`
-	Description      string `json:"description,omitempty"`
-	Active           bool   `json:"active"`
-	Type             string `json:"type"`
-	WelcomePageURL   string `json:"welcome_page_url,omitempty"`
-	CreatedAt        string `json:"created_at"`
-	UpdatedAt        string `json:"updated_at"`
-	Visibility       string `json:"visibility"`
-	MonthlyPrice     int    `json:"monthly_price,omitempty"`
-	YearlyPrice      int    `json:"yearly_price,omitempty"`
-	Currency         string `json:"currency,omitempty"`
-	TrialDays        int    `json:"trial_days"`
-}
-
-type tiersResponse struct {
-	Tiers []Tier `json:"tiers"`
-	Meta  struct {
-		Pagination struct {
-			Page  int `json:"page"`
-			Limit int `json:"limit"`
-			Pages int `json:"pages"`
-			Total int `json:"total"`
-			Next  int `json:"next"`
-		} `json:"pagination"`
-	} `json:"meta"`
-}
-
 func runTiersList(cmd *cobra.Command, args []string) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
-
-	data, err := client.Get("/tiers/", nil)
-	if err != nil {
-		return err
-	}
-
-	var resp tiersResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
+	client := newClient(cfg)
 
-	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(resp.Tiers)
+	var tiers []Tier
+	for t, err := range client.Tiers.All(context.Background(), tiersQuery.options("")) {
+		if err != nil {
+			return err
+		}
+		tiers = append(tiers, t)
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tNAME\tTYPE\tACTIVE\tVISIBILITY")
-	for _, t := range resp.Tiers {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\n", t.ID, t.Name, t.Type, t.Active, t.Visibility)
-	}
-	return w.Flush()
+	return render(tiers, []output.Column[Tier]{
+		{Header: "ID", Value: func(t Tier) string { return t.ID }},
+		{Header: "NAME", Value: func(t Tier) string { return t.Name }},
+		{Header: "TYPE", Value: func(t Tier) string { return t.Type }},
+		{Header: "ACTIVE", Value: func(t Tier) string { return strconv.FormatBool(t.Active) }},
+		{Header: "VISIBILITY", Value: func(t Tier) string { return t.Visibility }},
+	})
 }
 
 func runTiersGet(cmd *cobra.Command, args []string) error {
@@ -147,7 +133,7 @@ func runTiersGet(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	tier, err := getTier(client, args[0])
 	if err != nil {
@@ -155,9 +141,7 @@ func runTiersGet(cmd *cobra.Command, args []string) error {
 	}
 
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(tier)
+		return printJSON(tier)
 	}
 
 	fmt.Printf("ID:          %s\n", tier.ID)
@@ -186,7 +170,7 @@ func runTiersCreate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	tier := map[string]interface{}{
 		"name": args[0],
@@ -215,30 +199,13 @@ func runTiersCreate(cmd *cobra.Command, args []string) error {
 		tier["trial_days"] = tierTrialDays
 	}
 
-	body := map[string]interface{}{
-		"tiers": []interface{}{tier},
-	}
-
-	data, err := client.Post("/tiers/", body)
+	created, err := client.Tiers.Create(context.Background(), tier, nil)
 	if err != nil {
 		return err
 	}
 
-	var resp tiersResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
-
-	if len(resp.Tiers) == 0 {
-		return fmt.Errorf("no tier in response")
-	}
-
-	created := resp.Tiers[0]
-
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(created)
+		return printJSON(created)
 	}
 
 	fmt.Printf("Created tier: %s\n", created.Name)
@@ -252,7 +219,7 @@ func runTiersUpdate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	existing, err := getTier(client, args[0])
 	if err != nil {
@@ -290,30 +257,13 @@ func runTiersUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no updates specified")
 	}
 
-	body := map[string]interface{}{
-		"tiers": []interface{}{tier},
-	}
-
-	data, err := client.Put(fmt.Sprintf("/tiers/%s/", existing.ID), body)
+	updated, err := client.Tiers.Update(context.Background(), existing.ID, tier, nil)
 	if err != nil {
 		return err
 	}
 
-	var resp tiersResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
-
-	if len(resp.Tiers) == 0 {
-		return fmt.Errorf("no tier in response")
-	}
-
-	updated := resp.Tiers[0]
-
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(updated)
+		return printJSON(updated)
 	}
 
 	fmt.Printf("Updated tier: %s\n", updated.Name)
@@ -322,29 +272,43 @@ func runTiersUpdate(cmd *cobra.Command, args []string) error {
 }
 
 func getTier(client *api.Client, idOrSlug string) (*Tier, error) {
-	data, err := client.Get(fmt.Sprintf("/tiers/%s/", idOrSlug), nil)
-	if err == nil {
-		var resp tiersResponse
-		if err := json.Unmarshal(data, &resp); err == nil && len(resp.Tiers) > 0 {
-			return &resp.Tiers[0], nil
-		}
-	}
+	return client.Tiers.Lookup(context.Background(), idOrSlug, nil)
+}
 
-	params := url.Values{}
-	params.Set("filter", fmt.Sprintf("slug:%s", idOrSlug))
-	data, err = client.Get("/tiers/", params)
+func runTiersURL(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
 	if err != nil {
-		return nil, err
+		return err
 	}
+	client := newClient(cfg)
 
-	var resp tiersResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return nil, fmt.Errorf("parsing response: %w", err)
+	tier, err := getTier(client, args[0])
+	if err != nil {
+		return err
 	}
-
-	if len(resp.Tiers) == 0 {
-		return nil, fmt.Errorf("tier not found: %s", idOrSlug)
+	cadence, price := "monthly", tier.MonthlyPrice
+	if tiersURLYearly {
+		cadence, price = "yearly", tier.YearlyPrice
+	}
+	if tier.Type != "free" && price == 0 {
+		return fmt.Errorf("tier %q has no %s price", tier.Name, cadence)
+	}
+	if !tier.Active {
+		fmt.Fprintf(os.Stderr, "Warning: tier %q is archived, so its link won't work\n", tier.Name)
 	}
 
-	return &resp.Tiers[0], nil
+	siteURL, err := portalSiteURL(client, cfg)
+	if err != nil {
+		return err
+	}
+	name := tier.Name
+	if tier.Type != "free" {
+		name += " (" + cadence + ")"
+	}
+	link := portalLink(siteURL, name, tierPortal(*tier, cadence))
+	if config.OutputFormat() == "json" {
+		return printJSON(link)
+	}
+	fmt.Println(link.URL)
+	return nil
 }
@@ -1,15 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/url"
 	"os"
-	"text/tabwriter"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/teal-bauer/specter/api"
 	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
 )
 
 var usersCmd = &cobra.Command{
@@ -30,51 +31,74 @@ var usersGetCmd = &cobra.Command{
 	RunE:  runUsersGet,
 }
 
-var usersLimit int
+var usersInviteCmd = &cobra.Command{
+	Use:   "invite <email>",
+	Short: "Invite a new staff user",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUsersInvite,
+}
+
+var usersUpdateCmd = &cobra.Command{
+	Use:   "update <id-or-slug>",
+	Short: "Update a user",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUsersUpdate,
+}
+
+var usersDeleteCmd = &cobra.Command{
+	Use:   "delete <id-or-slug>",
+	Short: "Delete a user",
+	Long:  "Delete a staff user. Their posts are transferred to the site owner.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUsersDelete,
+}
+
+var usersSetRoleCmd = &cobra.Command{
+	Use:   "set-role <id-or-slug> <role>",
+	Short: "Change a user's role",
+	Long: `Change a staff user's role, e.g. to Editor or Administrator.
+
+Use the role "Owner" to transfer site ownership to an Administrator. The
+current owner becomes an Administrator. Since only the new owner can transfer
+it back, you'll be asked to type the user's email to confirm.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runUsersSetRole,
+}
+
+var (
+	usersLimit   int
+	userRole     string
+	userName     string
+	userBio      string
+	userWebsite  string
+	userLocation string
+	userForce    bool
+	usersQuery   listQuery
+)
 
 func init() {
 	rootCmd.AddCommand(usersCmd)
 	usersCmd.AddCommand(usersListCmd)
 	usersCmd.AddCommand(usersGetCmd)
+	usersCmd.AddCommand(usersInviteCmd)
+	usersCmd.AddCommand(usersUpdateCmd)
+	usersCmd.AddCommand(usersDeleteCmd)
+	usersCmd.AddCommand(usersSetRoleCmd)
 
 	usersListCmd.Flags().IntVar(&usersLimit, "limit", 15, "Number of users to return")
-}
+	usersQuery.addFlags(usersListCmd, "status:active")
+	addExecFlag(usersListCmd)
+
+	usersInviteCmd.Flags().StringVar(&userRole, "role", "Contributor", "Role name: Contributor, Author, Editor, or Administrator")
 
-type User struct {
-	ID               string `json:"id"`
-	Name             string `json:"name"`
-	Slug             string `json:"slug"`
-	Email            string `json:"email"`
-	ProfileImage     string `json:"profile_image,omitempty"`
-	CoverImage       string `json:"cover_image,omitempty"`
-	Bio              string `json:"bio,omitempty"`
-	Website          string `json:"website,omitempty"`
-	Location         string `json:"location,omitempty"`
-	Status           string `json:"status"`
-	Accessibility    string `json:"accessibility,omitempty"`
-	CreatedAt        string `json:"created_at"`
-	LastSeen         string `json:"last_seen,omitempty"`
-	URL              string `json:"url,omitempty"`
-	Roles            []Role `json:"roles,omitempty"`
-}
-
-type Role struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-}
-
-type usersResponse struct {
-	Users []User `json:"users"`
-	Meta  struct {
-		Pagination struct {
-			Page  int `json:"page"`
-			Limit int `json:"limit"`
-			Pages int `json:"pages"`
-			Total int `json:"total"`
-			Next  int `json:"next"`
-		} `json:"pagination"`
-	} `json:"meta"`
+	usersUpdateCmd.Flags().StringVar(&userName, "name", "", "Update name")
+	usersUpdateCmd.Flags().StringVar(&userBio, "bio", "", "Update bio")
+	usersUpdateCmd.Flags().StringVar(&userWebsite, "website", "", "Update website")
+	usersUpdateCmd.Flags().StringVar(&userLocation, "location", "", "Update location")
+	usersUpdateCmd.Flags().StringVar(&userRole, "role", "", "Update role by name")
+
+	usersDeleteCmd.Flags().BoolVar(&userForce, "force", false, "Delete without asking for confirmation")
+	usersSetRoleCmd.Flags().BoolVar(&userForce, "force", false, "Transfer ownership without asking for confirmation")
 }
 
 func runUsersList(cmd *cobra.Command, args []string) error {
@@ -82,33 +106,24 @@ func runUsersList(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
-
-	params := url.Values{}
-	params.Set("limit", fmt.Sprintf("%d", usersLimit))
+	client := newClient(cfg)
 
-	data, err := client.Get("/users/", params)
+	opts := usersQuery.options("")
+	opts.Limit = usersLimit
+	list, err := client.Users.List(context.Background(), opts)
 	if err != nil {
 		return err
 	}
 
-	var resp usersResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
-
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(resp.Users)
+		return encodePage("users", list.Items, list.Pagination)
 	}
-
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tNAME\tEMAIL\tSTATUS")
-	for _, u := range resp.Users {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", u.ID, u.Name, u.Email, u.Status)
-	}
-	return w.Flush()
+	return render(list.Items, []output.Column[User]{
+		{Header: "ID", Value: func(u User) string { return u.ID }},
+		{Header: "NAME", Value: func(u User) string { return u.Name }},
+		{Header: "EMAIL", Value: func(u User) string { return u.Email }},
+		{Header: "STATUS", Value: func(u User) string { return u.Status }},
+	})
 }
 
 func runUsersGet(cmd *cobra.Command, args []string) error {
@@ -116,7 +131,7 @@ func runUsersGet(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := api.NewClient(cfg)
+	client := newClient(cfg)
 
 	user, err := getUser(client, args[0])
 	if err != nil {
@@ -124,9 +139,7 @@ func runUsersGet(cmd *cobra.Command, args []string) error {
 	}
 
 	if config.OutputFormat() == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(user)
+		return printJSON(user)
 	}
 
 	printUser(*user)
@@ -163,30 +176,228 @@ func printUser(u User) {
 	}
 }
 
-func getUser(client *api.Client, idOrSlug string) (*User, error) {
-	data, err := client.Get(fmt.Sprintf("/users/%s/", idOrSlug), nil)
-	if err == nil {
-		var resp usersResponse
-		if err := json.Unmarshal(data, &resp); err == nil && len(resp.Users) > 0 {
-			return &resp.Users[0], nil
-		}
+func runUsersInvite(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	role, err := findRole(client, userRole)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{
+		"invites": []interface{}{
+			map[string]string{
+				"email":   args[0],
+				"role_id": role.ID,
+			},
+		},
 	}
 
-	params := url.Values{}
-	params.Set("filter", fmt.Sprintf("slug:%s", idOrSlug))
-	data, err = client.Get("/users/", params)
+	data, err := client.Post("/invites/", body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var resp usersResponse
+	var resp invitesResponse
 	if err := json.Unmarshal(data, &resp); err != nil {
-		return nil, fmt.Errorf("parsing response: %w", err)
+		return fmt.Errorf("parsing response: %w", err)
+	}
+
+	if len(resp.Invites) == 0 {
+		return fmt.Errorf("no invite in response")
+	}
+
+	created := resp.Invites[0]
+
+	if config.OutputFormat() == "json" {
+		return printJSON(created)
+	}
+
+	fmt.Printf("Invited: %s\n", created.Email)
+	fmt.Printf("  ID:     %s\n", created.ID)
+	fmt.Printf("  Role:   %s\n", role.Name)
+	fmt.Printf("  Status: %s\n", created.Status)
+	return nil
+}
+
+func runUsersUpdate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	existing, err := getUser(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	user := map[string]interface{}{}
+
+	if userName != "" {
+		user["name"] = userName
+	}
+	if cmd.Flags().Changed("bio") {
+		user["bio"] = userBio
+	}
+	if cmd.Flags().Changed("website") {
+		user["website"] = userWebsite
+	}
+	if cmd.Flags().Changed("location") {
+		user["location"] = userLocation
+	}
+	if userRole != "" {
+		role, err := findRole(client, userRole)
+		if err != nil {
+			return err
+		}
+		user["roles"] = []map[string]string{{"id": role.ID}}
+	}
+
+	if len(user) == 0 {
+		return fmt.Errorf("no updates specified")
+	}
+
+	updated, err := updateUser(client, existing.ID, user)
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(updated)
+	}
+
+	fmt.Printf("Updated user: %s\n", updated.Name)
+	fmt.Printf("  ID: %s\n", updated.ID)
+	return nil
+}
+
+func runUsersDelete(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	existing, err := getUser(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := confirmOrAbort(fmt.Sprintf("Delete user '%s' <%s> (%s)?", existing.Name, existing.Email, existing.ID), userForce); err != nil {
+		return err
+	}
+
+	if err := client.Users.Delete(context.Background(), existing.ID); err != nil {
+		return err
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(map[string]string{
+			"deleted": existing.ID,
+			"name":    existing.Name,
+		})
+	}
+
+	fmt.Printf("Deleted user: %s (%s)\n", existing.Name, existing.ID)
+	return nil
+}
+
+func runUsersSetRole(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
 	}
+	client := newClient(cfg)
 
-	if len(resp.Users) == 0 {
-		return nil, fmt.Errorf("user not found: %s", idOrSlug)
+	existing, err := getUser(client, args[0])
+	if err != nil {
+		return err
 	}
 
-	return &resp.Users[0], nil
+	var updated *User
+	if strings.EqualFold(args[1], "owner") {
+		if !userForce && !config.FlagYes {
+			fmt.Fprintf(os.Stderr, "This transfers ownership of the site to %s <%s>.\n", existing.Name, existing.Email)
+			ok, err := confirmTyped("Type the user's email to confirm", existing.Email)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("aborted")
+			}
+		}
+
+		body := map[string]interface{}{
+			"owner": []interface{}{map[string]string{"id": existing.ID}},
+		}
+		data, err := client.Put("/users/owner/", body)
+		if err != nil {
+			return err
+		}
+
+		var resp struct {
+			Users []User `json:"users"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return fmt.Errorf("parsing response: %w", err)
+		}
+		for i, u := range resp.Users {
+			if u.ID == existing.ID {
+				updated = &resp.Users[i]
+			}
+		}
+		if updated == nil {
+			return fmt.Errorf("new owner not in response")
+		}
+	} else {
+		role, err := findRole(client, args[1])
+		if err != nil {
+			return err
+		}
+		updated, err = updateUser(client, existing.ID, map[string]interface{}{
+			"roles": []map[string]string{{"id": role.ID}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(updated)
+	}
+
+	var roles []string
+	for _, r := range updated.Roles {
+		roles = append(roles, r.Name)
+	}
+	fmt.Printf("Updated user: %s\n", updated.Name)
+	fmt.Printf("  ID:   %s\n", updated.ID)
+	fmt.Printf("  Role: %s\n", strings.Join(roles, ", "))
+	return nil
+}
+
+func updateUser(client *api.Client, id string, user map[string]interface{}) (*User, error) {
+	return client.Users.Update(context.Background(), id, user, nil)
+}
+
+// listAllUsers returns every staff user, including their roles
+func listAllUsers(client *api.Client) ([]User, error) {
+	opts := &api.ListOptions{ReadOptions: api.ReadOptions{Include: []string{"roles"}}}
+	var users []User
+	for u, err := range client.Users.All(context.Background(), opts) {
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+func getUser(client *api.Client, idOrSlug string) (*User, error) {
+	return client.Users.Lookup(context.Background(), idOrSlug, nil)
 }
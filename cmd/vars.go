@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// contentVars holds the --var and --vars flags that turn a markdown file
+// into a template
+type contentVars struct {
+	pairs []string
+	file  string
+	// defaults, if not nil, are available to every file, which is then
+	// always treated as a template
+	defaults map[string]string
+}
+
+// contentVarsFlags is shared by the commands that read markdown files
+var contentVarsFlags contentVars
+
+// addFlags registers --var and --vars on cmd
+func (v *contentVars) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&v.pairs, "var", nil, "Template variable for {{ .key }} in the file, as key=value (repeatable)")
+	cmd.Flags().StringVar(&v.file, "vars", "", "YAML or JSON file of template variables")
+}
+
+// load returns the defaults and the variables from --vars and --var, with
+// --var taking precedence, or nil if none are set and the file isn't a
+// template
+func (v *contentVars) load() (map[string]string, error) {
+	if v.file == "" && len(v.pairs) == 0 && v.defaults == nil {
+		return nil, nil
+	}
+
+	vars := map[string]string{}
+	for key, value := range v.defaults {
+		vars[key] = value
+	}
+	if v.file != "" {
+		data, err := os.ReadFile(v.file)
+		if err != nil {
+			return nil, fmt.Errorf("reading vars file: %w", err)
+		}
+		// YAML is a superset of JSON, so this reads both
+		if err := yaml.Unmarshal(data, &vars); err != nil {
+			return nil, fmt.Errorf("parsing vars file %s: %w", v.file, err)
+		}
+	}
+	for _, pair := range v.pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var %q: expected key=value", pair)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/teal-bauer/specter/api"
+	"github.com/teal-bauer/specter/internal/config"
+	"github.com/teal-bauer/specter/internal/output"
+)
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Manage webhooks",
+}
+
+var webhooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List webhooks of all custom integrations",
+	RunE:  runWebhooksList,
+}
+
+var webhooksRotateSecretCmd = &cobra.Command{
+	Use:   "rotate-secret <id>",
+	Short: "Generate a new signing secret for a webhook",
+	Long: `Generate a new signing secret for a webhook and print it.
+
+The secret is printed only once; update the receiving service right away.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWebhooksRotateSecret,
+}
+
+var webhooksForce bool
+
+func init() {
+	rootCmd.AddCommand(webhooksCmd)
+	webhooksCmd.AddCommand(webhooksListCmd)
+	webhooksCmd.AddCommand(webhooksRotateSecretCmd)
+
+	addExecFlag(webhooksListCmd)
+	webhooksRotateSecretCmd.Flags().BoolVar(&webhooksForce, "force", false, "Rotate without asking for confirmation")
+}
+
+type Webhook struct {
+	ID            string `json:"id"`
+	Event         string `json:"event"`
+	TargetURL     string `json:"target_url"`
+	Name          string `json:"name,omitempty"`
+	Secret        string `json:"secret,omitempty"`
+	Status        string `json:"status,omitempty"`
+	IntegrationID string `json:"integration_id,omitempty"`
+	LastTriggered string `json:"last_triggered_at,omitempty"`
+}
+
+type webhooksResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+func runWebhooksList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	webhooks, err := listWebhooks(client)
+	if err != nil {
+		return err
+	}
+
+	return render(webhooks, []output.Column[Webhook]{
+		{Header: "ID", Value: func(h Webhook) string { return h.ID }},
+		{Header: "EVENT", Value: func(h Webhook) string { return h.Event }},
+		{Header: "TARGET", Value: func(h Webhook) string { return h.TargetURL }},
+		{Header: "STATUS", Value: func(h Webhook) string { return h.Status }},
+	})
+}
+
+func runWebhooksRotateSecret(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+
+	if err := confirmOrAbort(fmt.Sprintf("Replace the secret of webhook %s? The receiving service must be updated", args[0]), webhooksForce); err != nil {
+		return err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{
+		"webhooks": []interface{}{
+			map[string]string{"secret": secret},
+		},
+	}
+
+	data, err := client.Put(fmt.Sprintf("/webhooks/%s/", args[0]), body)
+	if err != nil {
+		return err
+	}
+
+	var resp webhooksResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	if len(resp.Webhooks) == 0 {
+		return fmt.Errorf("webhook not found: %s", args[0])
+	}
+
+	if config.OutputFormat() == "json" {
+		return printJSON(map[string]string{
+			"id":     resp.Webhooks[0].ID,
+			"secret": secret,
+		})
+	}
+
+	fmt.Fprintf(os.Stderr, "Rotated secret for webhook %s (%s). It won't be shown again.\n",
+		resp.Webhooks[0].ID, resp.Webhooks[0].TargetURL)
+	fmt.Println(secret)
+	return nil
+}
+
+// listWebhooks returns the webhooks of all integrations. Ghost has no
+// endpoint to browse webhooks directly.
+func listWebhooks(client *api.Client) ([]Webhook, error) {
+	params := url.Values{}
+	params.Set("include", "webhooks")
+	params.Set("limit", "all")
+
+	data, err := client.Get("/integrations/", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Integrations []struct {
+			Webhooks []Webhook `json:"webhooks"`
+		} `json:"integrations"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	var webhooks []Webhook
+	for _, i := range resp.Integrations {
+		webhooks = append(webhooks, i.Webhooks...)
+	}
+	return webhooks, nil
+}
+
+// generateSecret returns a random 32-byte hex-encoded secret
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
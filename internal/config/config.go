@@ -1,17 +1,69 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
+	"github.com/teal-bauer/specter/internal/content"
+	"github.com/teal-bauer/specter/internal/keyring"
 	"gopkg.in/yaml.v3"
 )
 
 // Config holds a single instance configuration
 type Config struct {
 	URL string `yaml:"url"`
-	Key string `yaml:"key"`
+	Key string `yaml:"key,omitempty"`
+	// Keyring keeps Key in the OS keychain instead of the config file
+	Keyring bool `yaml:"keyring,omitempty"`
+	// ContentKey is a Content API key, for read-only access to published
+	// content with --content-api
+	ContentKey string          `yaml:"content_key,omitempty"`
+	Markdown   content.Options `yaml:"markdown,omitempty"`
+	// Frozen blocks changes through this profile unless --override-freeze
+	// is given
+	Frozen bool `yaml:"frozen,omitempty"`
+	// UserAgent and Headers are sent with every request, e.g. to get past
+	// a WAF or a proxy in front of Ghost
+	UserAgent string            `yaml:"user_agent,omitempty"`
+	Headers   map[string]string `yaml:"headers,omitempty"`
+	// CFAccess is a Cloudflare Access service token for sites behind
+	// Cloudflare Zero Trust
+	CFAccess *CFAccess `yaml:"cf_access,omitempty"`
+	// ProxyToken is a bearer token for an authenticating proxy in front of
+	// Ghost, sent in ProxyTokenHeader (default Proxy-Authorization)
+	ProxyToken       string `yaml:"proxy_token,omitempty"`
+	ProxyTokenHeader string `yaml:"proxy_token_header,omitempty"`
+	// MaxResponseMB caps the size of API responses (default 100 MB, -1 for
+	// no limit)
+	MaxResponseMB int `yaml:"max_response_mb,omitempty"`
+
+	// Output is the default output format, e.g. json
+	Output string `yaml:"output,omitempty"`
+	// DefaultStatus is the status of new posts and pages whose frontmatter
+	// doesn't set one: draft (default) or published
+	DefaultStatus string `yaml:"default_status,omitempty"`
+	// Newsletter is the newsletter that published posts are emailed
+	// through unless the frontmatter or --newsletter picks another
+	Newsletter string `yaml:"newsletter,omitempty"`
+	// Timezone is the IANA timezone that schedule times without an offset,
+	// such as "friday 9am", are in, instead of the local one
+	Timezone string `yaml:"timezone,omitempty"`
+
+	// Name is the profile this configuration was loaded from, if any
+	Name string `yaml:"-"`
+}
+
+// CFAccess holds a Cloudflare Access service token
+type CFAccess struct {
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
 }
 
 // FileConfig holds the full config file structure
@@ -29,6 +81,24 @@ var (
 	FlagKey     string
 	FlagOutput  string
 	FlagProfile string
+	// FlagProfiles and FlagAllProfiles run a command once for each of
+	// several profiles
+	FlagProfiles    []string
+	FlagAllProfiles bool
+
+	FlagOverrideFreeze bool
+
+	FlagNoHeaders bool
+	FlagWide      bool
+
+	FlagDebug bool
+	// FlagYes answers yes to every confirmation prompt
+	FlagYes bool
+	// FlagDryRun prints changes instead of sending them
+	FlagDryRun bool
+	// FlagContentAPI reads through the Content API with the content key
+	// instead of the Admin API
+	FlagContentAPI bool
 )
 
 // Load reads configuration from file, environment, and CLI flags
@@ -39,19 +109,26 @@ func Load() (*Config, error) {
 	// Try config file first
 	fileCfg, _ := loadFileConfig()
 	if fileCfg != nil {
-		// Determine which profile to use
-		profile := FlagProfile
-		if profile == "" {
-			profile = os.Getenv("GHOST_PROFILE")
-		}
-		if profile == "" {
-			profile = fileCfg.Default
-		}
-
+		profile := selectedProfile(fileCfg)
 		if profile != "" && fileCfg.Instances != nil {
 			if inst, ok := fileCfg.Instances[profile]; ok {
 				cfg.URL = inst.URL
 				cfg.Key = inst.Key
+				cfg.Keyring = inst.Keyring
+				cfg.ContentKey = inst.ContentKey
+				cfg.Markdown = inst.Markdown
+				cfg.Frozen = inst.Frozen
+				cfg.UserAgent = inst.UserAgent
+				cfg.Headers = inst.Headers
+				cfg.CFAccess = inst.CFAccess
+				cfg.ProxyToken = inst.ProxyToken
+				cfg.ProxyTokenHeader = inst.ProxyTokenHeader
+				cfg.MaxResponseMB = inst.MaxResponseMB
+				cfg.Output = inst.Output
+				cfg.DefaultStatus = inst.DefaultStatus
+				cfg.Newsletter = inst.Newsletter
+				cfg.Timezone = inst.Timezone
+				cfg.Name = profile
 			}
 		}
 
@@ -69,6 +146,16 @@ func Load() (*Config, error) {
 	if key := os.Getenv("GHOST_ADMIN_KEY"); key != "" {
 		cfg.Key = key
 	}
+	if key := os.Getenv("GHOST_CONTENT_KEY"); key != "" {
+		cfg.ContentKey = key
+	}
+
+	if id, secret := os.Getenv("CF_ACCESS_CLIENT_ID"), os.Getenv("CF_ACCESS_CLIENT_SECRET"); id != "" && secret != "" {
+		cfg.CFAccess = &CFAccess{ClientID: id, ClientSecret: secret}
+	}
+	if token := os.Getenv("GHOST_PROXY_TOKEN"); token != "" {
+		cfg.ProxyToken = token
+	}
 
 	// CLI flags override everything
 	if FlagURL != "" {
@@ -78,17 +165,220 @@ func Load() (*Config, error) {
 		cfg.Key = FlagKey
 	}
 
+	// Only ask the keychain if the key isn't given otherwise, since it may
+	// prompt for permission
+	if cfg.Key == "" && cfg.Keyring && !FlagContentAPI {
+		key, err := keyring.Get(cfg.Name)
+		if err != nil {
+			return nil, fmt.Errorf("reading admin key of profile '%s': %w", cfg.Name, err)
+		}
+		cfg.Key = key
+	}
+
 	// Validate
 	if cfg.URL == "" {
 		return nil, fmt.Errorf("ghost URL not configured (use 'specter login', set GHOST_URL, or use --url)")
 	}
-	if cfg.Key == "" {
+	if FlagContentAPI {
+		if cfg.ContentKey == "" {
+			return nil, fmt.Errorf("ghost content API key not configured (set content_key in the profile or GHOST_CONTENT_KEY)")
+		}
+	} else if cfg.Key == "" {
 		return nil, fmt.Errorf("ghost admin key not configured (use 'specter login', set GHOST_ADMIN_KEY, or use --key)")
 	}
+	switch cfg.DefaultStatus {
+	case "", "draft", "published":
+	default:
+		return nil, fmt.Errorf("invalid default_status %q in profile '%s': must be draft or published", cfg.DefaultStatus, cfg.Name)
+	}
+	// time.LoadLocation also accepts "Local", which differs between the
+	// machines a profile is used on
+	if cfg.Timezone != "" {
+		if _, err := time.LoadLocation(cfg.Timezone); err != nil || cfg.Timezone == "Local" {
+			return nil, fmt.Errorf("invalid timezone %q in profile '%s': must be an IANA name like Europe/Berlin", cfg.Timezone, cfg.Name)
+		}
+	}
 
 	return cfg, nil
 }
 
+// selectedProfile returns the profile chosen by --profile, GHOST_PROFILE or
+// the config file's default
+func selectedProfile(fileCfg *FileConfig) string {
+	if FlagProfile != "" {
+		return FlagProfile
+	}
+	if profile := os.Getenv("GHOST_PROFILE"); profile != "" {
+		return profile
+	}
+	return fileCfg.Default
+}
+
+// CurrentProfile returns the name of the profile commands use, or "" if
+// none is selected
+func CurrentProfile() string {
+	fileCfg, err := loadFileConfig()
+	if err != nil {
+		fileCfg = &FileConfig{}
+	}
+	return selectedProfile(fileCfg)
+}
+
+// LoadProfile returns the configuration of a named profile from the config
+// file. Unlike Load, it ignores environment variables and CLI flags, which
+// apply to the selected profile only.
+func LoadProfile(name string) (*Config, error) {
+	fileCfg, err := loadFileConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	inst, ok := fileCfg.Instances[name]
+	if !ok {
+		return nil, fmt.Errorf("profile not found: %s", name)
+	}
+	if inst.Keyring {
+		key, err := keyring.Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("reading admin key of profile '%s': %w", name, err)
+		}
+		inst.Key = key
+	}
+	if inst.URL == "" || inst.Key == "" {
+		return nil, fmt.Errorf("profile %s is missing a URL or admin key", name)
+	}
+	inst.Name = name
+	return &inst, nil
+}
+
+// GetInstance returns the stored settings of a profile, which may be
+// incomplete
+func GetInstance(name string) (Config, bool) {
+	fileCfg, err := loadFileConfig()
+	if err != nil {
+		return Config{}, false
+	}
+	inst, ok := fileCfg.Instances[name]
+	return inst, ok
+}
+
+// SetFrozen sets or clears the content freeze of a profile
+func SetFrozen(name string, frozen bool) error {
+	fileCfg, err := loadFileConfig()
+	if err != nil {
+		return err
+	}
+
+	inst, ok := fileCfg.Instances[name]
+	if !ok {
+		return fmt.Errorf("profile not found: %s", name)
+	}
+	inst.Frozen = frozen
+	fileCfg.Instances[name] = inst
+
+	return writeFileConfig(fileCfg)
+}
+
+// ClearKey removes the admin key of a profile from the config file and the
+// keychain, keeping its other settings for the next login
+func ClearKey(name string) error {
+	fileCfg, err := loadFileConfig()
+	if err != nil {
+		return err
+	}
+
+	inst, ok := fileCfg.Instances[name]
+	if !ok {
+		return fmt.Errorf("profile not found: %s", name)
+	}
+	if inst.Keyring {
+		if err := keyring.Delete(name); err != nil {
+			return err
+		}
+	}
+	inst.Key = ""
+	inst.Keyring = false
+	fileCfg.Instances[name] = inst
+
+	return writeFileConfig(fileCfg)
+}
+
+// RemoveInstance deletes a profile and its keychain entry. If it was the
+// default, there is no default afterwards.
+func RemoveInstance(name string) error {
+	fileCfg, err := loadFileConfig()
+	if err != nil {
+		return err
+	}
+
+	inst, ok := fileCfg.Instances[name]
+	if !ok {
+		return fmt.Errorf("profile not found: %s", name)
+	}
+	if inst.Keyring {
+		if err := keyring.Delete(name); err != nil {
+			return err
+		}
+	}
+	delete(fileCfg.Instances, name)
+	if fileCfg.Default == name {
+		fileCfg.Default = ""
+	}
+
+	return writeFileConfig(fileCfg)
+}
+
+// RenameInstance renames a profile, moving its keychain entry and keeping
+// it the default if it was
+func RenameInstance(oldName, newName string) error {
+	fileCfg, err := loadFileConfig()
+	if err != nil {
+		return err
+	}
+
+	inst, ok := fileCfg.Instances[oldName]
+	if !ok {
+		return fmt.Errorf("profile not found: %s", oldName)
+	}
+	if _, exists := fileCfg.Instances[newName]; exists {
+		return fmt.Errorf("profile already exists: %s", newName)
+	}
+	if inst.Keyring {
+		key, err := keyring.Get(oldName)
+		if err != nil {
+			return fmt.Errorf("reading admin key of profile '%s': %w", oldName, err)
+		}
+		if err := keyring.Set(newName, key); err != nil {
+			return err
+		}
+		if err := keyring.Delete(oldName); err != nil {
+			return err
+		}
+	}
+	delete(fileCfg.Instances, oldName)
+	fileCfg.Instances[newName] = inst
+	if fileCfg.Default == oldName {
+		fileCfg.Default = newName
+	}
+
+	return writeFileConfig(fileCfg)
+}
+
+// SetDefault makes a profile the default
+func SetDefault(name string) error {
+	fileCfg, err := loadFileConfig()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := fileCfg.Instances[name]; !ok {
+		return fmt.Errorf("profile not found: %s", name)
+	}
+	fileCfg.Default = name
+
+	return writeFileConfig(fileCfg)
+}
+
 // ConfigPath returns the path to the config file
 func ConfigPath() string {
 	home, err := os.UserHomeDir()
@@ -98,15 +388,58 @@ func ConfigPath() string {
 	return filepath.Join(home, ".config", "specter", "config.yaml")
 }
 
-func loadFileConfig() (*FileConfig, error) {
+// CacheDir returns the directory for specter's cached data
+func CacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "specter")
+}
+
+// configPaths returns the paths a config file is read from, in order
+func configPaths() ([]string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, err
 	}
-
-	paths := []string{
+	return []string{
 		filepath.Join(home, ".config", "specter", "config.yaml"),
 		filepath.Join(home, ".specter.yaml"),
+	}, nil
+}
+
+// CheckFile finds the config file and decodes it strictly, reporting the
+// syntax errors and unknown keys that Load ignores. It returns "" if there
+// is no config file.
+func CheckFile() (string, error) {
+	paths, err := configPaths()
+	if err != nil {
+		return "", err
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return path, err
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		var cfg FileConfig
+		if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+			return path, err
+		}
+		return path, nil
+	}
+	return "", nil
+}
+
+func loadFileConfig() (*FileConfig, error) {
+	paths, err := configPaths()
+	if err != nil {
+		return nil, err
 	}
 
 	for _, path := range paths {
@@ -124,20 +457,16 @@ func loadFileConfig() (*FileConfig, error) {
 	return nil, fmt.Errorf("no config file found")
 }
 
-// SaveInstance saves an instance configuration to the config file
+// SaveInstance saves an instance configuration to the config file. If
+// cfg.Keyring is set, the key is stored in the OS keychain instead.
 func SaveInstance(name string, cfg Config, setDefault bool) error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-
-	configDir := filepath.Join(home, ".config", "specter")
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return fmt.Errorf("creating config directory: %w", err)
+	if cfg.Keyring && cfg.Key != "" {
+		if err := keyring.Set(name, cfg.Key); err != nil {
+			return err
+		}
+		cfg.Key = ""
 	}
 
-	configPath := filepath.Join(configDir, "config.yaml")
-
 	// Load existing config or create new
 	fileCfg, _ := loadFileConfig()
 	if fileCfg == nil {
@@ -170,13 +499,21 @@ func SaveInstance(name string, cfg Config, setDefault bool) error {
 		fileCfg.Default = name
 	}
 
-	// Write config
+	return writeFileConfig(fileCfg)
+}
+
+func writeFileConfig(fileCfg *FileConfig) error {
+	configDir := filepath.Dir(ConfigPath())
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
 	data, err := yaml.Marshal(fileCfg)
 	if err != nil {
 		return fmt.Errorf("marshaling config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
+	if err := os.WriteFile(ConfigPath(), data, 0600); err != nil {
 		return fmt.Errorf("writing config: %w", err)
 	}
 
@@ -198,10 +535,27 @@ func ListInstances() ([]string, string, error) {
 	return names, fileCfg.Default, nil
 }
 
-// OutputFormat returns the configured output format
+var (
+	outputConfigOnce sync.Once
+	outputConfig     *FileConfig
+)
+
+// OutputFormat returns the output format: --output, else the selected
+// profile's output setting, else text
 func OutputFormat() string {
 	if FlagOutput != "" {
 		return FlagOutput
 	}
+	// The file is read on first use, since the format is needed before, and
+	// without, loading the full configuration. The profile is looked up on
+	// every call, as --profiles runs a command for several.
+	outputConfigOnce.Do(func() {
+		outputConfig, _ = loadFileConfig()
+	})
+	if outputConfig != nil {
+		if out := outputConfig.Instances[selectedProfile(outputConfig)].Output; out != "" {
+			return out
+		}
+	}
 	return "text"
 }
@@ -0,0 +1,486 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Bookmark is the metadata shown in a bookmark card
+type Bookmark struct {
+	Title       string
+	Description string
+	Icon        string
+	Thumbnail   string
+	Author      string
+	Publisher   string
+}
+
+// BookmarkResolver fetches the metadata of a page for a bookmark card
+type BookmarkResolver func(url string) (*Bookmark, error)
+
+// cardNames are the cards that :::name directives can insert
+var cardNames = map[string]bool{
+	"bookmark": true, "button": true, "callout": true, "embed": true, "gallery": true, "toggle": true,
+}
+
+// calloutColors are the background colors Ghost's editor offers for callouts
+var calloutColors = map[string]bool{
+	"grey": true, "white": true, "blue": true, "green": true, "yellow": true,
+	"red": true, "pink": true, "purple": true, "accent": true,
+}
+
+// kindCard is the node kind of a :::card directive
+var kindCard = ast.NewNodeKind("Card")
+
+// cardNode is a Ghost card written as a fenced directive:
+//
+//	:::callout 💡 color=blue
+//	Markdown content
+//	:::
+//
+// The text after the name is the card's argument, except for key=value
+// options. A longer fence (::::) can enclose shorter ones.
+type cardNode struct {
+	ast.BaseBlock
+	name     string
+	arg      string
+	options  map[string]string
+	fence    int
+	bookmark *Bookmark
+	embed    *Embed
+}
+
+func (n *cardNode) Kind() ast.NodeKind {
+	return kindCard
+}
+
+func (n *cardNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Name": n.name, "Arg": n.arg}, nil)
+}
+
+// cardExtender adds :::card directives for Ghost's bookmark, button,
+// callout, embed, gallery and toggle cards, rendered as the HTML Ghost's editor
+// produces for them so that Ghost imports them as cards
+type cardExtender struct {
+	// warnings collects unknown cards and options
+	warnings *[]string
+}
+
+func (e *cardExtender) Extend(m goldmark.Markdown) {
+	// Ahead of paragraphs, which would otherwise take the fence line
+	m.Parser().AddOptions(parser.WithBlockParsers(
+		util.Prioritized(&cardParser{e}, 90),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&cardRenderer{}, 500),
+	))
+}
+
+type cardParser struct {
+	*cardExtender
+}
+
+func (p *cardParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+func (p *cardParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+	i := pos
+	for i < len(line) && line[i] == ':' {
+		i++
+	}
+	fence := i - pos
+	rest := strings.TrimSpace(string(line[i:]))
+	name, args, _ := strings.Cut(rest, " ")
+	if fence < 3 || name == "" || strings.IndexFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && r != '-' }) >= 0 {
+		return nil, parser.NoChildren
+	}
+	name = strings.ToLower(name)
+
+	node := &cardNode{name: name, fence: fence, options: map[string]string{}}
+	node.arg = parseCardArgs(args, node.options)
+	if !cardNames[name] {
+		*p.warnings = append(*p.warnings, fmt.Sprintf("unknown card :::%s; its content is kept without the card (cards: bookmark, button, callout, embed, gallery, toggle)", name))
+	}
+	if c := node.options["color"]; name == "callout" && c != "" && !calloutColors[c] {
+		*p.warnings = append(*p.warnings, fmt.Sprintf("unknown callout color %q; using grey", c))
+		delete(node.options, "color")
+	}
+	reader.Advance(segment.Len() - 1)
+	return node, parser.HasChildren
+}
+
+func (p *cardParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) >= node.(*cardNode).fence && len(bytes.Trim(trimmed, ":")) == 0 {
+		reader.Advance(segment.Len() - 1)
+		return parser.Close
+	}
+	return parser.Continue | parser.HasChildren
+}
+
+func (p *cardParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+	card := node.(*cardNode)
+	// Callout text and button labels are inline, without a paragraph
+	if card.name != "callout" && card.name != "button" {
+		return
+	}
+	if para, ok := card.FirstChild().(*ast.Paragraph); ok && para.NextSibling() == nil {
+		tb := ast.NewTextBlock()
+		tb.SetLines(para.Lines())
+		for c := para.FirstChild(); c != nil; {
+			next := c.NextSibling()
+			tb.AppendChild(tb, c)
+			c = next
+		}
+		card.ReplaceChild(card, para, tb)
+	}
+}
+
+func (p *cardParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p *cardParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// parseCardArgs splits a directive's arguments into key=value options,
+// whose values may be quoted, and the remaining text
+func parseCardArgs(s string, options map[string]string) string {
+	var rest []string
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		word := s
+		if i := strings.IndexByte(s, ' '); i >= 0 {
+			word = s[:i]
+		}
+		key, value, ok := strings.Cut(word, "=")
+		if !ok || key == "" || strings.IndexFunc(key, func(r rune) bool { return !unicode.IsLetter(r) && r != '_' }) >= 0 {
+			rest = append(rest, word)
+			s = s[len(word):]
+			continue
+		}
+		s = s[len(key)+1:]
+		if q := value; q != "" && (q[0] == '"' || q[0] == '\'') {
+			if end := strings.IndexByte(s[1:], q[0]); end >= 0 {
+				value = s[1 : end+1]
+				s = s[end+2:]
+			} else {
+				value = s[1:]
+				s = ""
+			}
+		} else {
+			s = s[len(value):]
+		}
+		options[strings.ToLower(key)] = value
+	}
+	return strings.Join(rest, " ")
+}
+
+// resolveBookmarks fetches the metadata of every bookmark card in doc. Cards
+// whose page can't be fetched show its URL, with a warning.
+func resolveBookmarks(doc ast.Node, resolve BookmarkResolver, warnings *[]string) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		card, ok := n.(*cardNode)
+		if !entering || !ok || card.name != "bookmark" || card.arg == "" {
+			return ast.WalkContinue, nil
+		}
+		b, err := resolve(card.arg)
+		if err != nil {
+			*warnings = append(*warnings, fmt.Sprintf("could not fetch bookmark %s: %v", card.arg, err))
+			return ast.WalkSkipChildren, nil
+		}
+		card.bookmark = b
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+type cardRenderer struct{}
+
+func (r *cardRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindCard, r.renderCard)
+}
+
+func (r *cardRenderer) renderCard(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*cardNode)
+	esc := html.EscapeString
+	switch n.name {
+	case "callout":
+		if entering {
+			color := n.options["color"]
+			if color == "" {
+				color = "grey"
+			}
+			emoji := n.arg
+			if emoji == "" {
+				emoji = "💡"
+			}
+			fmt.Fprintf(w, "<div class=\"kg-card kg-callout-card kg-callout-card-%s\"><div class=\"kg-callout-emoji\">%s</div><div class=\"kg-callout-text\">", color, esc(emoji))
+		} else {
+			_, _ = w.WriteString("</div></div>\n")
+		}
+	case "toggle":
+		if entering {
+			fmt.Fprintf(w, "<div class=\"kg-card kg-toggle-card\" data-kg-toggle-state=\"close\"><div class=\"kg-toggle-heading\"><h4 class=\"kg-toggle-heading-text\">%s</h4></div><div class=\"kg-toggle-content\">\n", esc(n.arg))
+		} else {
+			_, _ = w.WriteString("</div></div>\n")
+		}
+	case "button":
+		if entering {
+			align := "center"
+			if n.options["align"] == "left" {
+				align = "left"
+			}
+			fmt.Fprintf(w, "<div class=\"kg-card kg-button-card kg-align-%s\"><a href=\"%s\" class=\"kg-btn kg-btn-accent\">%s</a></div>\n",
+				align, esc(n.arg), esc(strings.TrimSpace(plainText(n, source))))
+		}
+		return ast.WalkSkipChildren, nil
+	case "bookmark":
+		if entering {
+			renderBookmark(w, n, strings.TrimSpace(plainText(n, source)))
+		}
+		return ast.WalkSkipChildren, nil
+	case "gallery":
+		if entering {
+			renderGallery(w, n, source)
+		}
+		return ast.WalkSkipChildren, nil
+	case "embed":
+		if entering {
+			renderEmbed(w, n)
+		}
+		return ast.WalkSkipChildren, nil
+	}
+	return ast.WalkContinue, nil
+}
+
+func renderBookmark(w util.BufWriter, n *cardNode, description string) {
+	esc := html.EscapeString
+	b := n.bookmark
+	if b == nil {
+		b = &Bookmark{}
+	}
+	title := firstNonEmpty(n.options["title"], b.Title, n.arg)
+	description = firstNonEmpty(description, b.Description)
+
+	fmt.Fprintf(w, "<figure class=\"kg-card kg-bookmark-card\"><a class=\"kg-bookmark-container\" href=\"%s\"><div class=\"kg-bookmark-content\"><div class=\"kg-bookmark-title\">%s</div>", esc(n.arg), esc(title))
+	if description != "" {
+		fmt.Fprintf(w, "<div class=\"kg-bookmark-description\">%s</div>", esc(description))
+	}
+	_, _ = w.WriteString("<div class=\"kg-bookmark-metadata\">")
+	if b.Icon != "" {
+		fmt.Fprintf(w, "<img class=\"kg-bookmark-icon\" src=\"%s\" alt=\"\">", esc(b.Icon))
+	}
+	if b.Author != "" {
+		fmt.Fprintf(w, "<span class=\"kg-bookmark-author\">%s</span>", esc(b.Author))
+	}
+	if b.Publisher != "" {
+		fmt.Fprintf(w, "<span class=\"kg-bookmark-publisher\">%s</span>", esc(b.Publisher))
+	}
+	_, _ = w.WriteString("</div></div>")
+	if b.Thumbnail != "" {
+		fmt.Fprintf(w, "<div class=\"kg-bookmark-thumbnail\"><img src=\"%s\" alt=\"\"></div>", esc(b.Thumbnail))
+	}
+	_, _ = w.WriteString("</a></figure>\n")
+}
+
+// renderGallery renders the images in a gallery card in rows of three, as
+// Ghost's editor lays them out; other content is left out
+func renderGallery(w util.BufWriter, n *cardNode, source []byte) {
+	esc := html.EscapeString
+	var images []*ast.Image
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if img, ok := c.(*ast.Image); ok && entering {
+			images = append(images, img)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	_, _ = w.WriteString("<figure class=\"kg-card kg-gallery-card kg-width-wide\"><div class=\"kg-gallery-container\">")
+	for _, row := range galleryRows(len(images)) {
+		_, _ = w.WriteString("<div class=\"kg-gallery-row\">")
+		for _, img := range images[row[0]:row[1]] {
+			fmt.Fprintf(w, "<div class=\"kg-gallery-image\"><img src=\"%s\" alt=\"%s\" loading=\"lazy\"></div>",
+				esc(string(img.Destination)), esc(plainText(img, source)))
+		}
+		_, _ = w.WriteString("</div>")
+	}
+	_, _ = w.WriteString("</div>")
+	if caption := n.options["caption"]; caption != "" || n.arg != "" {
+		fmt.Fprintf(w, "<figcaption>%s</figcaption>", esc(firstNonEmpty(caption, n.arg)))
+	}
+	_, _ = w.WriteString("</figure>\n")
+}
+
+// galleryRows splits count images into rows of up to three, avoiding a
+// last row with a single image
+func galleryRows(count int) [][2]int {
+	var rows [][2]int
+	for start := 0; start < count; {
+		size := 3
+		if left := count - start; left < 3 || left == 4 {
+			size = min(left, 2)
+		}
+		rows = append(rows, [2]int{start, start + size})
+		start += size
+	}
+	return rows
+}
+
+// plainText returns the text in n without markup
+func plainText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := c.(type) {
+		case *ast.Text:
+			b.Write(t.Segment.Value(source))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(t.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// card converts a Ghost card back to a :::card directive, if n is one that
+// a directive can write
+func (c *mdConverter) card(n *htmlNode) (string, bool) {
+	classes := strings.Fields(n.attrs["class"])
+	switch {
+	case slices.Contains(classes, "kg-callout-card"):
+		emoji, text := findClass(n, "kg-callout-emoji"), findClass(n, "kg-callout-text")
+		if emoji == nil || text == nil || strings.TrimSpace(textContent(emoji)) == "" {
+			return "", false
+		}
+		head := "callout " + strings.TrimSpace(textContent(emoji))
+		for _, class := range classes {
+			if color, ok := strings.CutPrefix(class, "kg-callout-card-"); ok && color != "grey" {
+				head += " color=" + color
+			}
+		}
+		return directive(head, c.text(text.children)), true
+	case slices.Contains(classes, "kg-toggle-card"):
+		heading, body := findClass(n, "kg-toggle-heading-text"), findClass(n, "kg-toggle-content")
+		if heading == nil || body == nil {
+			return "", false
+		}
+		return directive("toggle "+strings.Join(strings.Fields(textContent(heading)), " "), c.blocks(body.children, "\n\n")), true
+	case slices.Contains(classes, "kg-button-card"):
+		btn := findClass(n, "kg-btn")
+		if btn == nil || btn.attrs["href"] == "" {
+			return "", false
+		}
+		head := "button " + btn.attrs["href"]
+		if slices.Contains(classes, "kg-align-left") {
+			head += " align=left"
+		}
+		return directive(head, c.text(btn.children)), true
+	case slices.Contains(classes, "kg-bookmark-card"):
+		link := findClass(n, "kg-bookmark-container")
+		if link == nil || link.attrs["href"] == "" {
+			return "", false
+		}
+		return directive("bookmark "+link.attrs["href"], ""), true
+	case slices.Contains(classes, "kg-gallery-card"):
+		var images []string
+		for _, cell := range findAllClass(n, "kg-gallery-image") {
+			if img := findTag(cell, "img"); img != nil {
+				images = append(images, c.inline(img))
+			}
+		}
+		if len(images) == 0 {
+			return "", false
+		}
+		head := "gallery"
+		if caption := findTag(n, "figcaption"); caption != nil {
+			head += " " + strings.Join(strings.Fields(textContent(caption)), " ")
+		}
+		return directive(head, strings.Join(images, "\n")), true
+	}
+	return "", false
+}
+
+// directive writes a :::card directive, with a fence longer than any in
+// body
+func directive(head, body string) string {
+	fence := 3
+	for _, line := range strings.Split(body, "\n") {
+		if n := len(line) - len(strings.TrimLeft(line, ":")); n >= fence {
+			fence = n + 1
+		}
+	}
+	colons := strings.Repeat(":", fence)
+	if body == "" {
+		return colons + head + "\n" + colons
+	}
+	return colons + head + "\n" + body + "\n" + colons
+}
+
+// findClass returns the first descendant of n with the given class
+func findClass(n *htmlNode, class string) *htmlNode {
+	if found := findAllClass(n, class); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+// findAllClass returns the descendants of n with the given class, outside
+// of each other
+func findAllClass(n *htmlNode, class string) []*htmlNode {
+	var found []*htmlNode
+	for _, child := range n.children {
+		if slices.Contains(strings.Fields(child.attrs["class"]), class) {
+			found = append(found, child)
+		} else {
+			found = append(found, findAllClass(child, class)...)
+		}
+	}
+	return found
+}
+
+// findTag returns the first descendant of n that is a tag element
+func findTag(n *htmlNode, tag string) *htmlNode {
+	for _, child := range n.children {
+		if child.tag == tag {
+			return child
+		}
+		if found := findTag(child, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package content
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// Embed is the player or widget of an embed card, e.g. a YouTube video, as
+// given by the provider's oEmbed endpoint
+type Embed struct {
+	// Type is the oEmbed type: video, rich or photo
+	Type string
+	HTML string
+}
+
+// EmbedResolver fetches the embed for a URL. It returns an error if the URL
+// can't be embedded.
+type EmbedResolver func(url string) (*Embed, error)
+
+// resolveEmbeds fetches the embeds of :::embed cards and, if bare is set,
+// turns paragraphs that are only a link into embed cards where the link
+// can be embedded. Directives that can't be embedded are warned about and
+// left as links; bare links are left as they are.
+func resolveEmbeds(doc ast.Node, source []byte, resolve EmbedResolver, bare bool, warnings *[]string) {
+	var cards []*cardNode
+	var paragraphs []*ast.Paragraph
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *cardNode:
+			if n.name == "embed" && n.arg != "" {
+				cards = append(cards, n)
+			}
+		case *ast.Paragraph:
+			if bare && bareURL(n, source) != "" {
+				paragraphs = append(paragraphs, n)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, card := range cards {
+		embed, err := resolve(card.arg)
+		if err != nil {
+			*warnings = append(*warnings, fmt.Sprintf("can't embed %s: %v", card.arg, err))
+			continue
+		}
+		card.embed = embed
+	}
+	// Replaced after the walk, which can't follow a node it replaced
+	for _, para := range paragraphs {
+		url := bareURL(para, source)
+		embed, err := resolve(url)
+		if err != nil {
+			continue
+		}
+		card := &cardNode{name: "embed", arg: url, options: map[string]string{}, embed: embed}
+		para.Parent().ReplaceChild(para.Parent(), para, card)
+	}
+}
+
+// bareURL returns the URL a paragraph consists of, or "" if it has anything
+// else
+func bareURL(para *ast.Paragraph, source []byte) string {
+	if para.ChildCount() != 1 {
+		return ""
+	}
+	switch para.FirstChild().(type) {
+	case *ast.AutoLink, *ast.Link:
+	default:
+		return ""
+	}
+	text := strings.TrimSpace(string(para.Lines().Value(source)))
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "<"), ">")
+	if !strings.HasPrefix(text, "https://") && !strings.HasPrefix(text, "http://") || strings.ContainsAny(text, " \t\n") {
+		return ""
+	}
+	return text
+}
+
+// renderEmbed renders an embed card as Ghost's editor does, or a link to
+// its URL if it couldn't be embedded
+func renderEmbed(w util.BufWriter, n *cardNode) {
+	esc := html.EscapeString
+	if n.embed == nil {
+		fmt.Fprintf(w, "<p><a href=\"%s\">%s</a></p>\n", esc(n.arg), esc(n.arg))
+		return
+	}
+	_, _ = w.WriteString("<figure class=\"kg-card kg-embed-card\">")
+	_, _ = w.WriteString(n.embed.HTML)
+	if caption := n.options["caption"]; caption != "" {
+		fmt.Fprintf(w, "<figcaption>%s</figcaption>", esc(caption))
+	}
+	_, _ = w.WriteString("</figure>\n")
+}
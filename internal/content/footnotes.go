@@ -0,0 +1,55 @@
+package content
+
+import (
+	"github.com/yuin/goldmark"
+	gast "github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// footnoteExtender wraps goldmark's footnote extension and replaces the
+// footnote list wrapper with configurable HTML
+type footnoteExtender struct {
+	open  string
+	close string
+}
+
+func footnotes(opts Options) goldmark.Extender {
+	return &footnoteExtender{open: opts.FootnotesOpen, close: opts.FootnotesClose}
+}
+
+func (e *footnoteExtender) Extend(m goldmark.Markdown) {
+	extension.NewFootnote(
+		extension.WithFootnoteBacklinkHTML("↩︎"),
+	).Extend(m)
+	// Lower values take precedence, so this replaces the list renderer
+	// registered by the footnote extension (priority 500)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(e, 400),
+	))
+}
+
+func (e *footnoteExtender) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(east.KindFootnoteList, e.renderFootnoteList)
+}
+
+func (e *footnoteExtender) renderFootnoteList(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString(e.open)
+	} else {
+		_, _ = w.WriteString(e.close)
+	}
+	return gast.WalkContinue, nil
+}
+
+// markFootnoteItems adds the footnote-item class themes expect on each note
+func markFootnoteItems(doc gast.Node) {
+	_ = gast.Walk(doc, func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if entering && n.Kind() == east.KindFootnote {
+			n.SetAttributeString("class", []byte("footnote-item"))
+		}
+		return gast.WalkContinue, nil
+	})
+}
@@ -0,0 +1,33 @@
+package content
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+var (
+	spaceRe        = regexp.MustCompile(`\s+`)
+	spaceBetweenRe = regexp.MustCompile(`>\s+<`)
+)
+
+// HTMLHash returns the hex SHA-256 of html with insignificant whitespace
+// removed, so that the same content rendered by specter and stored by Ghost
+// compares equal even if it was reformatted
+func HTMLHash(html string) string {
+	html = spaceBetweenRe.ReplaceAllString(html, "><")
+	html = strings.TrimSpace(spaceRe.ReplaceAllString(html, " "))
+	sum := sha256.Sum256([]byte(html))
+	return hex.EncodeToString(sum[:])
+}
+
+// ContentHash returns the hex SHA-256 of the content of html, as markdown
+// with whitespace collapsed. Unlike HTMLHash it ignores how the HTML is
+// serialized, e.g. entities, attribute order and self-closing tags, which
+// Ghost changes when it stores a post.
+func ContentHash(html string) string {
+	md, _ := HTMLToMarkdown(html)
+	sum := sha256.Sum256([]byte(strings.TrimSpace(spaceRe.ReplaceAllString(md, " "))))
+	return hex.EncodeToString(sum[:])
+}
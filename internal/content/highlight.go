@@ -0,0 +1,50 @@
+package content
+
+import (
+	"fmt"
+	"html"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/util"
+)
+
+// defaultHighlightTheme is the chroma style used when none is set
+const defaultHighlightTheme = "github"
+
+// highlighter highlights fenced code blocks that name a language when the
+// HTML is rendered, with inline styles, so posts need no stylesheet or
+// script. Ghost turns a plain <pre><code> into a code card and drops the
+// markup, so highlighted blocks are wrapped in HTML cards.
+func highlighter(theme string, warnings *[]string) goldmark.Extender {
+	if theme == "" || theme == "default" {
+		theme = defaultHighlightTheme
+	}
+	if _, ok := styles.Registry[theme]; !ok {
+		*warnings = append(*warnings, fmt.Sprintf("unknown highlight_theme %q, using %s; chroma's themes include monokai, dracula, github-dark and solarized-light", theme, defaultHighlightTheme))
+		theme = defaultHighlightTheme
+	}
+	return highlighting.NewHighlighting(
+		highlighting.WithStyle(theme),
+		highlighting.WithFormatOptions(chromahtml.WithClasses(false)),
+		highlighting.WithWrapperRenderer(func(w util.BufWriter, c highlighting.CodeBlockContext, entering bool) {
+			switch {
+			case c.Highlighted() && entering:
+				_, _ = w.WriteString("<!--kg-card-begin: html-->\n")
+			case c.Highlighted():
+				_, _ = w.WriteString("<!--kg-card-end: html-->\n")
+			case entering:
+				// A language chroma doesn't know, rendered as goldmark would
+				_, _ = w.WriteString("<pre><code")
+				if lang, ok := c.Language(); ok {
+					_, _ = w.WriteString(` class="language-` + html.EscapeString(string(lang)) + `"`)
+				}
+				_ = w.WriteByte('>')
+			default:
+				_, _ = w.WriteString("</code></pre>\n")
+			}
+		}),
+	)
+}
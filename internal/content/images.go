@@ -0,0 +1,32 @@
+package content
+
+import (
+	"fmt"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// ImageResolver maps an image destination as written in markdown to the URL
+// to use instead, e.g. after uploading a local file. It returns "" to leave
+// the destination unchanged.
+type ImageResolver func(dest string) (string, error)
+
+// rewriteImages replaces the destination of every image in doc with the one
+// returned by resolve
+func rewriteImages(doc ast.Node, resolve ImageResolver) error {
+	return ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		img, ok := n.(*ast.Image)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		dest := string(img.Destination)
+		resolved, err := resolve(dest)
+		if err != nil {
+			return ast.WalkStop, fmt.Errorf("image %s: %w", dest, err)
+		}
+		if resolved != "" {
+			img.Destination = []byte(resolved)
+		}
+		return ast.WalkContinue, nil
+	})
+}
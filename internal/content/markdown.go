@@ -0,0 +1,410 @@
+package content
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// HTMLToMarkdown converts a post's HTML back to markdown, e.g. for editing.
+// Cards that :::card directives can write become directives again. Other
+// markup that markdown has no syntax for is kept as raw HTML; the second
+// result reports whether there is any, since it only survives the round
+// trip with raw_html enabled. HTML that can't be parsed is returned as a
+// single raw block.
+func HTMLToMarkdown(src string) (string, bool) {
+	root, err := parseHTML(src)
+	if err != nil {
+		return strings.TrimSpace(src) + "\n", true
+	}
+	c := &mdConverter{src: src}
+	return c.blocks(root.children, "\n\n") + "\n", c.raw
+}
+
+// htmlNode is an element or, if tag is "", a text node
+type htmlNode struct {
+	tag      string
+	attrs    map[string]string
+	text     string
+	children []*htmlNode
+	// start and end are the element's byte offsets in the source, to copy
+	// it verbatim
+	start, end int
+}
+
+// parseHTML parses HTML with encoding/xml's lenient mode, which closes void
+// elements and missing end tags and knows HTML's entities
+func parseHTML(src string) (*htmlNode, error) {
+	const open = "<root>"
+	d := xml.NewDecoder(strings.NewReader(open + src + "</root>"))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+
+	var root *htmlNode
+	var stack []*htmlNode
+	for {
+		offset := int(d.InputOffset()) - len(open)
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &htmlNode{tag: strings.ToLower(t.Name.Local), attrs: map[string]string{}, start: offset}
+			for _, a := range t.Attr {
+				n.attrs[strings.ToLower(a.Name.Local)] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("unexpected </%s>", t.Name.Local)
+			}
+			n := stack[len(stack)-1]
+			n.end = int(d.InputOffset()) - len(open)
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				root = n
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, &htmlNode{text: string(t)})
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no content")
+	}
+	return root, nil
+}
+
+// inlineTags are the elements that are part of a paragraph's text
+var inlineTags = map[string]bool{
+	"a": true, "abbr": true, "b": true, "br": true, "cite": true, "code": true,
+	"del": true, "em": true, "i": true, "img": true, "kbd": true, "mark": true,
+	"q": true, "s": true, "samp": true, "small": true, "span": true, "strike": true,
+	"strong": true, "sub": true, "sup": true, "time": true, "u": true,
+}
+
+type mdConverter struct {
+	src string
+	raw bool
+}
+
+// blocks converts a list of nodes to markdown blocks separated by sep,
+// collecting runs of text and inline elements into paragraphs
+func (c *mdConverter) blocks(nodes []*htmlNode, sep string) string {
+	var out []string
+	var run []*htmlNode
+	flush := func() {
+		if text := c.paragraph(run); text != "" {
+			out = append(out, text)
+		}
+		run = nil
+	}
+	for _, n := range nodes {
+		if n.tag == "" || inlineTags[n.tag] {
+			run = append(run, n)
+			continue
+		}
+		flush()
+		if b := c.block(n); b != "" {
+			out = append(out, b)
+		}
+	}
+	flush()
+	return strings.Join(out, sep)
+}
+
+func (c *mdConverter) block(n *htmlNode) string {
+	switch n.tag {
+	case "p":
+		return c.paragraph(n.children)
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := int(n.tag[1] - '0')
+		text := strings.ReplaceAll(c.text(n.children), "\\\n", " ")
+		return strings.Repeat("#", level) + " " + text
+	case "hr":
+		return "---"
+	case "blockquote":
+		return prefixLines(c.blocks(n.children, "\n\n"), "> ")
+	case "ul", "ol":
+		return c.list(n)
+	case "pre":
+		return c.codeBlock(n)
+	case "figure", "div":
+		if md, ok := c.card(n); ok {
+			return md
+		}
+		if img := figureImage(n); n.tag == "figure" && img != nil {
+			return c.inline(img)
+		}
+	case "br":
+		return ""
+	}
+	c.raw = true
+	return strings.TrimSpace(c.src[n.start:n.end])
+}
+
+// paragraph converts inline nodes to a paragraph
+func (c *mdConverter) paragraph(nodes []*htmlNode) string {
+	return escapeLineStart(c.text(nodes))
+}
+
+// text converts inline nodes to text, collapsing whitespace as a browser
+// would
+func (c *mdConverter) text(nodes []*htmlNode) string {
+	text := strings.TrimSpace(c.inlines(nodes))
+	// Line breaks at either end show nothing, and markdown can't express
+	// them
+	text = strings.Trim(text, hardBreak+" ")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(multiSpace.ReplaceAllString(line, " "))
+	}
+	return hardBreakSpace.ReplaceAllString(strings.Join(lines, "\n"), "\\\n")
+}
+
+// hardBreak stands in for <br> until a paragraph's ends are trimmed
+const hardBreak = "\x00"
+
+var (
+	multiSpace = regexp.MustCompile(`[ \t]{2,}`)
+	htmlSpace  = regexp.MustCompile(`[ \t\r\n\f]+`)
+	// hardBreakSpace matches a <br> with the spaces around it, which
+	// markdown would keep at the ends of the lines
+	hardBreakSpace = regexp.MustCompile(" *" + hardBreak + " *")
+)
+
+func (c *mdConverter) inline(n *htmlNode) string {
+	switch n.tag {
+	case "":
+		return escapeMarkdown(htmlSpace.ReplaceAllString(n.text, " "))
+	case "strong", "b":
+		return c.emphasis(n, "**")
+	case "em", "i":
+		return c.emphasis(n, "*")
+	case "code":
+		return codeSpan(textContent(n))
+	case "br":
+		return hardBreak
+	case "a":
+		href, ok := n.attrs["href"]
+		if !ok {
+			break
+		}
+		return "[" + c.inlines(n.children) + "](" + linkDestination(href, n.attrs["title"]) + ")"
+	case "img":
+		return "![" + escapeMarkdown(n.attrs["alt"]) + "](" + linkDestination(n.attrs["src"], n.attrs["title"]) + ")"
+	}
+	c.raw = true
+	return c.src[n.start:n.end]
+}
+
+func (c *mdConverter) inlines(nodes []*htmlNode) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		b.WriteString(c.inline(n))
+	}
+	return b.String()
+}
+
+// emphasis wraps an element's text in marker, keeping surrounding spaces
+// outside, where markdown needs them
+func (c *mdConverter) emphasis(n *htmlNode, marker string) string {
+	text := c.inlines(n.children)
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	lead := text[:strings.Index(text, trimmed)]
+	trail := text[len(lead)+len(trimmed):]
+	return lead + marker + trimmed + marker + trail
+}
+
+func (c *mdConverter) list(n *htmlNode) string {
+	start := 1
+	if s, err := strconv.Atoi(n.attrs["start"]); err == nil {
+		start = s
+	}
+	var items []*htmlNode
+	loose := false
+	for _, child := range n.children {
+		if child.tag != "li" {
+			continue
+		}
+		items = append(items, child)
+		for _, gc := range child.children {
+			loose = loose || gc.tag == "p"
+		}
+	}
+
+	sep := "\n"
+	if loose {
+		sep = "\n\n"
+	}
+	var out []string
+	for i, li := range items {
+		marker := "- "
+		if n.tag == "ol" {
+			marker = strconv.Itoa(start+i) + ". "
+		}
+		body := c.blocks(li.children, sep)
+		out = append(out, marker+indentLines(body, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(out, sep)
+}
+
+func (c *mdConverter) codeBlock(n *htmlNode) string {
+	code := n
+	for _, child := range n.children {
+		if child.tag == "code" {
+			code = child
+			break
+		}
+	}
+	lang := ""
+	for _, class := range strings.Fields(code.attrs["class"]) {
+		if l, ok := strings.CutPrefix(class, "language-"); ok {
+			lang = l
+		}
+	}
+	text := strings.TrimSuffix(textContent(code), "\n")
+	fence := strings.Repeat("`", max(3, longestRun(text, '`')+1))
+	return fence + lang + "\n" + text + "\n" + fence
+}
+
+// figureImage returns the image of a figure that holds nothing else, which
+// markdown can express without losing anything
+func figureImage(n *htmlNode) *htmlNode {
+	var img *htmlNode
+	for _, child := range n.children {
+		switch {
+		case child.tag == "" && strings.TrimSpace(child.text) == "":
+		case child.tag == "img" && img == nil:
+			img = child
+		default:
+			return nil
+		}
+	}
+	return img
+}
+
+// textContent returns the text of a node and its descendants
+func textContent(n *htmlNode) string {
+	if n.tag == "" {
+		return n.text
+	}
+	var b strings.Builder
+	for _, child := range n.children {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+func codeSpan(text string) string {
+	fence := strings.Repeat("`", longestRun(text, '`')+1)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+	return fence + text + fence
+}
+
+func linkDestination(url, title string) string {
+	if strings.ContainsAny(url, " ()<>") {
+		url = "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(url) + ">"
+	}
+	if title != "" {
+		url += " " + strconv.Quote(title)
+	}
+	return url
+}
+
+// escapeMarkdown escapes the characters in s that markdown would read as
+// syntax. Underscores inside words are left alone, as they can't start
+// emphasis, and so are ampersands that don't start an entity.
+func escapeMarkdown(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		switch r {
+		case '\\', '`', '*', '[', ']', '<':
+			b.WriteRune('\\')
+		case '_':
+			inWord := i > 0 && i < len(runes)-1 && isWordRune(runes[i-1]) && isWordRune(runes[i+1])
+			if !inWord {
+				b.WriteRune('\\')
+			}
+		case '&':
+			if entityRef.MatchString(string(runes[i:])) {
+				b.WriteRune('\\')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+var entityRef = regexp.MustCompile(`^&#?[0-9A-Za-z]+;`)
+
+var listStart = regexp.MustCompile(`^(\d+)([.)])`)
+
+// escapeLineStart escapes a paragraph's first character if it would make
+// the paragraph a heading, quote or list
+func escapeLineStart(s string) string {
+	if s == "" {
+		return s
+	}
+	if strings.ContainsRune("#>+-", rune(s[0])) {
+		return "\\" + s
+	}
+	return listStart.ReplaceAllString(s, `$1\$2`)
+}
+
+func prefixLines(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(prefix+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// indentLines indents all lines but the first, leaving blank lines blank
+func indentLines(s, indent string) string {
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = indent + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func longestRun(s string, r rune) int {
+	longest, run := 0, 0
+	for _, c := range s {
+		if c == r {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
@@ -9,21 +9,120 @@ import (
 	"strings"
 
 	"github.com/yuin/goldmark"
-	"gopkg.in/yaml.v3"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
 )
 
 // Frontmatter holds post/page metadata from markdown frontmatter
 type Frontmatter struct {
-	Title       string   `yaml:"title"`
-	Slug        string   `yaml:"slug"`
-	Tags        []string `yaml:"tags"`
-	Featured    bool     `yaml:"featured"`
-	Status      string   `yaml:"status"`
-	Excerpt     string   `yaml:"excerpt"`
-	MetaTitle   string   `yaml:"meta_title"`
-	MetaDesc    string   `yaml:"meta_description"`
-	FeatureImg  string   `yaml:"feature_image"`
-	PublishedAt string   `yaml:"published_at"`
+	Title          string   `yaml:"title"`
+	Slug           string   `yaml:"slug"`
+	Tags           []string `yaml:"tags"`
+	Featured       bool     `yaml:"featured"`
+	Status         string   `yaml:"status"`
+	Excerpt        string   `yaml:"excerpt"`
+	MetaTitle      string   `yaml:"meta_title"`
+	MetaDesc       string   `yaml:"meta_description"`
+	FeatureImg     string   `yaml:"feature_image"`
+	Visibility     string   `yaml:"visibility"`
+	Tiers          []string `yaml:"tiers"`
+	Authors        []string `yaml:"authors"`
+	PublishedAt    string   `yaml:"published_at"`
+	CanonicalURL   string   `yaml:"canonical_url"`
+	OGTitle        string   `yaml:"og_title"`
+	OGDesc         string   `yaml:"og_description"`
+	OGImage        string   `yaml:"og_image"`
+	TwitterTitle   string   `yaml:"twitter_title"`
+	TwitterDesc    string   `yaml:"twitter_description"`
+	TwitterImage   string   `yaml:"twitter_image"`
+	CustomTemplate string   `yaml:"custom_template"`
+	CodeHead       string   `yaml:"codeinjection_head"`
+	CodeFoot       string   `yaml:"codeinjection_foot"`
+	Newsletter     string   `yaml:"newsletter"`
+	EmailSegment   string   `yaml:"email_segment"`
+	TOC            bool     `yaml:"toc"`
+	HeadingIDs     *bool    `yaml:"heading_ids"`
+	Footnotes      *bool    `yaml:"footnotes"`
+	Typographer    *bool    `yaml:"typographer"`
+	RawHTML        *bool    `yaml:"raw_html"`
+	WikiLinks      *bool    `yaml:"wiki_links"`
+	Highlight      *bool    `yaml:"highlight"`
+	Embeds         *bool    `yaml:"embeds"`
+}
+
+// Options controls how markdown is rendered to HTML. Profiles set the
+// defaults; frontmatter keys of the same name override them per file.
+type Options struct {
+	HeadingIDs     bool   `yaml:"heading_ids,omitempty"`
+	Footnotes      bool   `yaml:"footnotes,omitempty"`
+	Typographer    bool   `yaml:"typographer,omitempty"`
+	RawHTML        bool   `yaml:"raw_html,omitempty"`
+	WikiLinks      bool   `yaml:"wiki_links,omitempty"`
+	Highlight      bool   `yaml:"highlight,omitempty"`
+	HighlightTheme string `yaml:"highlight_theme,omitempty"`
+	Embeds         bool   `yaml:"embeds,omitempty"`
+	FootnotesOpen  string `yaml:"footnotes_open,omitempty"`
+	FootnotesClose string `yaml:"footnotes_close,omitempty"`
+
+	// ResolveWikiLink looks up the URL for [[wiki links]]. If nil, links
+	// point to the slugified title.
+	ResolveWikiLink WikiLinkResolver `yaml:"-"`
+	// ResolveImage, if set, can replace image destinations, e.g. to upload
+	// local files
+	ResolveImage ImageResolver `yaml:"-"`
+	// ResolveBookmark, if set, fetches the title, description and images
+	// of :::bookmark cards
+	ResolveBookmark BookmarkResolver `yaml:"-"`
+	// ResolveEmbed, if set, fetches the players of :::embed cards and, with
+	// Embeds, of links on their own line
+	ResolveEmbed EmbedResolver `yaml:"-"`
+	// Vars, if not nil, makes the file a Go template that is expanded with
+	// these variables before parsing
+	Vars map[string]string `yaml:"-"`
+}
+
+// Default footnote wrapper, matching the markup Ghost's own editor produces
+// so that theme styles for .footnotes apply unchanged
+const (
+	defaultFootnotesOpen  = "<hr class=\"footnotes-sep\">\n<section class=\"footnotes\">\n<ol class=\"footnotes-list\">\n"
+	defaultFootnotesClose = "</ol>\n</section>\n"
+)
+
+// withFrontmatter returns a copy of o with per-file overrides applied
+func (o Options) withFrontmatter(fm Frontmatter) Options {
+	if fm.HeadingIDs != nil {
+		o.HeadingIDs = *fm.HeadingIDs
+	}
+	if fm.Footnotes != nil {
+		o.Footnotes = *fm.Footnotes
+	}
+	if fm.Typographer != nil {
+		o.Typographer = *fm.Typographer
+	}
+	if fm.RawHTML != nil {
+		o.RawHTML = *fm.RawHTML
+	}
+	if fm.WikiLinks != nil {
+		o.WikiLinks = *fm.WikiLinks
+	}
+	if fm.Highlight != nil {
+		o.Highlight = *fm.Highlight
+	}
+	if fm.Embeds != nil {
+		o.Embeds = *fm.Embeds
+	}
+	if fm.TOC {
+		o.HeadingIDs = true
+	}
+	if o.FootnotesOpen == "" && o.FootnotesClose == "" {
+		o.FootnotesOpen = defaultFootnotesOpen
+		o.FootnotesClose = defaultFootnotesClose
+	}
+	return o
 }
 
 // ParsedContent contains parsed frontmatter and HTML content
@@ -31,12 +130,14 @@ type ParsedContent struct {
 	Frontmatter Frontmatter
 	HTML        string
 	Markdown    string
+	// Warnings are non-fatal problems found while rendering
+	Warnings []string
 }
 
 // ParseFile reads a markdown file with frontmatter
-func ParseFile(path string) (*ParsedContent, error) {
+func ParseFile(path string, opts Options) (*ParsedContent, error) {
 	if path == "-" {
-		return ParseReader(os.Stdin)
+		return ParseReader(os.Stdin, opts)
 	}
 
 	f, err := os.Open(path)
@@ -45,28 +146,39 @@ func ParseFile(path string) (*ParsedContent, error) {
 	}
 	defer f.Close()
 
-	return ParseReader(f)
+	return ParseReader(f, opts)
 }
 
 // ParseReader parses markdown with frontmatter from a reader
-func ParseReader(r io.Reader) (*ParsedContent, error) {
+func ParseReader(r io.Reader, opts Options) (*ParsedContent, error) {
 	data, err := io.ReadAll(r)
 	if err != nil {
 		return nil, fmt.Errorf("reading input: %w", err)
 	}
 
-	return Parse(data)
+	return Parse(data, opts)
 }
 
 // Parse parses markdown content with YAML frontmatter
-func Parse(data []byte) (*ParsedContent, error) {
+func Parse(data []byte, opts Options) (*ParsedContent, error) {
+	if opts.Vars != nil {
+		expanded, err := expandVars(data, opts.Vars)
+		if err != nil {
+			return nil, err
+		}
+		data = expanded
+	}
+
 	content := &ParsedContent{}
 
 	scanner := bufio.NewScanner(bytes.NewReader(data))
 
 	// Check for frontmatter
 	if scanner.Scan() && strings.TrimSpace(scanner.Text()) == "---" {
+		// Start with a newline for the opening "---" so that line numbers
+		// in errors match the file
 		var frontmatterBuf bytes.Buffer
+		frontmatterBuf.WriteString("\n")
 		for scanner.Scan() {
 			line := scanner.Text()
 			if strings.TrimSpace(line) == "---" {
@@ -76,7 +188,7 @@ func Parse(data []byte) (*ParsedContent, error) {
 			frontmatterBuf.WriteString("\n")
 		}
 
-		if err := yaml.Unmarshal(frontmatterBuf.Bytes(), &content.Frontmatter); err != nil {
+		if err := decodeFrontmatter(frontmatterBuf.Bytes(), &content.Frontmatter); err != nil {
 			return nil, fmt.Errorf("parsing frontmatter: %w", err)
 		}
 	}
@@ -95,12 +207,89 @@ func Parse(data []byte) (*ParsedContent, error) {
 	content.Markdown = markdownBuf.String()
 
 	// Convert markdown to HTML
+	source := markdownBuf.Bytes()
+	opts = opts.withFrontmatter(content.Frontmatter)
+	md := newMarkdown(opts, &content.Warnings)
+	doc := md.Parser().Parse(text.NewReader(source))
+	if opts.Footnotes {
+		markFootnoteItems(doc)
+	}
+	if opts.ResolveImage != nil {
+		if err := rewriteImages(doc, opts.ResolveImage); err != nil {
+			return nil, err
+		}
+	}
+	if opts.ResolveBookmark != nil {
+		resolveBookmarks(doc, opts.ResolveBookmark, &content.Warnings)
+	}
+	if opts.ResolveEmbed != nil {
+		resolveEmbeds(doc, source, opts.ResolveEmbed, opts.Embeds, &content.Warnings)
+	}
+	if !opts.RawHTML {
+		if n := countRawHTML(doc); n > 0 {
+			content.Warnings = append(content.Warnings, fmt.Sprintf(
+				"%d raw HTML fragment(s) were removed; set 'raw_html: true' in frontmatter or the profile's markdown config to keep them", n))
+		}
+	}
+
 	var htmlBuf bytes.Buffer
-	md := goldmark.New()
-	if err := md.Convert(markdownBuf.Bytes(), &htmlBuf); err != nil {
+	if content.Frontmatter.TOC {
+		htmlBuf.WriteString(renderTOC(collectHeadings(doc, source)))
+	}
+	if err := md.Renderer().Render(&htmlBuf, source, doc); err != nil {
 		return nil, fmt.Errorf("converting markdown: %w", err)
 	}
 	content.HTML = htmlBuf.String()
 
 	return content, nil
 }
+
+// newMarkdown builds a goldmark instance configured with the given options.
+// Problems found while parsing are appended to warnings.
+func newMarkdown(opts Options, warnings *[]string) goldmark.Markdown {
+	var parserOpts []parser.Option
+	if opts.HeadingIDs {
+		parserOpts = append(parserOpts, parser.WithAutoHeadingID())
+	}
+
+	// Tables, strikethrough, task lists and autolinks, as on GitHub, and
+	// :::card directives for Ghost's cards
+	extensions := []goldmark.Extender{extension.GFM, &cardExtender{warnings: warnings}}
+	if opts.Footnotes {
+		extensions = append(extensions, footnotes(opts))
+	}
+	if opts.WikiLinks {
+		extensions = append(extensions, &wikiLinkExtender{resolve: opts.ResolveWikiLink, warnings: warnings})
+	}
+	if opts.Typographer {
+		// Smart quotes, en/em dashes and ellipses
+		extensions = append(extensions, extension.Typographer)
+	}
+	if opts.Highlight {
+		extensions = append(extensions, highlighter(opts.HighlightTheme, warnings))
+	}
+
+	var rendererOpts []renderer.Option
+	if opts.RawHTML {
+		rendererOpts = append(rendererOpts, html.WithUnsafe())
+	}
+
+	return goldmark.New(
+		goldmark.WithParserOptions(parserOpts...),
+		goldmark.WithRendererOptions(rendererOpts...),
+		goldmark.WithExtensions(extensions...),
+	)
+}
+
+// countRawHTML returns the number of raw HTML blocks and inline tags,
+// which goldmark drops unless rendering in unsafe mode
+func countRawHTML(doc ast.Node) int {
+	count := 0
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && (n.Kind() == ast.KindHTMLBlock || n.Kind() == ast.KindRawHTML) {
+			count++
+		}
+		return ast.WalkContinue, nil
+	})
+	return count
+}
@@ -0,0 +1,115 @@
+package content
+
+import (
+	"reflect"
+	"strings"
+)
+
+// frontmatterDescriptions document each frontmatter key in the schema
+var frontmatterDescriptions = map[string]string{
+	"title":               "Post title",
+	"slug":                "URL slug",
+	"tags":                "Tag names; missing tags are created",
+	"featured":            "Feature the post",
+	"status":              "Publication status",
+	"excerpt":             "Custom excerpt",
+	"meta_title":          "SEO title",
+	"meta_description":    "SEO description",
+	"feature_image":       "Feature image URL or local path",
+	"visibility":          "Who can read the post",
+	"tiers":               "Tier slugs or IDs that can read the post, with visibility: tiers",
+	"authors":             "Author emails, slugs or IDs",
+	"published_at":        "Publication date, e.g. for scheduled posts (RFC 3339)",
+	"canonical_url":       "URL of the original, if the post is republished",
+	"og_title":            "Title when shared on Facebook and others",
+	"og_description":      "Description when shared on Facebook and others",
+	"og_image":            "Image URL or local path when shared on Facebook and others",
+	"twitter_title":       "Title when shared on X/Twitter",
+	"twitter_description": "Description when shared on X/Twitter",
+	"twitter_image":       "Image URL or local path when shared on X/Twitter",
+	"custom_template":     "Theme template to render the post with, e.g. custom-wide",
+	"codeinjection_head":  "HTML added to the post's <head>",
+	"codeinjection_foot":  "HTML added before the post's </body>",
+	"newsletter":          "Newsletter slug to email the post through when published",
+	"email_segment":       "Members to email, e.g. status:-free (default: all)",
+	"toc":                 "Insert a table of contents with heading anchors",
+	"heading_ids":         "Add id attributes to headings",
+	"footnotes":           "Enable [^1] footnotes",
+	"typographer":         "Smart quotes, dashes and ellipses",
+	"highlight":           "Highlight code blocks that name a language",
+	"embeds":              "Embed links on their own line, e.g. to YouTube, as embed cards",
+	"raw_html":            "Keep embedded HTML",
+	"wiki_links":          "Resolve [[Other Post]] links and ![[image.png]] embeds",
+}
+
+// FrontmatterKey describes a frontmatter key that Parse accepts
+type FrontmatterKey struct {
+	Name string `json:"name"`
+	// Type is the JSON Schema type: string, boolean or array (of strings)
+	Type        string   `json:"type"`
+	Values      []string `json:"values,omitempty"`
+	Description string   `json:"description"`
+}
+
+// FrontmatterKeys returns the frontmatter keys in the order of the
+// Frontmatter struct
+func FrontmatterKeys() []FrontmatterKey {
+	var keys []FrontmatterKey
+	t := reflect.TypeOf(Frontmatter{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		key := FrontmatterKey{
+			Name:        name,
+			Type:        "string",
+			Values:      frontmatterEnums[name],
+			Description: frontmatterDescriptions[name],
+		}
+		typ := f.Type
+		if typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		switch typ.Kind() {
+		case reflect.Bool:
+			key.Type = "boolean"
+		case reflect.Slice:
+			key.Type = "array"
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// FrontmatterSchema returns a JSON Schema describing the frontmatter that
+// Parse accepts, for editor completion and validation
+func FrontmatterSchema() map[string]interface{} {
+	properties := map[string]interface{}{}
+	for _, key := range FrontmatterKeys() {
+		prop := map[string]interface{}{"type": key.Type}
+		if key.Type == "array" {
+			prop["items"] = map[string]interface{}{"type": "string"}
+		}
+		if key.Values != nil {
+			prop["enum"] = key.Values
+		}
+		if key.Name == "published_at" {
+			prop["format"] = "date-time"
+		}
+		if key.Description != "" {
+			prop["description"] = key.Description
+		}
+		properties[key.Name] = prop
+	}
+
+	return map[string]interface{}{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"title":                "specter post frontmatter",
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
@@ -0,0 +1,88 @@
+package content
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// heading is a single table of contents entry
+type heading struct {
+	Level int
+	ID    string
+	Text  string
+}
+
+// collectHeadings walks the document and returns all headings that have an ID
+func collectHeadings(doc ast.Node, source []byte) []heading {
+	var headings []heading
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		h, ok := n.(*ast.Heading)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		id, ok := h.AttributeString("id")
+		if !ok {
+			return ast.WalkSkipChildren, nil
+		}
+		idBytes, _ := id.([]byte)
+		headings = append(headings, heading{
+			Level: h.Level,
+			ID:    string(idBytes),
+			Text:  string(h.Text(source)),
+		})
+		return ast.WalkSkipChildren, nil
+	})
+	return headings
+}
+
+// renderTOC renders headings as a nested list of anchor links
+func renderTOC(headings []heading) string {
+	if len(headings) == 0 {
+		return ""
+	}
+
+	base := headings[0].Level
+	for _, h := range headings {
+		if h.Level < base {
+			base = h.Level
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("<nav class=\"toc\">\n<ul>\n")
+	depth := 0
+	for i, h := range headings {
+		// Never nest more than one level deeper than the previous entry
+		level := min(h.Level-base, depth+1)
+		if i > 0 {
+			switch {
+			case level > depth:
+				b.WriteString("\n<ul>\n")
+				depth++
+			case level < depth:
+				for ; depth > level; depth-- {
+					b.WriteString("</li>\n</ul>\n")
+				}
+				b.WriteString("</li>\n")
+			default:
+				b.WriteString("</li>\n")
+			}
+		} else {
+			for ; depth < level; depth++ {
+				b.WriteString("<li>\n<ul>\n")
+			}
+		}
+		fmt.Fprintf(&b, "<li><a href=\"#%s\">%s</a>", html.EscapeString(h.ID), html.EscapeString(h.Text))
+	}
+	for ; depth > 0; depth-- {
+		b.WriteString("</li>\n</ul>\n")
+	}
+	b.WriteString("</li>\n</ul>\n</nav>\n")
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Allowed values for enum-like frontmatter keys
+var frontmatterEnums = map[string][]string{
+	"status":     {"draft", "published", "scheduled"},
+	"visibility": {"public", "members", "paid", "tiers"},
+}
+
+// FrontmatterError lists every problem found in a file's frontmatter
+type FrontmatterError struct {
+	Problems []string
+}
+
+func (e *FrontmatterError) Error() string {
+	return "invalid frontmatter:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// decodeFrontmatter strictly decodes frontmatter YAML, reporting unknown
+// keys, wrong types and invalid enum values. Line numbers in data must
+// already match the source file.
+func decodeFrontmatter(data []byte, fm *Frontmatter) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	if len(root.Content) == 0 {
+		return nil
+	}
+
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return &FrontmatterError{Problems: []string{
+			fmt.Sprintf("line %d: frontmatter must be a set of key: value pairs", doc.Line),
+		}}
+	}
+
+	var problems []string
+	known := frontmatterKeys()
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], doc.Content[i+1]
+
+		if !known[key.Value] {
+			msg := fmt.Sprintf("line %d: unknown key %q", key.Line, key.Value)
+			if s := suggestKey(key.Value, known); s != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", s)
+			}
+			problems = append(problems, msg)
+			continue
+		}
+
+		if allowed, ok := frontmatterEnums[key.Value]; ok && value.Kind == yaml.ScalarNode {
+			if !contains(allowed, value.Value) {
+				problems = append(problems, fmt.Sprintf("line %d: invalid %s %q (expected one of: %s)",
+					value.Line, key.Value, value.Value, strings.Join(allowed, ", ")))
+			}
+		}
+	}
+
+	if err := doc.Decode(fm); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return err
+		}
+		problems = append(problems, typeErr.Errors...)
+	}
+
+	if len(problems) > 0 {
+		return &FrontmatterError{Problems: problems}
+	}
+	return nil
+}
+
+// frontmatterKeys returns the set of keys Frontmatter accepts
+func frontmatterKeys() map[string]bool {
+	keys := map[string]bool{}
+	t := reflect.TypeOf(Frontmatter{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// suggestKey returns the known key closest to an unknown one, if any is
+// close enough to be a likely typo
+func suggestKey(key string, known map[string]bool) string {
+	// Common aliases that aren't near-misses by spelling
+	aliases := map[string]string{
+		"state":       "status",
+		"description": "excerpt",
+		"image":       "feature_image",
+		"date":        "published_at",
+		"draft":       "status",
+		"author":      "authors",
+		"template":    "custom_template",
+	}
+	if s, ok := aliases[key]; ok {
+		return s
+	}
+
+	var candidates []string
+	for k := range known {
+		candidates = append(candidates, k)
+	}
+	sort.Strings(candidates)
+
+	best, bestDist := "", 3
+	for _, k := range candidates {
+		if d := editDistance(key, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
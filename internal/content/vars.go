@@ -0,0 +1,36 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateFuncs are available in templated content, e.g.
+// {{ now.Format "January 2, 2006" }}
+var templateFuncs = template.FuncMap{
+	"now":   time.Now,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// expandVars executes data as a Go template with vars, so that recurring
+// posts can be written once with {{ .issue }}-style placeholders. Unknown
+// variables are an error rather than silently left empty.
+func expandVars(data []byte, vars map[string]string) ([]byte, error) {
+	tmpl, err := template.New("content").
+		Option("missingkey=error").
+		Funcs(templateFuncs).
+		Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return nil, fmt.Errorf("expanding template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
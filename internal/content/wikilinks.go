@@ -0,0 +1,152 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// WikiLinkResolver maps a wiki-link target (a note title) to a URL. It
+// returns false if the target can't be found.
+type WikiLinkResolver func(target string) (string, bool)
+
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true,
+	".webp": true, ".svg": true, ".avif": true,
+}
+
+// wikiLinkExtender adds Obsidian-style [[Target|Label]] links and
+// ![[image.png]] embeds
+type wikiLinkExtender struct {
+	resolve WikiLinkResolver
+	// warnings collects targets that could not be resolved
+	warnings *[]string
+	// resolved caches lookups so repeated links resolve (and warn) once
+	resolved map[string]string
+}
+
+func (e *wikiLinkExtender) Extend(m goldmark.Markdown) {
+	// Ahead of the standard link parser (priority 200)
+	m.Parser().AddOptions(parser.WithInlineParsers(
+		util.Prioritized(&wikiLinkParser{e}, 199),
+	))
+}
+
+type wikiLinkParser struct {
+	*wikiLinkExtender
+}
+
+func (p *wikiLinkParser) Trigger() []byte {
+	return []byte{'!', '['}
+}
+
+func (p *wikiLinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+
+	embed := false
+	start := 2
+	if bytes.HasPrefix(line, []byte("![[")) {
+		embed = true
+		start = 3
+	} else if !bytes.HasPrefix(line, []byte("[[")) {
+		return nil
+	}
+
+	end := bytes.Index(line[start:], []byte("]]"))
+	if end <= 0 {
+		return nil
+	}
+	inner := string(line[start : start+end])
+	if strings.ContainsAny(inner, "[]\n") {
+		return nil
+	}
+	block.Advance(start + end + 2)
+
+	target, label, _ := strings.Cut(inner, "|")
+	target = strings.TrimSpace(target)
+	label = strings.TrimSpace(label)
+
+	if embed && imageExtensions[strings.ToLower(path.Ext(target))] {
+		// Obsidian uses ![[img.png|300]] to set a width; that's not alt text
+		alt := label
+		if alt == "" || strings.IndexFunc(alt, func(r rune) bool { return !unicode.IsDigit(r) && r != 'x' }) < 0 {
+			alt = strings.TrimSuffix(path.Base(target), path.Ext(target))
+		}
+		link := ast.NewLink()
+		link.Destination = []byte(target)
+		img := ast.NewImage(link)
+		img.AppendChild(img, ast.NewString([]byte(alt)))
+		return img
+	}
+
+	page, heading, _ := strings.Cut(target, "#")
+	if label == "" {
+		label = page
+		if heading != "" {
+			label = strings.TrimSpace(heading)
+			if page != "" {
+				label = page + " > " + label
+			}
+		}
+	}
+
+	dest := ""
+	if page != "" {
+		dest = p.resolveTarget(strings.TrimSpace(page))
+	}
+	if heading != "" {
+		dest += "#" + Slugify(heading)
+	}
+
+	link := ast.NewLink()
+	link.Destination = []byte(dest)
+	link.AppendChild(link, ast.NewString([]byte(label)))
+	return link
+}
+
+func (p *wikiLinkParser) resolveTarget(target string) string {
+	if url, ok := p.resolved[target]; ok {
+		return url
+	}
+
+	url := "/" + Slugify(target) + "/"
+	if p.resolve != nil {
+		if u, ok := p.resolve(target); ok {
+			url = u
+		} else {
+			*p.warnings = append(*p.warnings, fmt.Sprintf("could not resolve wiki link [[%s]]; linking to %s", target, url))
+		}
+	}
+
+	if p.resolved == nil {
+		p.resolved = map[string]string{}
+	}
+	p.resolved[target] = url
+	return url
+}
+
+// Slugify converts a title into a Ghost-style slug: lowercase ASCII letters
+// and digits separated by single hyphens
+func Slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
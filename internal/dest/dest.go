@@ -0,0 +1,127 @@
+// Package dest writes exports to where they are kept: standard output, a
+// local file, or an object in S3 or Google Cloud Storage, named by a URL.
+package dest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Writer is an export being written. Nothing is left at the destination
+// until Close returns nil; Abort discards what was written instead.
+type Writer interface {
+	io.Writer
+	Close() error
+	Abort() error
+}
+
+// Provider opens writers for the destinations of a URL scheme
+type Provider interface {
+	Create(ctx context.Context, u *url.URL) (Writer, error)
+}
+
+var providers = map[string]Provider{
+	"file": fileProvider{},
+	"s3":   s3Provider{service: "s3"},
+	"gs":   s3Provider{service: "gs"},
+}
+
+// Register makes a provider handle the destinations of a URL scheme
+func Register(scheme string, p Provider) {
+	providers[scheme] = p
+}
+
+// Create opens a writer for dest: "-" for standard output, a file path or
+// file:// URL, or an s3://bucket/key or gs://bucket/key URL
+func Create(ctx context.Context, dest string) (Writer, error) {
+	if dest == "-" {
+		return stdout{}, nil
+	}
+	if !IsURL(dest) {
+		return createFile(dest)
+	}
+	u, err := url.Parse(dest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination: %w", err)
+	}
+	p, ok := providers[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("unsupported destination %s:// (use a path, file://, s3:// or gs://)", u.Scheme)
+	}
+	return p.Create(ctx, u)
+}
+
+// IsURL reports whether dest is a URL rather than a file path
+func IsURL(dest string) bool {
+	scheme, _, ok := strings.Cut(dest, "://")
+	return ok && scheme != "" && !strings.ContainsAny(scheme, `/\`)
+}
+
+// WriteTo writes an export to dest with write, aborting it if write fails
+func WriteTo(ctx context.Context, dest string, write func(io.Writer) error) error {
+	w, err := Create(ctx, dest)
+	if err != nil {
+		return err
+	}
+	if err := write(w); err != nil {
+		_ = w.Abort()
+		return err
+	}
+	return w.Close()
+}
+
+// stdout writes to standard output, which can't be taken back
+type stdout struct{}
+
+func (stdout) Write(p []byte) (int, error) { return os.Stdout.Write(p) }
+func (stdout) Close() error                { return nil }
+func (stdout) Abort() error                { return nil }
+
+type fileProvider struct{}
+
+func (fileProvider) Create(ctx context.Context, u *url.URL) (Writer, error) {
+	return createFile(u.Path)
+}
+
+// file writes to a temporary file next to the destination, which replaces
+// it on Close, so that a failed export doesn't overwrite the last good one
+type file struct {
+	*os.File
+	path string
+}
+
+func createFile(path string) (Writer, error) {
+	dir, name := ".", path
+	if i := strings.LastIndexAny(path, `/\`); i >= 0 {
+		dir, name = path[:i+1], path[i+1:]
+	}
+	f, err := os.CreateTemp(dir, "."+name+".*")
+	if err != nil {
+		return nil, err
+	}
+	return &file{File: f, path: path}, nil
+}
+
+func (f *file) Close() error {
+	if err := f.File.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), f.path); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+func (f *file) Abort() error {
+	f.File.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,336 @@
+package dest
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// partSize is how much of an object is held in memory before it is
+// uploaded as a part of a multipart upload. S3 needs parts of at least
+// 5 MiB, except for the last.
+const partSize = 8 << 20
+
+// s3Provider writes objects through the S3 API, to AWS for s3:// or to
+// Google Cloud Storage's S3-compatible XML API for gs://
+type s3Provider struct {
+	service string
+}
+
+// s3Config is where and as whom objects are written
+type s3Config struct {
+	endpoint  string
+	region    string
+	accessKey string
+	secretKey string
+	token     string
+	// virtualHost puts the bucket in the host name rather than the path
+	virtualHost bool
+}
+
+// loadS3Config reads the credentials and endpoint of a service from the
+// environment: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN,
+// AWS_REGION and AWS_ENDPOINT_URL for s3, e.g. for R2 or MinIO, and the
+// HMAC key in GS_ACCESS_KEY_ID and GS_SECRET_ACCESS_KEY for gs
+func loadS3Config(service string) (*s3Config, error) {
+	if service == "gs" {
+		c := &s3Config{
+			endpoint:  "https://storage.googleapis.com",
+			region:    "auto",
+			accessKey: os.Getenv("GS_ACCESS_KEY_ID"),
+			secretKey: os.Getenv("GS_SECRET_ACCESS_KEY"),
+		}
+		if c.accessKey == "" || c.secretKey == "" {
+			return nil, fmt.Errorf("gs:// needs an HMAC key in GS_ACCESS_KEY_ID and GS_SECRET_ACCESS_KEY")
+		}
+		return c, nil
+	}
+
+	c := &s3Config{
+		region:    firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+		accessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:     os.Getenv("AWS_SESSION_TOKEN"),
+		endpoint:  strings.TrimSuffix(firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"), "/"),
+	}
+	if c.accessKey == "" || c.secretKey == "" {
+		return nil, fmt.Errorf("s3:// needs credentials in AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	if c.region == "" {
+		c.region = "us-east-1"
+	}
+	if c.endpoint == "" {
+		c.endpoint = "https://s3." + c.region + ".amazonaws.com"
+		c.virtualHost = true
+	}
+	return c, nil
+}
+
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (p s3Provider) Create(ctx context.Context, u *url.URL) (Writer, error) {
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" || strings.HasSuffix(key, "/") {
+		return nil, fmt.Errorf("%s:// destination needs a bucket and an object name: %s", p.service, u.Redacted())
+	}
+	cfg, err := loadS3Config(p.service)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Object{ctx: ctx, cfg: cfg, bucket: u.Host, key: key}, nil
+}
+
+// s3Object is an object being written. Up to partSize bytes are buffered;
+// larger objects are uploaded in parts as they are written.
+type s3Object struct {
+	ctx      context.Context
+	cfg      *s3Config
+	bucket   string
+	key      string
+	buf      bytes.Buffer
+	uploadID string
+	parts    []s3Part
+}
+
+type s3Part struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+func (o *s3Object) Write(p []byte) (int, error) {
+	n, _ := o.buf.Write(p)
+	for o.buf.Len() >= partSize {
+		if err := o.uploadPart(o.buf.Next(partSize)); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (o *s3Object) uploadPart(data []byte) error {
+	if o.uploadID == "" {
+		var result struct {
+			UploadID string `xml:"UploadId"`
+		}
+		resp, err := o.do("POST", url.Values{"uploads": {""}}, nil)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return fmt.Errorf("starting upload: %w", err)
+		}
+		o.uploadID = result.UploadID
+	}
+
+	n := len(o.parts) + 1
+	resp, err := o.do("PUT", url.Values{"partNumber": {fmt.Sprint(n)}, "uploadId": {o.uploadID}}, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	o.parts = append(o.parts, s3Part{PartNumber: n, ETag: resp.Header.Get("ETag")})
+	return nil
+}
+
+func (o *s3Object) Close() error {
+	// Small objects are uploaded in one request
+	if o.uploadID == "" {
+		resp, err := o.do("PUT", nil, o.buf.Bytes())
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	if o.buf.Len() > 0 {
+		if err := o.uploadPart(o.buf.Bytes()); err != nil {
+			_ = o.Abort()
+			return err
+		}
+	}
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []s3Part `xml:"Part"`
+	}{Parts: o.parts})
+	if err != nil {
+		return err
+	}
+	resp, err := o.do("POST", url.Values{"uploadId": {o.uploadID}}, body)
+	if err != nil {
+		_ = o.Abort()
+		return err
+	}
+	defer resp.Body.Close()
+	// Completing can fail after the response has started
+	var result struct {
+		XMLName xml.Name
+		Message string `xml:"Message"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err == nil && result.XMLName.Local == "Error" {
+		return fmt.Errorf("completing upload: %s", result.Message)
+	}
+	return nil
+}
+
+func (o *s3Object) Abort() error {
+	o.buf.Reset()
+	if o.uploadID == "" {
+		return nil
+	}
+	resp, err := o.do("DELETE", url.Values{"uploadId": {o.uploadID}}, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// do sends a signed request for the object, returning an error for
+// responses other than 2xx
+func (o *s3Object) do(method string, query url.Values, body []byte) (*http.Response, error) {
+	u, err := url.Parse(o.cfg.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+	if o.cfg.virtualHost && !strings.Contains(o.bucket, ".") {
+		u.Host = o.bucket + "." + u.Host
+		u.Path = "/" + o.key
+	} else {
+		u.Path = path.Join("/", u.Path, o.bucket, o.key)
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(o.ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	// The object's type is given when it is created
+	if query == nil || query.Has("uploads") {
+		if t := mime.TypeByExtension(path.Ext(o.key)); t != "" {
+			req.Header.Set("Content-Type", t)
+		}
+	}
+	o.cfg.sign(req, body, time.Now().UTC())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		var result struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if xml.Unmarshal(data, &result) == nil && result.Code != "" {
+			return nil, fmt.Errorf("writing %s/%s: %s: %s", o.bucket, o.key, result.Code, result.Message)
+		}
+		return nil, fmt.Errorf("writing %s/%s: %s", o.bucket, o.key, resp.Status)
+	}
+	return resp, nil
+}
+
+// sign adds an AWS Signature Version 4 to req
+func (c *s3Config) sign(req *http.Request, body []byte, now time.Time) {
+	payload := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(payload[:])
+	amzDate := now.Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.token != "" {
+		req.Header.Set("X-Amz-Security-Token", c.token)
+	}
+
+	names := []string{"host"}
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") {
+			names = append(names, lower)
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := now.Format("20060102") + "/" + c.region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := []byte("AWS4" + c.secretKey)
+	for _, part := range []string{now.Format("20060102"), c.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery encodes query parameters sorted by name, as signatures
+// need them
+func canonicalQuery(query url.Values) string {
+	var params []string
+	for name, values := range query {
+		for _, v := range values {
+			params = append(params, uriEncode(name, true)+"="+uriEncode(v, true))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters, and "/"
+// unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
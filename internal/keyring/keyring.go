@@ -0,0 +1,35 @@
+// Package keyring stores secrets in the operating system's credential store:
+// the macOS Keychain, the Secret Service (GNOME Keyring, KWallet) through
+// secret-tool on Linux and BSD, and the Windows Credential Manager.
+package keyring
+
+import "errors"
+
+// Service is the name specter's secrets are stored under
+const Service = "specter"
+
+// ErrNotFound is returned by Get when no secret is stored for an account
+var ErrNotFound = errors.New("secret not found in keyring")
+
+// ErrUnsupported is returned on platforms without a supported store
+var ErrUnsupported = errors.New("no supported keyring on this platform")
+
+// Set stores secret for account, replacing any existing one
+func Set(account, secret string) error {
+	return set(account, secret)
+}
+
+// Get returns the secret stored for account
+func Get(account string) (string, error) {
+	return get(account)
+}
+
+// Delete removes the secret stored for account. It is not an error if
+// there is none.
+func Delete(account string) error {
+	err := del(account)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
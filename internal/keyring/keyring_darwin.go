@@ -0,0 +1,67 @@
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// The security tool exits with 44 if the item doesn't exist
+const errSecItemNotFound = 44
+
+func set(account, secret string) error {
+	// -U updates an existing item. The command is given to security's
+	// interactive mode on stdin, so the secret isn't on the command line
+	// where other processes can see it.
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(securityCommand("add-generic-password", "-U",
+		"-s", Service, "-a", account, "-l", Service+": "+account, "-w", secret) + "\n")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("storing secret in keychain: %s", strings.TrimSpace(string(out)))
+	}
+	// In interactive mode security exits with 0 even if the command failed,
+	// so check that the secret was stored
+	if stored, err := get(account); err != nil || stored != secret {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = "the secret wasn't saved"
+		}
+		return fmt.Errorf("storing secret in keychain: %s", msg)
+	}
+	return nil
+}
+
+// securityCommand quotes args into a line for security -i
+func securityCommand(args ...string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		a = strings.ReplaceAll(a, `\`, `\\`)
+		quoted[i] = `"` + strings.ReplaceAll(a, `"`, `\"`) + `"`
+	}
+	return strings.Join(quoted, " ")
+}
+
+func get(account string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", Service, "-a", account, "-w").Output()
+	if err != nil {
+		return "", keychainError("reading secret from keychain", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func del(account string) error {
+	if err := exec.Command("security", "delete-generic-password", "-s", Service, "-a", account).Run(); err != nil {
+		return keychainError("deleting secret from keychain", err)
+	}
+	return nil
+}
+
+func keychainError(action string, err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == errSecItemNotFound {
+		return ErrNotFound
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
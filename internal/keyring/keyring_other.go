@@ -0,0 +1,9 @@
+//go:build !darwin && !windows && !linux && !freebsd && !openbsd && !netbsd
+
+package keyring
+
+func set(account, secret string) error { return ErrUnsupported }
+
+func get(account string) (string, error) { return "", ErrUnsupported }
+
+func del(account string) error { return ErrUnsupported }
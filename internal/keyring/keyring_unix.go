@@ -0,0 +1,57 @@
+//go:build linux || freebsd || openbsd || netbsd
+
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// secret-tool is part of libsecret and talks to whichever Secret Service
+// is running, e.g. GNOME Keyring or KWallet
+
+func set(account, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", Service+": "+account,
+		"service", Service, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("storing secret with secret-tool: %s", secretToolError(err, out))
+	}
+	return nil
+}
+
+func get(account string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", Service, "account", account).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// lookup exits with 1 and no output if nothing matches
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) == 0 {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("reading secret with secret-tool: %s", secretToolError(err, nil))
+	}
+	return string(out), nil
+}
+
+func del(account string) error {
+	if out, err := exec.Command("secret-tool", "clear", "service", Service, "account", account).CombinedOutput(); err != nil {
+		return fmt.Errorf("deleting secret with secret-tool: %s", secretToolError(err, out))
+	}
+	return nil
+}
+
+func secretToolError(err error, out []byte) string {
+	if errors.Is(err, exec.ErrNotFound) {
+		return "secret-tool not found (install libsecret-tools or libsecret)"
+	}
+	var exitErr *exec.ExitError
+	if len(out) == 0 && errors.As(err, &exitErr) {
+		out = exitErr.Stderr
+	}
+	if msg := strings.TrimSpace(string(out)); msg != "" {
+		return msg
+	}
+	return err.Error()
+}
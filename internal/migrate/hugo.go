@@ -0,0 +1,218 @@
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ReadHugo reads the content of the Hugo site in dir. Files directly in
+// content/, and bundles there, are pages; files in sections such as
+// content/posts/ are posts. Section list pages (_index.md) are skipped.
+func ReadHugo(dir string) (*Site, error) {
+	contentDir := filepath.Join(dir, "content")
+	if _, err := os.Stat(contentDir); err != nil {
+		return nil, fmt.Errorf("not a Hugo site: %w", err)
+	}
+	files, err := markdownFiles(contentDir, true)
+	if err != nil {
+		return nil, err
+	}
+
+	site := &Site{StaticDir: filepath.Join(dir, "static")}
+	for _, path := range files {
+		name := filepath.Base(path)
+		if strings.HasPrefix(name, "_index.") {
+			continue
+		}
+		rel, _ := filepath.Rel(contentDir, path)
+		section := filepath.Dir(rel)
+		slug := strings.TrimSuffix(name, filepath.Ext(name))
+		if slug == "index" {
+			// A page bundle, named after its directory
+			slug = filepath.Base(section)
+			section = filepath.Dir(section)
+		}
+
+		it, err := readHugoFile(path, slug, section)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+		it.File = filepath.Join("content", rel)
+		site.Items = append(site.Items, *it)
+	}
+	return site, nil
+}
+
+// readHugoFile reads a content file of a section, "." for pages
+func readHugoFile(path, slug, section string) (*Item, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fm, body, err := splitFrontmatter(data)
+	if err != nil {
+		return nil, err
+	}
+
+	it := &Item{Dir: filepath.Dir(path), Page: section == ".", Slug: slug}
+	if s := stringValue(fm["slug"]); s != "" {
+		it.Slug = s
+	}
+	it.Title = stringValue(fm["title"])
+	if it.Title == "" {
+		it.Title = titleFromSlug(it.Slug)
+	}
+	it.Draft, _ = boolValue(fm["draft"])
+
+	// publishDate is when a post goes live, date when it was written
+	for _, key := range []string{"publishDate", "publishdate", "date"} {
+		if fm[key] == nil {
+			continue
+		}
+		date, err := parseDate(fm[key])
+		if err != nil {
+			it.Warnings = append(it.Warnings, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		it.Date = date
+		break
+	}
+
+	it.Tags = appendUnique(stringList(fm["tags"], false), stringList(fm["categories"], false)...)
+	it.Excerpt = stringValue(fm["summary"])
+	if it.Excerpt == "" {
+		it.Excerpt = stringValue(fm["description"])
+	}
+	for _, key := range []string{"images", "image", "featured_image", "featureImage", "cover"} {
+		if it.FeatureImage = imageValue(fm[key]); it.FeatureImage != "" {
+			break
+		}
+	}
+	// Where Hugo published the item: its url, or under its section
+	old := stringValue(fm["url"])
+	if old == "" && !it.Page {
+		old = "/" + filepath.ToSlash(section) + "/" + it.Slug + "/"
+	}
+	for _, alias := range append(stringList(fm["aliases"], false), old) {
+		if p := aliasPath(alias); p != "" && p != "/"+it.Slug+"/" {
+			it.Aliases = appendUnique(it.Aliases, p)
+		}
+	}
+
+	it.Body = convertShortcodes(body, &it.Warnings)
+	return it, nil
+}
+
+// shortcodeRe matches a Hugo shortcode, {{< name args >}} or
+// {{% name args %}}, or its closing tag
+var shortcodeRe = regexp.MustCompile(`\{\{([<%])\s*(/?)\s*([\w-]+)(.*?)\s*[>%]\}\}`)
+
+// shortcodeArgRe matches a shortcode argument: name="value", "value" or
+// value
+var shortcodeArgRe = regexp.MustCompile(`(?:(\w+)=)?(?:"((?:[^"\\]|\\.)*)"|` + "`([^`]*)`" + `|(\S+))`)
+
+// convertShortcodes replaces the shortcodes Hugo has built in with their
+// markdown, or the ::: directives specter renders as cards. Others are left
+// in place with a warning.
+func convertShortcodes(body string, warnings *[]string) string {
+	return shortcodeRe.ReplaceAllStringFunc(body, func(m string) string {
+		sub := shortcodeRe.FindStringSubmatch(m)
+		closing, name := sub[2] == "/", sub[3]
+		named, args := shortcodeArgs(sub[4])
+		arg := func(key string, i int) string {
+			if v := named[key]; v != "" {
+				return v
+			}
+			if i >= 0 && i < len(args) {
+				return args[i]
+			}
+			return ""
+		}
+
+		switch name {
+		case "highlight":
+			if closing {
+				return "```"
+			}
+			return "```" + arg("lang", 0)
+		case "figure":
+			alt := firstNonEmpty(arg("alt", -1), arg("caption", -1), arg("title", -1))
+			return fmt.Sprintf("![%s](%s)", alt, arg("src", 0))
+		case "ref", "relref":
+			return refPath(arg("path", 0))
+		}
+
+		var url string
+		switch name {
+		case "youtube":
+			url = "https://www.youtube.com/watch?v=" + arg("id", 0)
+		case "vimeo":
+			url = "https://vimeo.com/" + arg("id", 0)
+		case "gist":
+			url = "https://gist.github.com/" + arg("user", 0) + "/" + arg("id", 1)
+		case "tweet", "x":
+			if user := arg("user", -1); user != "" {
+				url = "https://twitter.com/" + user + "/status/" + arg("id", 0)
+			} else {
+				url = "https://twitter.com/i/status/" + arg("id", 0)
+			}
+		case "instagram":
+			url = "https://www.instagram.com/p/" + arg("id", 0) + "/"
+		}
+		if url != "" {
+			return ":::embed " + url + "\n:::"
+		}
+
+		if !closing {
+			*warnings = append(*warnings, fmt.Sprintf("unsupported shortcode %q left as is", name))
+		}
+		return m
+	})
+}
+
+// shortcodeArgs splits a shortcode's arguments into named and positional
+// ones
+func shortcodeArgs(s string) (map[string]string, []string) {
+	named := map[string]string{}
+	var args []string
+	for _, m := range shortcodeArgRe.FindAllStringSubmatch(s, -1) {
+		v := strings.ReplaceAll(m[2], `\"`, `"`) + m[3] + m[4]
+		if m[1] != "" {
+			named[m[1]] = v
+		} else {
+			args = append(args, v)
+		}
+	}
+	return named, args
+}
+
+// refPath returns the Ghost URL path of the content a ref shortcode links
+// to, e.g. posts/hello.md#intro becomes /hello/#intro
+func refPath(ref string) string {
+	ref, anchor, _ := strings.Cut(ref, "#")
+	if anchor != "" {
+		anchor = "#" + anchor
+	}
+	ref = strings.TrimSuffix(ref, "/")
+	if ref == "" {
+		return anchor
+	}
+	slug := strings.TrimSuffix(filepath.Base(ref), filepath.Ext(ref))
+	if slug == "index" {
+		slug = filepath.Base(filepath.Dir(ref))
+	}
+	return "/" + slug + "/" + anchor
+}
+
+// firstNonEmpty returns the first of values that isn't empty
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
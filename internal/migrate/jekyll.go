@@ -0,0 +1,276 @@
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// notJekyllPages are the markdown files at the top of a Jekyll site that
+// aren't pages of it
+var notJekyllPages = map[string]bool{"readme": true, "changelog": true, "license": true, "contributing": true, "index": true, "404": true}
+
+// ReadJekyll reads the content of the Jekyll site in dir: posts from
+// _posts/, drafts from _drafts/, and pages from the markdown files at the
+// top of the site and in _pages/.
+func ReadJekyll(dir string) (*Site, error) {
+	_, configErr := os.Stat(filepath.Join(dir, "_config.yml"))
+	if _, err := os.Stat(filepath.Join(dir, "_posts")); err != nil && configErr != nil {
+		return nil, fmt.Errorf("not a Jekyll site: no _config.yml or _posts in %s", dir)
+	}
+
+	permalink, err := jekyllPermalink(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	site := &Site{StaticDir: dir}
+	add := func(path string, page, draft bool) error {
+		rel, _ := filepath.Rel(dir, path)
+		it, err := readJekyllFile(path, page, draft, permalink)
+		if err != nil {
+			return fmt.Errorf("%s: %w", rel, err)
+		}
+		it.File = rel
+		site.Items = append(site.Items, *it)
+		return nil
+	}
+
+	for _, sub := range []struct {
+		dir       string
+		recursive bool
+		page      bool
+		draft     bool
+	}{
+		{"_posts", true, false, false},
+		{"_drafts", true, false, true},
+		{".", false, true, false},
+		{"_pages", true, true, false},
+	} {
+		files, err := markdownFiles(filepath.Join(dir, sub.dir), sub.recursive)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range files {
+			name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+			if sub.dir == "." && notJekyllPages[strings.ToLower(name)] {
+				continue
+			}
+			if err := add(path, sub.page, sub.draft); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return site, nil
+}
+
+// postNameRe matches the name of a Jekyll post file, which starts with
+// its date
+var postNameRe = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)$`)
+
+func readJekyllFile(path string, page, draft bool, permalink string) (*Item, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fm, body, err := splitFrontmatter(data)
+	if err != nil {
+		return nil, err
+	}
+
+	it := &Item{Dir: filepath.Dir(path), Page: page, Draft: draft}
+	it.Slug = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if m := postNameRe.FindStringSubmatch(it.Slug); m != nil && !page {
+		it.Slug = m[2]
+		it.Date, _ = parseDate(m[1])
+	}
+	if s := stringValue(fm["slug"]); s != "" {
+		it.Slug = s
+	}
+	it.Title = stringValue(fm["title"])
+	if it.Title == "" {
+		it.Title = titleFromSlug(it.Slug)
+	}
+	if published, ok := boolValue(fm["published"]); ok && !published {
+		it.Draft = true
+	}
+	if fm["date"] != nil {
+		if date, err := parseDate(fm["date"]); err != nil {
+			it.Warnings = append(it.Warnings, fmt.Sprintf("date: %v", err))
+		} else {
+			it.Date = date
+		}
+	}
+
+	for _, key := range []string{"tags", "tag", "categories", "category"} {
+		it.Tags = appendUnique(it.Tags, stringList(fm[key], key != "category")...)
+	}
+	it.Excerpt = stringValue(fm["excerpt"])
+	if it.Excerpt == "" {
+		it.Excerpt = stringValue(fm["description"])
+	}
+	for _, key := range []string{"image", "feature_image", "header", "cover"} {
+		if it.FeatureImage = imageValue(fm[key]); it.FeatureImage != "" {
+			break
+		}
+	}
+	it.FeatureImage = strings.TrimPrefix(it.FeatureImage, "{{ site.baseurl }}")
+	// Where Jekyll published the item, unless the frontmatter says
+	old := stringValue(fm["permalink"])
+	if old == "" && page {
+		old = "/" + strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) + ".html"
+	} else if old == "" && !draft {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if m := postNameRe.FindStringSubmatch(name); m != nil {
+			name = m[2]
+		}
+		old = expandPermalink(permalink, it.Date, stringList(fm["categories"], true), stringList(fm["category"], false), name)
+	}
+	for _, alias := range append(stringList(fm["redirect_from"], false), old) {
+		if p := aliasPath(alias); p != "" && !strings.Contains(p, ":") && p != "/"+it.Slug+"/" {
+			it.Aliases = appendUnique(it.Aliases, p)
+		}
+	}
+
+	it.Body = convertLiquid(body, &it.Warnings)
+	return it, nil
+}
+
+// permalinkStyles are the permalink styles Jekyll names, besides patterns
+var permalinkStyles = map[string]string{
+	"date":    "/:categories/:year/:month/:day/:title:output_ext",
+	"pretty":  "/:categories/:year/:month/:day/:title/",
+	"ordinal": "/:categories/:year/:y_day/:title:output_ext",
+	"none":    "/:categories/:title:output_ext",
+}
+
+// jekyllPermalink returns the permalink pattern of posts from the site's
+// _config.yml
+func jekyllPermalink(dir string) (string, error) {
+	var config struct {
+		Permalink string `yaml:"permalink"`
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "_config.yml"))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return "", fmt.Errorf("_config.yml: %w", err)
+	}
+	if style, ok := permalinkStyles[firstNonEmpty(config.Permalink, "date")]; ok {
+		return style, nil
+	}
+	return config.Permalink, nil
+}
+
+// expandPermalink returns the path Jekyll published a post at
+func expandPermalink(pattern string, date time.Time, categories, category []string, title string) string {
+	if len(categories) == 0 {
+		categories = category
+	}
+	for i, c := range categories {
+		categories[i] = strings.ToLower(c)
+	}
+	path := strings.NewReplacer(
+		":categories", strings.Join(categories, "/"),
+		":year", date.Format("2006"),
+		":month", date.Format("01"),
+		":i_month", date.Format("1"),
+		":day", date.Format("02"),
+		":i_day", date.Format("2"),
+		":y_day", fmt.Sprintf("%03d", date.YearDay()),
+		":short_year", date.Format("06"),
+		":title", title,
+		":slug", title,
+		":output_ext", ".html",
+	).Replace(pattern)
+	// Empty placeholders leave empty path segments
+	for strings.Contains(path, "//") {
+		path = strings.ReplaceAll(path, "//", "/")
+	}
+	return path
+}
+
+var (
+	// rawRe matches a {% raw %} block, whose content isn't Liquid
+	rawRe = regexp.MustCompile(`(?s)\{%-?\s*raw\s*-?%\}(.*?)\{%-?\s*endraw\s*-?%\}`)
+	// commentRe matches a {% comment %} block
+	commentRe = regexp.MustCompile(`(?s)\{%-?\s*comment\s*-?%\}.*?\{%-?\s*endcomment\s*-?%\}`)
+	// tagRe matches a Liquid tag, {% name args %}
+	tagRe = regexp.MustCompile(`\{%-?\s*(\w+)(.*?)\s*-?%\}`)
+	// outputRe matches a Liquid output, {{ value | filter }}
+	outputRe = regexp.MustCompile(`\{\{-?\s*(.*?)\s*-?\}\}`)
+	// relativeURLRe matches a quoted path passed to relative_url or
+	// absolute_url
+	relativeURLRe = regexp.MustCompile(`^["']([^"']*)["']\s*\|\s*(?:relative|absolute)_url$`)
+)
+
+// convertLiquid replaces the Liquid tags posts commonly use with markdown:
+// highlight blocks become fenced code, post_url and link tags become Ghost
+// paths, and site.baseurl is removed. Other tags are left in place with a
+// warning; the content of {% raw %} blocks is kept as is.
+func convertLiquid(body string, warnings *[]string) string {
+	var out strings.Builder
+	for {
+		loc := rawRe.FindStringSubmatchIndex(body)
+		if loc == nil {
+			out.WriteString(convertLiquidTags(body, warnings))
+			return out.String()
+		}
+		out.WriteString(convertLiquidTags(body[:loc[0]], warnings))
+		out.WriteString(body[loc[2]:loc[3]])
+		body = body[loc[1]:]
+	}
+}
+
+func convertLiquidTags(s string, warnings *[]string) string {
+	warned := map[string]bool{}
+	warn := func(m string) string {
+		if !warned[m] {
+			warned[m] = true
+			*warnings = append(*warnings, fmt.Sprintf("unsupported Liquid %s left as is", m))
+		}
+		return m
+	}
+
+	s = commentRe.ReplaceAllString(s, "")
+	s = tagRe.ReplaceAllStringFunc(s, func(m string) string {
+		sub := tagRe.FindStringSubmatch(m)
+		args := strings.Fields(sub[2])
+		switch sub[1] {
+		case "highlight":
+			if len(args) > 0 {
+				return "```" + args[0]
+			}
+			return "```"
+		case "endhighlight":
+			return "```"
+		case "post_url", "link":
+			if len(args) == 0 {
+				break
+			}
+			slug := strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+			if m := postNameRe.FindStringSubmatch(slug); m != nil {
+				slug = m[2]
+			}
+			return "/" + slug + "/"
+		}
+		return warn(m)
+	})
+	return outputRe.ReplaceAllStringFunc(s, func(m string) string {
+		expr := outputRe.FindStringSubmatch(m)[1]
+		switch expr {
+		case "site.baseurl", "site.url", "site.url | append: site.baseurl":
+			return ""
+		}
+		if sub := relativeURLRe.FindStringSubmatch(expr); sub != nil {
+			return sub[1]
+		}
+		return warn(m)
+	})
+}
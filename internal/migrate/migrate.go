@@ -0,0 +1,297 @@
+// Package migrate reads the content of static site generators, such as Hugo
+// and Jekyll, as markdown files specter can create posts and pages from
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Item is a post or page read from another platform
+type Item struct {
+	// File is the source file, relative to the site directory
+	File string
+	// Dir is the directory relative image paths are found in
+	Dir   string
+	Page  bool
+	Title string
+	Slug  string
+	Draft bool
+	// Date is when the item was published, or zero if unknown
+	Date         time.Time
+	Tags         []string
+	Excerpt      string
+	FeatureImage string
+	// Authors are the item's authors on the other platform
+	Authors []string
+	// Aliases are old paths of the item, which should redirect to it
+	Aliases []string
+	// Body is the markdown content, without frontmatter
+	Body     string
+	Warnings []string
+}
+
+// Status returns the Ghost status of the item: draft, scheduled for a
+// date in the future, or published
+func (it *Item) Status(now time.Time) string {
+	switch {
+	case it.Draft:
+		return "draft"
+	case it.Date.After(now):
+		return "scheduled"
+	}
+	return "published"
+}
+
+// Markdown returns the item as a markdown file with specter's frontmatter
+func (it *Item) Markdown(now time.Time) ([]byte, error) {
+	fm := struct {
+		Title        string   `yaml:"title"`
+		Slug         string   `yaml:"slug,omitempty"`
+		Status       string   `yaml:"status"`
+		PublishedAt  string   `yaml:"published_at,omitempty"`
+		Tags         []string `yaml:"tags,omitempty"`
+		Excerpt      string   `yaml:"excerpt,omitempty"`
+		FeatureImage string   `yaml:"feature_image,omitempty"`
+	}{Title: it.Title, Slug: it.Slug, Status: it.Status(now), Tags: it.Tags, Excerpt: it.Excerpt, FeatureImage: it.FeatureImage}
+	if !it.Date.IsZero() {
+		fm.PublishedAt = it.Date.UTC().Format(time.RFC3339)
+	}
+	data, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, err
+	}
+	return []byte("---\n" + string(data) + "---\n" + it.Body), nil
+}
+
+// Site is the content read from a site
+type Site struct {
+	Items []Item
+	// URL is the address of a site whose images are only online, such as
+	// a WordPress site's, to download them from
+	URL string
+	// StaticDir is where site-absolute paths such as /images/a.png are
+	// found
+	StaticDir string
+}
+
+// splitFrontmatter separates a file's frontmatter, in YAML between "---"
+// lines, TOML between "+++" lines or a JSON object, from its body
+func splitFrontmatter(data []byte) (map[string]interface{}, string, error) {
+	text := strings.TrimPrefix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\ufeff")
+	fm := map[string]interface{}{}
+	for _, delim := range []string{"---", "+++"} {
+		if !strings.HasPrefix(text, delim+"\n") {
+			continue
+		}
+		head, body, ok := strings.Cut(text[len(delim)+1:], "\n"+delim)
+		if !ok {
+			return nil, "", fmt.Errorf("frontmatter has no closing %s", delim)
+		}
+		// The rest of the closing line
+		if _, rest, ok := strings.Cut(body, "\n"); ok {
+			body = rest
+		} else {
+			body = ""
+		}
+		var err error
+		if delim == "---" {
+			err = yaml.Unmarshal([]byte(head), &fm)
+		} else {
+			fm, err = parseTOML(head)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("parsing frontmatter: %w", err)
+		}
+		return fm, body, nil
+	}
+	if strings.HasPrefix(text, "{") {
+		if fm, n, err := parseJSONFrontmatter(text); err == nil {
+			return fm, text[n:], nil
+		}
+	}
+	return fm, text, nil
+}
+
+// markdownFiles returns the markdown files in dir, and in its
+// subdirectories if recursive is set, sorted by path. Hidden directories
+// are skipped, and a missing dir has no files.
+func markdownFiles(dir string, recursive bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (!recursive || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".md", ".markdown":
+			files = append(files, path)
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	sort.Strings(files)
+	return files, err
+}
+
+// dateFormats are the date layouts found in frontmatter, besides YAML
+// timestamps
+var dateFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+func parseDate(v interface{}) (time.Time, error) {
+	switch v := v.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return v, nil
+	case string:
+		v = strings.TrimSpace(v)
+		for _, layout := range dateFormats {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("unknown date format: %s", v)
+	}
+	return time.Time{}, fmt.Errorf("unknown date: %v", v)
+}
+
+// stringValue returns a frontmatter value as a string, or "" if it isn't
+// one
+func stringValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return ""
+}
+
+// stringList returns a frontmatter value that is a list of strings, or a
+// single string of space-separated words as Jekyll allows
+func stringList(v interface{}, splitWords bool) []string {
+	switch v := v.(type) {
+	case string:
+		if splitWords {
+			return strings.Fields(v)
+		}
+		if s := strings.TrimSpace(v); s != "" {
+			return []string{s}
+		}
+	case []interface{}:
+		var list []string
+		for _, e := range v {
+			if s := strings.TrimSpace(fmt.Sprint(e)); s != "" && e != nil {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// boolValue returns a frontmatter value as a bool, accepting "true" and
+// "false" strings too
+func boolValue(v interface{}) (bool, bool) {
+	switch v := v.(type) {
+	case bool:
+		return v, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes":
+			return true, true
+		case "false", "no":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// imageValue returns an image path from a frontmatter value that is a
+// path, a list of paths or a map with a path, as themes use all of them
+func imageValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case []interface{}:
+		if len(v) > 0 {
+			return imageValue(v[0])
+		}
+	case map[string]interface{}:
+		for _, key := range []string{"image", "path", "src", "url"} {
+			if s := stringValue(v[key]); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// appendUnique appends the values in add to list that aren't in it yet,
+// ignoring case
+func appendUnique(list []string, add ...string) []string {
+	seen := map[string]bool{}
+	for _, s := range list {
+		seen[strings.ToLower(s)] = true
+	}
+	for _, s := range add {
+		if !seen[strings.ToLower(s)] {
+			seen[strings.ToLower(s)] = true
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// aliasPath returns an old URL path as a redirect source, starting and
+// ending with "/" unless it names a file
+func aliasPath(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	if i := strings.Index(s, "://"); i >= 0 {
+		if j := strings.IndexByte(s[i+3:], '/'); j >= 0 {
+			s = s[i+3+j:]
+		} else {
+			s = "/"
+		}
+	}
+	if !strings.HasPrefix(s, "/") {
+		s = "/" + s
+	}
+	if !strings.HasSuffix(s, "/") && filepath.Ext(s) == "" {
+		s += "/"
+	}
+	return s
+}
+
+// titleFromSlug makes a title from a file name, for items without one
+func titleFromSlug(slug string) string {
+	title := strings.Join(strings.FieldsFunc(slug, func(r rune) bool { return r == '-' || r == '_' }), " ")
+	for i, r := range title {
+		return title[:i] + string(unicode.ToUpper(r)) + title[i+utf8.RuneLen(r):]
+	}
+	return title
+}
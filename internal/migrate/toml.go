@@ -0,0 +1,29 @@
+package migrate
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// parseTOML parses the TOML frontmatter Hugo writes by default
+func parseTOML(src string) (map[string]interface{}, error) {
+	fm := map[string]interface{}{}
+	if _, err := toml.Decode(src, &fm); err != nil {
+		return nil, err
+	}
+	return fm, nil
+}
+
+// parseJSONFrontmatter parses the JSON object Hugo allows as frontmatter
+// at the start of text, returning where the body starts
+func parseJSONFrontmatter(text string) (map[string]interface{}, int, error) {
+	d := json.NewDecoder(strings.NewReader(text))
+	var fm map[string]interface{}
+	if err := d.Decode(&fm); err != nil {
+		return nil, 0, err
+	}
+	n := int(d.InputOffset())
+	return fm, n + len(text[n:]) - len(strings.TrimLeft(text[n:], "\n")), nil
+}
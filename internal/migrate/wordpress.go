@@ -0,0 +1,296 @@
+package migrate
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/teal-bauer/specter/internal/content"
+)
+
+// wxr is the part of a WordPress export (WXR) file that is migrated
+type wxr struct {
+	Channel struct {
+		Link    string    `xml:"link"`
+		BaseURL string    `xml:"base_blog_url"`
+		Items   []wxrItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type wxrItem struct {
+	Title   string `xml:"title"`
+	Link    string `xml:"link"`
+	PubDate string `xml:"pubDate"`
+	Creator string `xml:"creator"`
+	// Encoded holds the content and the excerpt, told apart by namespace
+	Encoded []struct {
+		XMLName xml.Name
+		Text    string `xml:",chardata"`
+	} `xml:"encoded"`
+	ID            string `xml:"post_id"`
+	DateGMT       string `xml:"post_date_gmt"`
+	Date          string `xml:"post_date"`
+	Name          string `xml:"post_name"`
+	Status        string `xml:"status"`
+	Type          string `xml:"post_type"`
+	AttachmentURL string `xml:"attachment_url"`
+	Categories    []struct {
+		Domain string `xml:"domain,attr"`
+		Name   string `xml:",chardata"`
+	} `xml:"category"`
+	Meta []struct {
+		Key   string `xml:"meta_key"`
+		Value string `xml:"meta_value"`
+	} `xml:"postmeta"`
+}
+
+// encoded returns the text of the content:encoded or excerpt:encoded
+// element
+func (it *wxrItem) encoded(kind string) string {
+	for _, e := range it.Encoded {
+		if strings.Contains(e.XMLName.Space, "/"+kind+"/") {
+			return e.Text
+		}
+	}
+	return ""
+}
+
+// ReadWordPress reads the posts and pages of a WordPress export (WXR)
+// file. Their HTML is converted to markdown; images stay on the old site,
+// whose address is the Site's URL.
+func ReadWordPress(path string) (*Site, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var export wxr
+	d := xml.NewDecoder(f)
+	// Exports often contain HTML entities and stray control characters
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	if err := d.Decode(&export); err != nil {
+		return nil, fmt.Errorf("reading WordPress export: %w", err)
+	}
+
+	site := &Site{URL: strings.TrimSuffix(firstNonEmpty(export.Channel.BaseURL, export.Channel.Link), "/")}
+	attachments := map[string]string{}
+	for _, wi := range export.Channel.Items {
+		if wi.Type == "attachment" {
+			attachments[wi.ID] = wi.AttachmentURL
+		}
+	}
+
+	// Links between posts are rewritten to their new paths
+	links := map[string]string{}
+	for _, wi := range export.Channel.Items {
+		if wi.Type != "post" && wi.Type != "page" {
+			continue
+		}
+		switch wi.Status {
+		case "trash", "auto-draft", "inherit":
+			continue
+		}
+		it := readWordPressItem(&wi, attachments)
+		if !it.Draft && wordPressPath(&wi) != "" {
+			links[strings.TrimSpace(wi.Link)] = "/" + it.Slug + "/"
+		}
+		site.Items = append(site.Items, *it)
+	}
+
+	var pairs []string
+	for old := range links {
+		pairs = append(pairs, old)
+	}
+	// Longer links first, so that none is replaced by a prefix of it
+	sort.Slice(pairs, func(i, j int) bool { return len(pairs[i]) > len(pairs[j]) })
+	var replace []string
+	for _, old := range pairs {
+		replace = append(replace, "("+old+")", "("+links[old]+")")
+	}
+	if len(replace) > 0 {
+		r := strings.NewReplacer(replace...)
+		for i := range site.Items {
+			site.Items[i].Body = r.Replace(site.Items[i].Body)
+		}
+	}
+	return site, nil
+}
+
+func readWordPressItem(wi *wxrItem, attachments map[string]string) *Item {
+	it := &Item{
+		File:    wi.Type + " " + wi.ID,
+		Page:    wi.Type == "page",
+		Title:   strings.TrimSpace(wi.Title),
+		Excerpt: strings.TrimSpace(wi.encoded("excerpt")),
+	}
+	if it.Title == "" {
+		it.Title = "(untitled)"
+	}
+	it.Slug = strings.TrimSpace(wi.Name)
+	if s, err := url.PathUnescape(it.Slug); err == nil {
+		it.Slug = s
+	}
+	if it.Slug == "" {
+		it.Slug = content.Slugify(it.Title)
+	}
+
+	switch wi.Status {
+	case "publish", "future":
+	case "private":
+		it.Draft = true
+		it.Warnings = append(it.Warnings, "private on WordPress, created as a draft")
+	default:
+		it.Draft = true
+	}
+	if date, err := parseWordPressDate(wi); err != nil {
+		it.Warnings = append(it.Warnings, err.Error())
+	} else {
+		it.Date = date
+	}
+	if wi.Creator != "" {
+		it.Authors = []string{strings.TrimSpace(wi.Creator)}
+	}
+
+	// Categories first, so that the primary tag is the main category
+	for _, domain := range []string{"category", "post_tag"} {
+		for _, c := range wi.Categories {
+			name := strings.TrimSpace(c.Name)
+			if c.Domain == domain && name != "" && name != "Uncategorized" {
+				it.Tags = appendUnique(it.Tags, name)
+			}
+		}
+	}
+	for _, m := range wi.Meta {
+		if m.Key == "_thumbnail_id" {
+			it.FeatureImage = attachments[strings.TrimSpace(m.Value)]
+		}
+	}
+	if p := wordPressPath(wi); p != "" && !it.Draft && p != "/"+it.Slug+"/" {
+		it.Aliases = []string{p}
+	}
+
+	it.Body = convertWordPress(wi.encoded("content"), &it.Warnings)
+	return it
+}
+
+// wordPressPath returns the path an item was published at. Drafts have
+// links like /?p=123, which aren't worth redirecting.
+func wordPressPath(wi *wxrItem) string {
+	u, err := url.Parse(strings.TrimSpace(wi.Link))
+	if err != nil || u.RawQuery != "" || u.Path == "" {
+		return ""
+	}
+	return aliasPath(u.Path)
+}
+
+// parseWordPressDate returns when an item was published, or zero for
+// drafts, which have no date
+func parseWordPressDate(wi *wxrItem) (time.Time, error) {
+	const layout = "2006-01-02 15:04:05"
+	if gmt := strings.TrimSpace(wi.DateGMT); gmt != "" && !strings.HasPrefix(gmt, "0000") {
+		return time.Parse(layout, gmt)
+	}
+	if wi.Status != "publish" && wi.Status != "future" {
+		return time.Time{}, nil
+	}
+	if wi.PubDate != "" {
+		if t, err := time.Parse(time.RFC1123Z, strings.TrimSpace(wi.PubDate)); err == nil {
+			return t, nil
+		}
+	}
+	return time.Parse(layout, strings.TrimSpace(wi.Date))
+}
+
+var (
+	// wpEmbedRe matches an embed, as a block or a shortcode
+	wpEmbedRe = regexp.MustCompile(`(?s)<figure[^>]*wp-block-embed[^>]*>\s*<div[^>]*>\s*(\S+?)\s*</div>.*?</figure>|\[embed[^\]]*\]\s*(\S+?)\s*\[/embed\]`)
+	// captionRe matches a [caption] shortcode around an image
+	captionRe = regexp.MustCompile(`(?s)\[caption[^\]]*\]\s*((?:<a[^>]*>)?\s*<img[^>]*>\s*(?:</a>)?)(.*?)\[/caption\]`)
+	// wpFigureRe matches an image block, possibly linked and captioned
+	wpFigureRe = regexp.MustCompile(`(?s)<figure[^>]*>\s*((?:<a[^>]*>)?\s*<img[^>]*>\s*(?:</a>)?)\s*(?:<figcaption[^>]*>(.*?)</figcaption>)?\s*</figure>`)
+	// wpShortcodeRe matches other shortcodes, which have attributes or a
+	// closing tag
+	wpShortcodeRe = regexp.MustCompile(`\[(\w[\w-]*)\s+\w+=[^\]]*\]|\[/(\w[\w-]*)\]`)
+	// blankLineRe matches the blank lines between classic paragraphs
+	blankLineRe = regexp.MustCompile(`\n\s*\n`)
+	// blockStartRe matches a paragraph that starts with a block element,
+	// which wpautop doesn't wrap
+	blockStartRe = regexp.MustCompile(`(?i)^<(p|div|h[1-6]|ul|ol|li|dl|blockquote|pre|table|figure|hr|form|iframe|script|style|section|address|!--)[\s>/]`)
+)
+
+// convertWordPress converts a post's content to markdown. Embeds become
+// :::embed directives, and image captions an emphasized line below the
+// image, which markdown has no syntax for. Other shortcodes are left in
+// place with a warning.
+func convertWordPress(html string, warnings *[]string) string {
+	html = strings.ReplaceAll(html, "\r\n", "\n")
+	html = captionRe.ReplaceAllString(html, "<figure>$1<figcaption>$2</figcaption></figure>")
+	html = wpFigureRe.ReplaceAllStringFunc(html, func(m string) string {
+		sub := wpFigureRe.FindStringSubmatch(m)
+		if caption := strings.TrimSpace(sub[2]); caption != "" {
+			return "<p>" + sub[1] + "</p>\n<p><em>" + caption + "</em></p>"
+		}
+		return "<p>" + sub[1] + "</p>"
+	})
+	for _, m := range wpShortcodeRe.FindAllStringSubmatch(html, -1) {
+		if name := m[1] + m[2]; name != "embed" {
+			*warnings = appendUnique(*warnings, fmt.Sprintf("unsupported shortcode [%s] left as is", name))
+		}
+	}
+
+	var blocks []string
+	add := func(html string) {
+		if md, _ := content.HTMLToMarkdown(autop(html)); strings.TrimSpace(md) != "" {
+			blocks = append(blocks, strings.TrimSpace(md))
+		}
+	}
+	for {
+		loc := wpEmbedRe.FindStringSubmatchIndex(html)
+		if loc == nil {
+			add(html)
+			return strings.Join(blocks, "\n\n") + "\n"
+		}
+		add(html[:loc[0]])
+		var embed string
+		if loc[2] >= 0 {
+			embed = html[loc[2]:loc[3]]
+		} else {
+			embed = html[loc[4]:loc[5]]
+		}
+		blocks = append(blocks, ":::embed "+embed+"\n:::")
+		html = html[loc[1]:]
+	}
+}
+
+// autop adds the paragraphs WordPress adds when it shows classic editor
+// content, which is stored with blank lines between paragraphs. Block
+// editor content already has them.
+func autop(html string) string {
+	if strings.Contains(html, "<!-- wp:") {
+		return html
+	}
+	var chunks []string
+	for _, chunk := range blankLineRe.Split(html, -1) {
+		// Blank lines inside a <pre> are part of it
+		if n := len(chunks); n > 0 && strings.Count(chunks[n-1], "<pre") > strings.Count(chunks[n-1], "</pre") {
+			chunks[n-1] += "\n\n" + chunk
+			continue
+		}
+		chunks = append(chunks, chunk)
+	}
+	for i, chunk := range chunks {
+		chunk = strings.TrimSpace(chunk)
+		if chunk != "" && !blockStartRe.MatchString(chunk) {
+			chunk = "<p>" + strings.ReplaceAll(chunk, "\n", "<br />\n") + "</p>"
+		}
+		chunks[i] = chunk
+	}
+	return strings.Join(chunks, "\n")
+}
@@ -0,0 +1,196 @@
+// Package output renders command results as tables, JSON, CSV, or Go
+// templates, so that all commands format their output the same way.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+// Options controls how results are rendered
+type Options struct {
+	// Format is "text" (the default), "json", "ndjson", "csv", or
+	// "template=<tmpl>" where tmpl is a Go template executed for each item
+	Format string
+	// NoHeaders omits the header row of tables and CSV
+	NoHeaders bool
+	// Wide shows all columns in tables, without truncating values
+	Wide bool
+}
+
+// Column is a column of a table or CSV listing
+type Column[T any] struct {
+	Header string
+	Value  func(T) string
+	// CSV, if set, gives the value in CSV instead of Value, e.g. an amount
+	// unformatted for spreadsheets
+	CSV func(T) string
+	// Width truncates values in tables to this many characters unless
+	// Options.Wide is set; 0 means no limit
+	Width int
+	// Wide columns only appear in tables with Options.Wide. CSV always
+	// includes them.
+	Wide bool
+}
+
+// Render writes items to w in the format given by opts. JSON encodes the
+// items themselves rather than the columns.
+func Render[T any](w io.Writer, items []T, columns []Column[T], opts Options) error {
+	if err := CheckFormat(opts.Format); err != nil {
+		return err
+	}
+	switch format := opts.Format; {
+	case format == "json":
+		if items == nil {
+			items = []T{}
+		}
+		return JSON(w, items)
+	case format == "ndjson":
+		return NDJSON(w, items)
+	case format == "csv":
+		return renderCSV(w, items, columns, opts)
+	case strings.HasPrefix(format, "template="):
+		return renderTemplate(w, items, strings.TrimPrefix(format, "template="))
+	default:
+		return renderTable(w, items, columns, opts)
+	}
+}
+
+// CheckFormat returns an error if format is not a supported output format
+func CheckFormat(format string) error {
+	switch {
+	case format == "" || format == "text" || format == "json" || format == "ndjson" || format == "csv":
+		return nil
+	case strings.HasPrefix(format, "template="):
+		if _, err := template.New("output").Parse(strings.TrimPrefix(format, "template=")); err != nil {
+			return fmt.Errorf("parsing output template: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q (expected text, json, ndjson, csv, or template=...)", format)
+	}
+}
+
+// JSON writes v as indented JSON
+func JSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// NDJSON writes each item as JSON on a line of its own. Commands can call it
+// once per fetched page to stream long listings.
+func NDJSON[T any](w io.Writer, items []T) error {
+	enc := json.NewEncoder(w)
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Error writes err to w, as {"error": "..."} if the format is JSON
+func Error(w io.Writer, err error, opts Options) {
+	if opts.Format == "json" || opts.Format == "ndjson" {
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	fmt.Fprintln(w, "Error:", err)
+}
+
+func renderTable[T any](w io.Writer, items []T, columns []Column[T], opts Options) error {
+	var visible []Column[T]
+	for _, c := range columns {
+		if !c.Wide || opts.Wide {
+			visible = append(visible, c)
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !opts.NoHeaders {
+		headers := make([]string, len(visible))
+		for i, c := range visible {
+			headers[i] = c.Header
+		}
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	}
+	for _, item := range items {
+		cells := make([]string, len(visible))
+		for i, c := range visible {
+			cell := cleanCell(c.Value(item))
+			if c.Width > 0 && !opts.Wide {
+				cell = Truncate(cell, c.Width)
+			}
+			cells[i] = cell
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func renderCSV[T any](w io.Writer, items []T, columns []Column[T], opts Options) error {
+	cw := csv.NewWriter(w)
+	if !opts.NoHeaders {
+		headers := make([]string, len(columns))
+		for i, c := range columns {
+			headers[i] = strings.ToLower(c.Header)
+		}
+		if err := cw.Write(headers); err != nil {
+			return err
+		}
+	}
+	for _, item := range items {
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			if c.CSV != nil {
+				record[i] = c.CSV(item)
+			} else {
+				record[i] = c.Value(item)
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func renderTemplate[T any](w io.Writer, items []T, text string) error {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return fmt.Errorf("parsing output template: %w", err)
+	}
+	for _, item := range items {
+		if err := tmpl.Execute(w, item); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Truncate shortens s to at most n characters, ending in "..." if it was cut
+func Truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(r[:n])
+	}
+	return string(r[:n-3]) + "..."
+}
+
+// cleanCell keeps tabs and newlines in values from breaking table layout
+func cleanCell(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", "").Replace(s)
+}
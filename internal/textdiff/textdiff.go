@@ -0,0 +1,220 @@
+// Package textdiff compares and merges texts line by line.
+package textdiff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Lines splits s into lines without their line endings. A final line
+// ending doesn't start another line.
+func Lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// hunk replaces base[start:end] with lines
+type hunk struct {
+	start, end int
+	lines      []string
+}
+
+// diff returns the hunks that turn a into b, using the longest common
+// subsequence of lines after trimming the common prefix and suffix
+func diff(a, b []string) []hunk {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// lcs[i][j] is the length of the LCS of ma[i:] and mb[j:]
+	lcs := make([][]int32, len(ma)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(mb)+1)
+	}
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var hunks []hunk
+	var cur *hunk
+	i, j := 0, 0
+	for i < len(ma) || j < len(mb) {
+		if i < len(ma) && j < len(mb) && ma[i] == mb[j] {
+			cur = nil
+			i++
+			j++
+			continue
+		}
+		if cur == nil {
+			hunks = append(hunks, hunk{start: prefix + i, end: prefix + i})
+			cur = &hunks[len(hunks)-1]
+		}
+		if j < len(mb) && (i == len(ma) || lcs[i][j+1] >= lcs[i+1][j]) {
+			cur.lines = append(cur.lines, mb[j])
+			j++
+		} else {
+			i++
+			cur.end = prefix + i
+		}
+	}
+	return hunks
+}
+
+// Unified returns a unified diff from a to b with three lines of context,
+// or "" if they are equal
+func Unified(aName, bName, a, b string) string {
+	const context = 3
+	al, bl := Lines(a), Lines(b)
+	hunks := diff(al, bl)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+	// offset is how far a line in b is from the same line in a
+	offset := 0
+	for i := 0; i < len(hunks); {
+		// Hunks with overlapping context are printed together
+		j := i + 1
+		for j < len(hunks) && hunks[j].start-hunks[j-1].end <= 2*context {
+			j++
+		}
+		start := max(hunks[i].start-context, 0)
+		end := min(hunks[j-1].end+context, len(al))
+
+		var body strings.Builder
+		aCount, bCount := 0, 0
+		pos := start
+		bStart := start + offset
+		for _, h := range hunks[i:j] {
+			for ; pos < h.start; pos++ {
+				body.WriteString(" " + al[pos] + "\n")
+				aCount++
+				bCount++
+			}
+			for ; pos < h.end; pos++ {
+				body.WriteString("-" + al[pos] + "\n")
+				aCount++
+			}
+			for _, line := range h.lines {
+				body.WriteString("+" + line + "\n")
+				bCount++
+			}
+			offset += len(h.lines) - (h.end - h.start)
+		}
+		for ; pos < end; pos++ {
+			body.WriteString(" " + al[pos] + "\n")
+			aCount++
+			bCount++
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(start, aCount), hunkRange(bStart, bCount))
+		out.WriteString(body.String())
+		i = j
+	}
+	return out.String()
+}
+
+// hunkRange formats the line range of a hunk as in diff -u, where an empty
+// range is given by the line before it
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// Merge combines the changes from base to ours and from base to theirs.
+// Where both changed the same lines differently, the result has a conflict
+// between markers as in git, or theirs if preferTheirs is set; the number
+// of conflicts is returned.
+func Merge(base, ours, theirs, oursName, theirsName string, preferTheirs bool) (string, int) {
+	bl := Lines(base)
+	ho, ht := diff(bl, Lines(ours)), diff(bl, Lines(theirs))
+
+	var out []string
+	conflicts := 0
+	pos := 0
+	for len(ho) > 0 || len(ht) > 0 {
+		// The region starts at the first hunk and grows while hunks of
+		// either side overlap it
+		var start int
+		switch {
+		case len(ht) == 0 || (len(ho) > 0 && ho[0].start <= ht[0].start):
+			start = ho[0].start
+		default:
+			start = ht[0].start
+		}
+		end := start
+		no, nt := 0, 0
+		for grown := true; grown; {
+			grown = false
+			if no < len(ho) && (ho[no].start < end || ho[no].start == start) {
+				end = max(end, ho[no].end)
+				no++
+				grown = true
+			}
+			if nt < len(ht) && (ht[nt].start < end || ht[nt].start == start) {
+				end = max(end, ht[nt].end)
+				nt++
+				grown = true
+			}
+		}
+
+		out = append(out, bl[pos:start]...)
+		o := apply(bl, start, end, ho[:no])
+		t := apply(bl, start, end, ht[:nt])
+		switch {
+		case nt == 0:
+			out = append(out, o...)
+		case no == 0, slices.Equal(o, t), preferTheirs:
+			out = append(out, t...)
+		default:
+			conflicts++
+			out = append(out, "<<<<<<< "+oursName)
+			out = append(out, o...)
+			out = append(out, "=======")
+			out = append(out, t...)
+			out = append(out, ">>>>>>> "+theirsName)
+		}
+		pos = end
+		ho, ht = ho[no:], ht[nt:]
+	}
+	out = append(out, bl[pos:]...)
+
+	if len(out) == 0 {
+		return "", conflicts
+	}
+	return strings.Join(out, "\n") + "\n", conflicts
+}
+
+// apply returns base[start:end] with hunks, which lie within it, applied
+func apply(base []string, start, end int, hunks []hunk) []string {
+	var out []string
+	pos := start
+	for _, h := range hunks {
+		out = append(out, base[pos:h.start]...)
+		out = append(out, h.lines...)
+		pos = h.end
+	}
+	return append(out, base[pos:end]...)
+}